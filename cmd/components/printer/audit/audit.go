@@ -0,0 +1,282 @@
+// Package audit 以 JSON Lines 格式记录打印审计日志，按大小轮转并支持按条件检索
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"cse-go/internal/commandbus"
+)
+
+const (
+	currentFile     = "audit.jsonl"
+	defaultMaxSize  = 10 << 20
+	defaultMaxFiles = 10
+	maxLineSize     = 1 << 20
+)
+
+// 审计记录的结果
+const (
+	ResultSuccess = "success"
+	ResultFailed  = "failed"
+	// ResultDenied 表示调用被访问策略拒绝，命令未执行
+	ResultDenied = "denied"
+	// ResultRetrying 表示自动重试的提交仍未成功，作业将再次重试
+	ResultRetrying = "retrying"
+)
+
+// Entry 是一次打印命令的审计记录
+type Entry struct {
+	Time    time.Time         `json:"time"`
+	Command string            `json:"command"`
+	Caller  commandbus.Caller `json:"caller"`
+	// RequestID 为 Supervisor 传递的请求 ID，用于关联 HTTP 请求与日志
+	RequestID string `json:"requestId,omitempty"`
+	// RequestedPrinter 为调用方传入的打印机名称，可能是逻辑打印机；Printer 为实际使用的打印机
+	RequestedPrinter string `json:"requestedPrinter,omitempty"`
+	Printer          string `json:"printer,omitempty"`
+	Document         string `json:"document,omitempty"`
+	JobID            string `json:"jobId,omitempty"`
+	Pages            int    `json:"pages,omitempty"`
+	Bytes            int    `json:"bytes"`
+	// SHA256 为提交给打印机的内容的校验和
+	SHA256  string `json:"sha256,omitempty"`
+	Success bool   `json:"success"`
+	// Result 为 success、failed、denied 或 retrying
+	Result string `json:"result,omitempty"`
+	// Attempt 为自动重试时作业的提交次数，此类记录与原始记录的 JobID 相同
+	Attempt    int    `json:"attempt,omitempty"`
+	Message    string `json:"message,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// SetContent 记录提交给打印机的内容大小与校验和
+func (e *Entry) SetContent(data []byte) {
+	sum := sha256.Sum256(data)
+	e.Bytes = len(data)
+	e.SHA256 = hex.EncodeToString(sum[:])
+}
+
+// Query 定义了检索条件，零值字段表示不限制
+type Query struct {
+	Since time.Time
+	Until time.Time
+	// Printers 匹配实际使用或调用方传入的打印机名称中的任意一个
+	Printers []string
+	User     string
+	Command  string
+	// JobID 匹配同一作业的原始记录与自动重试记录
+	JobID string
+	Limit int
+}
+
+func (q *Query) match(e *Entry) bool {
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && e.Time.After(q.Until) {
+		return false
+	}
+	if len(q.Printers) > 0 && !slices.Contains(q.Printers, e.Printer) && !slices.Contains(q.Printers, e.RequestedPrinter) {
+		return false
+	}
+	if q.User != "" && e.Caller.User != q.User {
+		return false
+	}
+	if q.Command != "" && e.Command != q.Command {
+		return false
+	}
+	if q.JobID != "" && e.JobID != q.JobID {
+		return false
+	}
+	return true
+}
+
+// Options 定义了审计日志的配置
+type Options struct {
+	// MaxSize 为单个日志文件的字节数上限，超过后轮转
+	MaxSize int64
+	// MaxFiles 为保留的日志文件数量 (包括当前文件)
+	MaxFiles int
+}
+
+// Log 是追加写入的审计日志
+type Log struct {
+	dir  string
+	opts Options
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Open 打开 dir 中的审计日志，目录不存在时创建
+func Open(dir string, opts Options) (*Log, error) {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = defaultMaxSize
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = defaultMaxFiles
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("无法创建审计日志目录: %w", err)
+	}
+	l := &Log{dir: dir, opts: opts}
+	if err := l.openCurrent(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) openCurrent() error {
+	f, err := os.OpenFile(filepath.Join(l.dir, currentFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("无法打开审计日志: %w", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("无法读取审计日志信息: %w", err)
+	}
+	l.file = f
+	l.size = fi.Size()
+	return nil
+}
+
+// rotatedName 返回第 n 个轮转文件的名称，n 越大越旧
+func rotatedName(n int) string {
+	return fmt.Sprintf("audit.%d.jsonl", n)
+}
+
+// rotate 将当前文件依次后移并打开新文件，调用方需持有锁
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		log.Printf("[Audit] 关闭审计日志失败: %v", err)
+	}
+	oldest := filepath.Join(l.dir, rotatedName(l.opts.MaxFiles-1))
+	if err := os.Remove(oldest); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("[Audit] 删除最旧的审计日志失败: %v", err)
+	}
+	for n := l.opts.MaxFiles - 2; n >= 1; n-- {
+		err := os.Rename(filepath.Join(l.dir, rotatedName(n)), filepath.Join(l.dir, rotatedName(n+1)))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[Audit] 轮转审计日志失败: %v", err)
+		}
+	}
+	if l.opts.MaxFiles > 1 {
+		if err := os.Rename(filepath.Join(l.dir, currentFile), filepath.Join(l.dir, rotatedName(1))); err != nil {
+			log.Printf("[Audit] 轮转审计日志失败: %v", err)
+		}
+	} else if err := os.Remove(filepath.Join(l.dir, currentFile)); err != nil {
+		log.Printf("[Audit] 删除审计日志失败: %v", err)
+	}
+	return l.openCurrent()
+}
+
+// Record 追加一条审计记录
+func (l *Log) Record(entry *Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return errors.New("审计日志已关闭")
+	}
+	if l.size > 0 && l.size+int64(len(line)) > l.opts.MaxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("写入审计日志失败: %w", err)
+	}
+	return nil
+}
+
+// Query 按条件检索审计记录，结果按时间倒序排列
+func (l *Log) Query(q Query) ([]*Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 从最旧的轮转文件读到当前文件，得到按时间正序的记录
+	files := make([]string, 0, l.opts.MaxFiles)
+	for n := l.opts.MaxFiles - 1; n >= 1; n-- {
+		files = append(files, filepath.Join(l.dir, rotatedName(n)))
+	}
+	files = append(files, filepath.Join(l.dir, currentFile))
+
+	var matched []*Entry
+	for _, path := range files {
+		entries, err := readEntries(path, &q)
+		if err != nil {
+			return nil, err
+		}
+		matched = append(matched, entries...)
+	}
+
+	slices.Reverse(matched)
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	if matched == nil {
+		matched = []*Entry{}
+	}
+	return matched, nil
+}
+
+// readEntries 读取一个日志文件中满足条件的记录，文件不存在时返回空结果，无法解析的行被跳过
+func readEntries(path string, q *Query) ([]*Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取审计日志: %w", err)
+	}
+	defer f.Close()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		entry := &Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			continue
+		}
+		if q.match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取审计日志 '%s' 失败: %w", filepath.Base(path), err)
+	}
+	return entries, nil
+}
+
+// Close 关闭审计日志
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cse-go/internal/commandbus"
+)
+
+// TestRecordAndQuery 测试写入记录后按时间、打印机与用户检索
+func TestRecordAndQuery(t *testing.T) {
+	l, err := Open(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatalf("打开审计日志失败: %v", err)
+	}
+	defer l.Close()
+
+	base := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	records := []*Entry{
+		{Time: base, Command: "print.testPrint", Printer: "P1", Caller: commandbus.Caller{User: "alice"}, Success: true},
+		{Time: base.Add(time.Hour), Command: "print.printReceipt", RequestedPrinter: "receipt", Printer: "P2", Caller: commandbus.Caller{User: "bob"}},
+		{Time: base.Add(2 * time.Hour), Command: "print.printLabel", Printer: "P1", JobID: "job-000001", Caller: commandbus.Caller{User: "bob"}, Success: true},
+	}
+	for _, e := range records {
+		if err := l.Record(e); err != nil {
+			t.Fatalf("写入审计记录失败: %v", err)
+		}
+	}
+
+	all, err := l.Query(Query{})
+	if err != nil || len(all) != 3 || all[0].Command != "print.printLabel" {
+		t.Fatalf("查询结果应按时间倒序: %+v (%v)", all, err)
+	}
+	if got, _ := l.Query(Query{Printers: []string{"P1"}}); len(got) != 2 {
+		t.Errorf("按打印机筛选结果不正确: %+v", got)
+	}
+	if got, _ := l.Query(Query{Printers: []string{"receipt"}}); len(got) != 1 || got[0].Printer != "P2" {
+		t.Errorf("按逻辑打印机名称筛选结果不正确: %+v", got)
+	}
+	if got, _ := l.Query(Query{Since: base.Add(30 * time.Minute), Until: base.Add(90 * time.Minute)}); len(got) != 1 || got[0].Caller.User != "bob" {
+		t.Errorf("按时间范围筛选结果不正确: %+v", got)
+	}
+	if got, _ := l.Query(Query{User: "bob", Limit: 1}); len(got) != 1 || got[0].Command != "print.printLabel" {
+		t.Errorf("按用户筛选并限制数量的结果不正确: %+v", got)
+	}
+	if got, _ := l.Query(Query{JobID: "job-000001"}); len(got) != 1 || got[0].Command != "print.printLabel" {
+		t.Errorf("按作业筛选结果不正确: %+v", got)
+	}
+}
+
+// TestRotation 测试超过大小上限后轮转，并只保留指定数量的文件
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{MaxSize: 300, MaxFiles: 3})
+	if err != nil {
+		t.Fatalf("打开审计日志失败: %v", err)
+	}
+
+	entry := &Entry{Command: "print.testPrint", Printer: "P1"}
+	entry.SetContent([]byte("hello"))
+	for i := 0; i < 10; i++ {
+		entry.Time = time.Unix(int64(i), 0)
+		if err := l.Record(entry); err != nil {
+			t.Fatalf("写入审计记录失败: %v", err)
+		}
+	}
+
+	for _, name := range []string{"audit.jsonl", "audit.1.jsonl", "audit.2.jsonl"} {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil || fi.Size() > 300 {
+			t.Errorf("日志文件 %s 不存在或超过大小上限: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "audit.3.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("超过保留数量的日志文件应被删除")
+	}
+
+	got, err := l.Query(Query{})
+	if err != nil || len(got) == 0 || len(got) >= 10 || got[0].Time.Unix() != 9 {
+		t.Fatalf("轮转后查询结果不正确: %d 条 (%v)", len(got), err)
+	}
+	for i := 1; i < len(got); i++ {
+		if !got[i].Time.Before(got[i-1].Time) {
+			t.Fatalf("轮转后结果应按时间倒序")
+		}
+	}
+	if got[0].SHA256 != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" || got[0].Bytes != 5 {
+		t.Errorf("内容校验和不正确: %+v", got[0])
+	}
+
+	// 重新打开后继续追加到当前文件
+	l.Close()
+	l, err = Open(dir, Options{MaxSize: 300, MaxFiles: 3})
+	if err != nil {
+		t.Fatalf("重新打开审计日志失败: %v", err)
+	}
+	defer l.Close()
+	entry.Time = time.Unix(10, 0)
+	l.Record(entry)
+	if got, _ := l.Query(Query{Limit: 1}); len(got) != 1 || got[0].Time.Unix() != 10 {
+		t.Errorf("重新打开后应能查询到新记录: %+v", got)
+	}
+}
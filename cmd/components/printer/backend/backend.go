@@ -0,0 +1,134 @@
+// Package backend 抽象了打印组件与操作系统打印子系统之间的交互
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrJobNotFound 表示后端的打印队列中已找不到指定的作业
+	ErrJobNotFound = errors.New("打印作业不存在")
+	// ErrPDFNotSupported 表示打印机不能直接打印 PDF 文档，重新提交也不会成功
+	ErrPDFNotSupported = errors.New("打印机不支持直接打印 PDF 文档")
+)
+
+// JobState 描述打印作业所处的状态
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobSpooling  JobState = "spooling"
+	JobPrinting  JobState = "printing"
+	JobCompleted JobState = "completed"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+	// JobRetrying 表示提交失败、等待作业管理器重新提交，后端不会报告此状态
+	JobRetrying JobState = "retrying"
+)
+
+// Terminal 判断作业是否已处于终止状态
+func (s JobState) Terminal() bool {
+	return s == JobCompleted || s == JobFailed || s == JobCancelled
+}
+
+// 文档的数据类型
+const (
+	// DataTypeRaw 表示打印机语言数据 (ESC/POS、ZPL、PCL 等)，原样发送
+	DataTypeRaw = "RAW"
+	// DataTypePDF 表示 PDF 文档，CUPS 会通过过滤器转换为打印机语言；
+	// Windows 的假脱机程序不转换 PDF，只能原样发送给 Options.PDFPrinters 中支持 PDF 直接打印的打印机
+	DataTypePDF = "PDF"
+)
+
+// Document 是提交给后端的一份待打印文档
+type Document struct {
+	Printer  string
+	Name     string
+	DataType string // 为空时默认使用 RAW
+	Data     []byte
+}
+
+// JobStatus 是后端报告的作业状态
+type JobStatus struct {
+	State        JobState
+	Message      string
+	TotalPages   int
+	PagesPrinted int
+}
+
+// Backend 是所有打印后端必须实现的接口
+type Backend interface {
+	// Name 返回后端名称，例如 "windows"、"cups"
+	Name() string
+
+	// Printers 返回所有可用的打印机名称
+	Printers() ([]string, error)
+
+	// DefaultPrinter 返回系统默认打印机名称，未设置时返回空字符串
+	DefaultPrinter() (string, error)
+
+	// SetDefaultPrinter 设置系统默认打印机
+	SetDefaultPrinter(name string) error
+
+	// PrinterInfo 返回打印机的能力与当前状态
+	PrinterInfo(name string) (*PrinterInfo, error)
+
+	// Submit 将文档写入打印队列，返回后端用于标识该作业的引用
+	Submit(doc *Document) (string, error)
+
+	// JobStatus 查询作业状态，作业已离开队列时返回 ErrJobNotFound
+	JobStatus(printer, ref string) (*JobStatus, error)
+
+	// CancelJob 取消队列中的作业
+	CancelJob(printer, ref string) error
+}
+
+// Options 是创建后端时的配置，后端忽略与其无关的项
+type Options struct {
+	// PDFPrinters 为支持 PDF 直接打印的打印机 (仅 Windows 后端使用)，其他打印机提交 PDF 文档时返回 ErrPDFNotSupported
+	PDFPrinters []string
+}
+
+// Factory 用于创建后端实例
+type Factory func(opts Options) (Backend, error)
+
+var (
+	factories = make(map[string]Factory)
+	mu        sync.RWMutex
+)
+
+// Register 注册一个后端工厂，通常在各实现文件的 init 中调用
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[name] = factory
+}
+
+// New 按名称创建后端，名称为空时使用当前操作系统的默认后端
+func New(name string, opts Options) (Backend, error) {
+	if name == "" {
+		name = defaultName
+	}
+	mu.RLock()
+	factory, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未知的打印后端 '%s' (可用: %v)", name, Names())
+	}
+	return factory(opts)
+}
+
+// Names 返回所有已注册的后端名称
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,246 @@
+//go:build !windows
+
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const defaultName = "cups"
+
+// cupsBackend 通过 CUPS 命令行工具 (lp、lpstat、cancel、lpoptions) 完成打印
+type cupsBackend struct{}
+
+func init() {
+	Register("cups", func(Options) (Backend, error) {
+		if _, err := exec.LookPath("lpstat"); err != nil {
+			return nil, fmt.Errorf("未找到 CUPS 命令行工具: %w", err)
+		}
+		return &cupsBackend{}, nil
+	})
+}
+
+var requestIDPattern = regexp.MustCompile(`request id is (\S+)`)
+
+func (b *cupsBackend) Name() string {
+	return "cups"
+}
+
+func (b *cupsBackend) Printers() ([]string, error) {
+	out, err := runCups(nil, "lpstat", "-e")
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(out), nil
+}
+
+func (b *cupsBackend) DefaultPrinter() (string, error) {
+	out, err := runCups(nil, "lpstat", "-d")
+	if err != nil {
+		return "", err
+	}
+	if _, name, ok := strings.Cut(out, "system default destination:"); ok {
+		return strings.TrimSpace(name), nil
+	}
+	return "", nil
+}
+
+func (b *cupsBackend) SetDefaultPrinter(name string) error {
+	_, err := runCups(nil, "lpoptions", "-d", name)
+	return err
+}
+
+func (b *cupsBackend) PrinterInfo(name string) (*PrinterInfo, error) {
+	state, err := runCups(nil, "lpstat", "-l", "-p", name)
+	if err != nil {
+		return nil, err
+	}
+	defaultPrinter, _ := b.DefaultPrinter()
+
+	info := &PrinterInfo{Name: name, IsDefault: defaultPrinter == name}
+	info.Status, info.StatusMessage = cupsPrinterStatus(state)
+
+	// 能力信息来自 PPD 选项，无 PPD 的打印机 (如 IPP Everywhere 队列) 可能查询失败
+	if options, err := runCups(nil, "lpoptions", "-p", name, "-l"); err == nil {
+		info.Capabilities = parseCupsOptions(options)
+	}
+	info.Capabilities.normalize()
+	return info, nil
+}
+
+func (b *cupsBackend) Submit(doc *Document) (string, error) {
+	args := []string{"-d", doc.Printer, "-t", doc.Name}
+	if doc.DataType == "" || strings.EqualFold(doc.DataType, DataTypeRaw) {
+		args = append(args, "-o", "raw")
+	}
+	out, err := runCups(doc.Data, "lp", args...)
+	if err != nil {
+		return "", err
+	}
+	match := requestIDPattern.FindStringSubmatch(out)
+	if match == nil {
+		return "", fmt.Errorf("无法解析 lp 输出: %s", strings.TrimSpace(out))
+	}
+	return match[1], nil
+}
+
+func (b *cupsBackend) JobStatus(printer, ref string) (*JobStatus, error) {
+	pending, err := runCups(nil, "lpstat", "-W", "not-completed", "-o", printer)
+	if err != nil {
+		return nil, err
+	}
+	if containsJob(pending, ref) {
+		state, err := runCups(nil, "lpstat", "-p", printer)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(state, "now printing "+ref) {
+			return &JobStatus{State: JobPrinting}, nil
+		}
+		return &JobStatus{State: JobSpooling}, nil
+	}
+
+	completed, err := runCups(nil, "lpstat", "-W", "completed", "-o", printer)
+	if err != nil {
+		return nil, err
+	}
+	if containsJob(completed, ref) {
+		return &JobStatus{State: JobCompleted}, nil
+	}
+	return nil, ErrJobNotFound
+}
+
+func (b *cupsBackend) CancelJob(printer, ref string) error {
+	_, err := runCups(nil, "cancel", ref)
+	return err
+}
+
+// cupsPrinterStatus 解析 lpstat -l -p 的输出，返回状态与附加说明
+func cupsPrinterStatus(out string) (PrinterStatus, string) {
+	var alerts string
+	for _, line := range splitLines(out) {
+		if rest, ok := strings.CutPrefix(line, "Alerts:"); ok {
+			alerts = strings.TrimSpace(rest)
+		}
+	}
+	switch {
+	case strings.Contains(alerts, "media-jam"):
+		return StatusJammed, alerts
+	case strings.Contains(alerts, "media-empty"), strings.Contains(alerts, "media-needed"):
+		return StatusPaperOut, alerts
+	case strings.Contains(out, "disabled since"), strings.Contains(alerts, "offline"):
+		return StatusOffline, alerts
+	case strings.Contains(alerts, "-error"):
+		return StatusError, alerts
+	case strings.Contains(out, "now printing"):
+		return StatusPrinting, alerts
+	case strings.Contains(out, "is idle"):
+		return StatusIdle, alerts
+	default:
+		return StatusUnknown, alerts
+	}
+}
+
+// parseCupsOptions 解析 lpoptions -l 的输出，格式为 "Keyword/Label: value1 *default value2"
+func parseCupsOptions(out string) Capabilities {
+	var caps Capabilities
+	for _, line := range splitLines(out) {
+		key, values, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, _, _ = strings.Cut(key, "/")
+		choices := strings.Fields(strings.ReplaceAll(values, "*", ""))
+		switch key {
+		case "PageSize", "media":
+			caps.PaperSizes = choices
+		case "InputSlot":
+			caps.Trays = choices
+		case "ColorModel", "print-color-mode":
+			caps.ColorModes = []string{ColorMonochrome}
+			for _, choice := range choices {
+				lower := strings.ToLower(choice)
+				if lower != "gray" && lower != "grayscale" && lower != "monochrome" && lower != "black" {
+					caps.ColorModes = append(caps.ColorModes, ColorColor)
+					break
+				}
+			}
+		case "Duplex", "sides":
+			caps.DuplexModes = []string{DuplexNone}
+			for _, choice := range choices {
+				switch choice {
+				case "DuplexNoTumble", "two-sided-long-edge":
+					caps.DuplexModes = append(caps.DuplexModes, DuplexLongEdge)
+				case "DuplexTumble", "two-sided-short-edge":
+					caps.DuplexModes = append(caps.DuplexModes, DuplexShortEdge)
+				}
+			}
+		case "Resolution", "printer-resolution":
+			for _, choice := range choices {
+				if res, ok := parseResolution(choice); ok {
+					caps.Resolutions = append(caps.Resolutions, res)
+				}
+			}
+		}
+	}
+	return caps
+}
+
+// parseResolution 解析 "600dpi" 或 "1200x600dpi" 形式的分辨率
+func parseResolution(value string) (Resolution, bool) {
+	value = strings.TrimSuffix(strings.ToLower(value), "dpi")
+	xs, ys, found := strings.Cut(value, "x")
+	if !found {
+		ys = xs
+	}
+	x, errX := strconv.Atoi(xs)
+	y, errY := strconv.Atoi(ys)
+	if errX != nil || errY != nil {
+		return Resolution{}, false
+	}
+	return Resolution{X: x, Y: y}, true
+}
+
+// runCups 以 C 语言环境执行 CUPS 命令，保证输出格式可解析
+func runCups(stdin []byte, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C", "LANG=C")
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s 执行失败: %v: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// containsJob 检查 lpstat -o 的输出中是否包含指定作业
+func containsJob(out, ref string) bool {
+	for _, line := range splitLines(out) {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == ref {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(out string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,48 @@
+//go:build !windows
+
+package backend
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseCupsOptions 测试 lpoptions -l 输出的解析
+func TestParseCupsOptions(t *testing.T) {
+	out := `PageSize/Media Size: Letter *A4 Legal
+InputSlot/Media Source: *Auto Tray1 Manual
+ColorModel/Color Mode: Gray *RGB
+Duplex/2-Sided Printing: *None DuplexNoTumble DuplexTumble
+Resolution/Resolution: 300dpi *600dpi 1200x600dpi
+`
+	caps := parseCupsOptions(out)
+	want := Capabilities{
+		PaperSizes:  []string{"Letter", "A4", "Legal"},
+		Trays:       []string{"Auto", "Tray1", "Manual"},
+		ColorModes:  []string{ColorMonochrome, ColorColor},
+		DuplexModes: []string{DuplexNone, DuplexLongEdge, DuplexShortEdge},
+		Resolutions: []Resolution{{300, 300}, {600, 600}, {1200, 600}},
+	}
+	if !reflect.DeepEqual(caps, want) {
+		t.Errorf("解析结果不正确:\n got: %+v\nwant: %+v", caps, want)
+	}
+}
+
+// TestCupsPrinterStatus 测试 lpstat -l -p 输出的状态映射
+func TestCupsPrinterStatus(t *testing.T) {
+	cases := []struct {
+		out  string
+		want PrinterStatus
+	}{
+		{"printer HP is idle.  enabled since Mon 01 Jan 2024\n\tAlerts: none", StatusIdle},
+		{"printer HP now printing HP-12.  enabled since Mon 01 Jan 2024", StatusPrinting},
+		{"printer HP disabled since Mon 01 Jan 2024 -\n\tPaused", StatusOffline},
+		{"printer HP is idle.  enabled since Mon 01 Jan 2024\n\tAlerts: media-empty-error", StatusPaperOut},
+		{"printer HP is idle.  enabled since Mon 01 Jan 2024\n\tAlerts: media-jam-error", StatusJammed},
+	}
+	for _, c := range cases {
+		if got, _ := cupsPrinterStatus(c.out); got != c.want {
+			t.Errorf("%q: 预期 %s，实际 %s", c.out, c.want, got)
+		}
+	}
+}
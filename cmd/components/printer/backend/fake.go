@@ -0,0 +1,188 @@
+package backend
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Fake 是一个内存中的打印后端，用于开发调试与单元测试
+type Fake struct {
+	mu             sync.Mutex
+	printers       []string
+	defaultPrinter string
+	jobs           map[string]*fakeJob
+	submitted      []Document
+	nextID         int
+	submitErr      error
+	statuses       map[string]PrinterStatus
+}
+
+type fakeJob struct {
+	doc    Document
+	status JobStatus
+}
+
+func init() {
+	Register("fake", func(Options) (Backend, error) {
+		return NewFake("Fake Printer", "Fake Receipt Printer"), nil
+	})
+}
+
+// NewFake 创建一个包含指定打印机的 Fake 后端，第一台打印机为默认打印机
+func NewFake(printers ...string) *Fake {
+	f := &Fake{
+		jobs:     make(map[string]*fakeJob),
+		statuses: make(map[string]PrinterStatus),
+	}
+	f.SetPrinters(printers...)
+	return f
+}
+
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+func (f *Fake) Printers() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.printers...), nil
+}
+
+func (f *Fake) DefaultPrinter() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.defaultPrinter, nil
+}
+
+func (f *Fake) SetDefaultPrinter(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.hasPrinter(name) {
+		return fmt.Errorf("打印机 '%s' 不存在", name)
+	}
+	f.defaultPrinter = name
+	return nil
+}
+
+func (f *Fake) PrinterInfo(name string) (*PrinterInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.hasPrinter(name) {
+		return nil, fmt.Errorf("打印机 '%s' 不存在", name)
+	}
+	status, ok := f.statuses[name]
+	if !ok {
+		status = StatusIdle
+	}
+	return &PrinterInfo{
+		Name:      name,
+		IsDefault: name == f.defaultPrinter,
+		Status:    status,
+		Capabilities: Capabilities{
+			PaperSizes:  []string{"A4", "Letter"},
+			Trays:       []string{"Auto"},
+			ColorModes:  []string{ColorMonochrome},
+			DuplexModes: []string{DuplexNone},
+			Resolutions: []Resolution{{X: 203, Y: 203}},
+		},
+	}, nil
+}
+
+func (f *Fake) Submit(doc *Document) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.submitErr != nil {
+		return "", f.submitErr
+	}
+	if !f.hasPrinter(doc.Printer) {
+		return "", fmt.Errorf("无法打开打印机 '%s'", doc.Printer)
+	}
+	f.nextID++
+	ref := fmt.Sprintf("%s-%d", doc.Printer, f.nextID)
+	copied := *doc
+	copied.Data = append([]byte(nil), doc.Data...)
+	f.jobs[ref] = &fakeJob{doc: copied, status: JobStatus{State: JobSpooling}}
+	f.submitted = append(f.submitted, copied)
+	return ref, nil
+}
+
+func (f *Fake) JobStatus(printer, ref string) (*JobStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job, ok := f.jobs[ref]
+	if !ok || job.doc.Printer != printer {
+		return nil, ErrJobNotFound
+	}
+	status := job.status
+	return &status, nil
+}
+
+func (f *Fake) CancelJob(printer, ref string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job, ok := f.jobs[ref]
+	if !ok || job.doc.Printer != printer {
+		return ErrJobNotFound
+	}
+	job.status.State = JobCancelled
+	return nil
+}
+
+// SetPrinters 替换打印机列表；若原默认打印机不在新列表中，则改用第一台
+func (f *Fake) SetPrinters(printers ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.printers = append([]string(nil), printers...)
+	if !f.hasPrinter(f.defaultPrinter) {
+		f.defaultPrinter = ""
+		if len(printers) > 0 {
+			f.defaultPrinter = printers[0]
+		}
+	}
+}
+
+// SetStatus 修改打印机的状态
+func (f *Fake) SetStatus(name string, status PrinterStatus) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statuses[name] = status
+}
+
+// SetJobState 修改作业在队列中的状态
+func (f *Fake) SetJobState(ref string, state JobState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if job, ok := f.jobs[ref]; ok {
+		job.status.State = state
+	}
+}
+
+// RemoveJob 将作业从队列中移除，模拟打印完成后作业消失
+func (f *Fake) RemoveJob(ref string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.jobs, ref)
+}
+
+// SetSubmitError 使后续的 Submit 调用返回指定错误，传入 nil 恢复正常
+func (f *Fake) SetSubmitError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.submitErr = err
+}
+
+// Submitted 按提交顺序返回所有成功提交过的文档
+func (f *Fake) Submitted() []Document {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Document(nil), f.submitted...)
+}
+
+func (f *Fake) hasPrinter(name string) bool {
+	for _, p := range f.printers {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
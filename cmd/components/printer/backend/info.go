@@ -0,0 +1,67 @@
+package backend
+
+// PrinterStatus 是归一化后的打印机状态
+type PrinterStatus string
+
+const (
+	StatusIdle     PrinterStatus = "idle"
+	StatusPrinting PrinterStatus = "printing"
+	StatusOffline  PrinterStatus = "offline"
+	StatusPaperOut PrinterStatus = "paperOut"
+	StatusJammed   PrinterStatus = "jammed"
+	StatusError    PrinterStatus = "error"
+	StatusUnknown  PrinterStatus = "unknown"
+)
+
+// 颜色与双面模式的统一取值
+const (
+	ColorMonochrome = "monochrome"
+	ColorColor      = "color"
+
+	DuplexNone      = "none"
+	DuplexLongEdge  = "longEdge"
+	DuplexShortEdge = "shortEdge"
+)
+
+// Resolution 表示打印分辨率 (DPI)
+type Resolution struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Capabilities 描述打印机支持的纸张、纸盒、颜色、双面与分辨率
+type Capabilities struct {
+	PaperSizes  []string     `json:"paperSizes"`
+	Trays       []string     `json:"trays"`
+	ColorModes  []string     `json:"colorModes"`
+	DuplexModes []string     `json:"duplexModes"`
+	Resolutions []Resolution `json:"resolutions"`
+}
+
+// PrinterInfo 是单台打印机的能力与状态
+type PrinterInfo struct {
+	Name          string        `json:"name"`
+	IsDefault     bool          `json:"isDefault"`
+	Status        PrinterStatus `json:"status"`
+	StatusMessage string        `json:"statusMessage,omitempty"`
+	Capabilities  Capabilities  `json:"capabilities"`
+}
+
+// normalize 将 nil 切片替换为空切片，保证 JSON 输出稳定
+func (c *Capabilities) normalize() {
+	if c.PaperSizes == nil {
+		c.PaperSizes = []string{}
+	}
+	if c.Trays == nil {
+		c.Trays = []string{}
+	}
+	if c.ColorModes == nil {
+		c.ColorModes = []string{ColorMonochrome}
+	}
+	if c.DuplexModes == nil {
+		c.DuplexModes = []string{DuplexNone}
+	}
+	if c.Resolutions == nil {
+		c.Resolutions = []Resolution{}
+	}
+}
@@ -0,0 +1,189 @@
+//go:build windows
+
+package backend
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	winprinter "github.com/godoes/printers"
+)
+
+const defaultName = "windows"
+
+// windowsBackend 通过 Windows 打印后台处理程序 (winspool) 完成打印
+type windowsBackend struct {
+	// pdfPrinters 为支持 PDF 直接打印的打印机
+	pdfPrinters []string
+}
+
+func init() {
+	Register("windows", func(opts Options) (Backend, error) {
+		return &windowsBackend{pdfPrinters: opts.PDFPrinters}, nil
+	})
+}
+
+func (b *windowsBackend) Name() string {
+	return "windows"
+}
+
+func (b *windowsBackend) Printers() ([]string, error) {
+	return winprinter.ReadNames()
+}
+
+func (b *windowsBackend) DefaultPrinter() (string, error) {
+	return winprinter.GetDefault()
+}
+
+func (b *windowsBackend) SetDefaultPrinter(name string) error {
+	return winprinter.SetDefault(name)
+}
+
+func (b *windowsBackend) PrinterInfo(name string) (*PrinterInfo, error) {
+	snapshot, err := getPrinter2(name)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取打印机 '%s' 的信息: %w", name, err)
+	}
+	defaultPrinter, _ := winprinter.GetDefault()
+
+	info := &PrinterInfo{
+		Name:      name,
+		IsDefault: defaultPrinter == name,
+		Status:    windowsPrinterStatus(snapshot),
+		Capabilities: Capabilities{
+			PaperSizes:  deviceCapabilityNames(name, snapshot.port, dcPaperNames, paperNameLen),
+			Trays:       deviceCapabilityNames(name, snapshot.port, dcBinNames, binNameLen),
+			Resolutions: deviceResolutions(name, snapshot.port),
+			ColorModes:  []string{ColorMonochrome},
+			DuplexModes: []string{DuplexNone},
+		},
+	}
+	if deviceCapabilities(name, snapshot.port, dcColorDevice, nil) == 1 {
+		info.Capabilities.ColorModes = append(info.Capabilities.ColorModes, ColorColor)
+	}
+	if deviceCapabilities(name, snapshot.port, dcDuplex, nil) == 1 {
+		info.Capabilities.DuplexModes = append(info.Capabilities.DuplexModes, DuplexLongEdge, DuplexShortEdge)
+	}
+	info.Capabilities.normalize()
+	return info, nil
+}
+
+// Submit 以文档名作为作业引用，调用方需保证文档名唯一
+func (b *windowsBackend) Submit(doc *Document) (string, error) {
+	// 假脱机程序没有 PDF 数据类型且不转换 PDF，PDF 只能按 RAW 交给支持 PDF 直接打印的打印机，
+	// 其他打印机会把 PDF 当作打印机语言输出乱码
+	dataType := doc.DataType
+	if strings.EqualFold(dataType, DataTypePDF) {
+		if !slices.Contains(b.pdfPrinters, doc.Printer) {
+			return "", fmt.Errorf("%w: '%s' 未配置为支持 PDF 直接打印 (pdfPrinters)", ErrPDFNotSupported, doc.Printer)
+		}
+		dataType = DataTypeRaw
+	}
+	if dataType == "" {
+		dataType = DataTypeRaw
+	}
+
+	printer, err := winprinter.Open(doc.Printer)
+	if err != nil {
+		return "", fmt.Errorf("无法打开打印机 '%s': %w", doc.Printer, err)
+	}
+	defer printer.Close()
+
+	if err := printer.StartDocument(doc.Name, dataType); err != nil {
+		return "", fmt.Errorf("开始打印文档失败: %w", err)
+	}
+	defer printer.EndDocument()
+
+	if err := printer.StartPage(); err != nil {
+		return "", fmt.Errorf("开始页面失败: %w", err)
+	}
+	defer printer.EndPage()
+
+	if len(doc.Data) > 0 {
+		if _, err := printer.Write(doc.Data); err != nil {
+			return "", fmt.Errorf("写入打印内容失败: %w", err)
+		}
+	}
+	return doc.Name, nil
+}
+
+func (b *windowsBackend) JobStatus(printerName, ref string) (*JobStatus, error) {
+	printer, err := winprinter.Open(printerName)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开打印机 '%s': %w", printerName, err)
+	}
+	defer printer.Close()
+
+	jobs, err := printer.Jobs()
+	if err != nil {
+		return nil, fmt.Errorf("枚举打印作业失败: %w", err)
+	}
+	for _, job := range jobs {
+		if job.DocumentName != ref {
+			continue
+		}
+		return &JobStatus{
+			State:        windowsJobState(job.StatusCode),
+			Message:      job.Status,
+			TotalPages:   int(job.TotalPages),
+			PagesPrinted: int(job.PagesPrinted),
+		}, nil
+	}
+	return nil, ErrJobNotFound
+}
+
+func (b *windowsBackend) CancelJob(printerName, ref string) error {
+	printer, err := winprinter.Open(printerName)
+	if err != nil {
+		return fmt.Errorf("无法打开打印机 '%s': %w", printerName, err)
+	}
+	defer printer.Close()
+
+	jobs, err := printer.Jobs()
+	if err != nil {
+		return fmt.Errorf("枚举打印作业失败: %w", err)
+	}
+	for _, job := range jobs {
+		if job.DocumentName == ref {
+			return setJob(printerName, job.JobID, jobControlDelete)
+		}
+	}
+	return ErrJobNotFound
+}
+
+// windowsPrinterStatus 将 PRINTER_INFO_2 的状态位映射为统一的打印机状态
+func windowsPrinterStatus(snapshot *printerSnapshot) PrinterStatus {
+	status := snapshot.status
+	switch {
+	case snapshot.attributes&printerAttributeWorkOffline != 0,
+		status&(printerStatusOffline|printerStatusNotAvailable) != 0:
+		return StatusOffline
+	case status&printerStatusPaperJam != 0:
+		return StatusJammed
+	case status&printerStatusPaperOut != 0:
+		return StatusPaperOut
+	case status&(printerStatusError|printerStatusPaperProblem|printerStatusUserIntervention|printerStatusDoorOpen|printerStatusPaused) != 0:
+		return StatusError
+	case status&(printerStatusPrinting|printerStatusBusy) != 0 || snapshot.jobs > 0:
+		return StatusPrinting
+	default:
+		return StatusIdle
+	}
+}
+
+// windowsJobState 将 JOB_INFO_1 的状态位映射为统一的作业状态
+func windowsJobState(code uint32) JobState {
+	switch {
+	case code&(winprinter.JOB_STATUS_DELETING|winprinter.JOB_STATUS_DELETED) != 0:
+		return JobCancelled
+	case code&(winprinter.JOB_STATUS_ERROR|winprinter.JOB_STATUS_BLOCKED_DEVQ) != 0:
+		return JobFailed
+	case code&(winprinter.JOB_STATUS_PRINTED|winprinter.JOB_STATUS_COMPLETE) != 0:
+		return JobCompleted
+	case code&winprinter.JOB_STATUS_PRINTING != 0:
+		return JobPrinting
+	default:
+		return JobSpooling
+	}
+}
@@ -0,0 +1,182 @@
+package backend
+
+import (
+	"syscall"
+	"unsafe"
+
+	winprinter "github.com/godoes/printers"
+	"golang.org/x/sys/windows"
+)
+
+// printers 库未封装的 winspool 接口
+var (
+	winspool                = syscall.NewLazyDLL("winspool.drv")
+	procSetJobW             = winspool.NewProc("SetJobW")
+	procGetPrinterW         = winspool.NewProc("GetPrinterW")
+	procDeviceCapabilitiesW = winspool.NewProc("DeviceCapabilitiesW")
+)
+
+const jobControlDelete = 5 // JOB_CONTROL_DELETE
+
+// DeviceCapabilities 查询项
+const (
+	dcDuplex          = 7
+	dcBinNames        = 12
+	dcEnumResolutions = 13
+	dcPaperNames      = 16
+	dcColorDevice     = 32
+
+	paperNameLen = 64
+	binNameLen   = 24
+)
+
+// PRINTER_INFO_2 中的状态位与属性位
+const (
+	printerStatusPaused           = 0x00000001
+	printerStatusError            = 0x00000002
+	printerStatusPaperJam         = 0x00000008
+	printerStatusPaperOut         = 0x00000010
+	printerStatusPaperProblem     = 0x00000040
+	printerStatusOffline          = 0x00000080
+	printerStatusBusy             = 0x00000200
+	printerStatusPrinting         = 0x00000400
+	printerStatusNotAvailable     = 0x00001000
+	printerStatusUserIntervention = 0x00100000
+	printerStatusDoorOpen         = 0x00400000
+
+	printerAttributeWorkOffline = 0x00000400
+)
+
+// printerInfo2 与 PRINTER_INFO_2 的内存布局一致，用于读取 printers 库未导出的字段
+type printerInfo2 struct {
+	serverName         *uint16
+	printerName        *uint16
+	shareName          *uint16
+	portName           *uint16
+	driverName         *uint16
+	comment            *uint16
+	location           *uint16
+	devMode            uintptr
+	sepFile            *uint16
+	printProcessor     *uint16
+	datatype           *uint16
+	parameters         *uint16
+	securityDescriptor uintptr
+	attributes         uint32
+	priority           uint32
+	defaultPriority    uint32
+	startTime          uint32
+	untilTime          uint32
+	status             uint32
+	jobs               uint32
+	averagePPM         uint32
+}
+
+// printerSnapshot 是从 PRINTER_INFO_2 中提取出的信息
+type printerSnapshot struct {
+	port       string
+	status     uint32
+	attributes uint32
+	jobs       uint32
+}
+
+// openPrinterHandle 打开打印机并返回原始句柄
+func openPrinterHandle(printerName string) (syscall.Handle, error) {
+	name, err := syscall.UTF16PtrFromString(printerName)
+	if err != nil {
+		return 0, err
+	}
+	var h syscall.Handle
+	if err := winprinter.OpenPrinter(name, &h, nil); err != nil {
+		return 0, err
+	}
+	return h, nil
+}
+
+// setJob 对指定作业执行 SetJob 控制命令
+func setJob(printerName string, jobID uint32, command uint32) error {
+	h, err := openPrinterHandle(printerName)
+	if err != nil {
+		return err
+	}
+	defer winprinter.ClosePrinter(h)
+
+	r1, _, e1 := procSetJobW.Call(uintptr(h), uintptr(jobID), 0, 0, uintptr(command))
+	if r1 == 0 {
+		return e1
+	}
+	return nil
+}
+
+// getPrinter2 读取打印机的 PRINTER_INFO_2
+func getPrinter2(printerName string) (*printerSnapshot, error) {
+	h, err := openPrinterHandle(printerName)
+	if err != nil {
+		return nil, err
+	}
+	defer winprinter.ClosePrinter(h)
+
+	var needed uint32
+	procGetPrinterW.Call(uintptr(h), 2, 0, 0, uintptr(unsafe.Pointer(&needed)))
+	if needed == 0 {
+		return nil, windows.ERROR_INSUFFICIENT_BUFFER
+	}
+	buf := make([]byte, needed)
+	r1, _, e1 := procGetPrinterW.Call(uintptr(h), 2, uintptr(unsafe.Pointer(&buf[0])), uintptr(needed), uintptr(unsafe.Pointer(&needed)))
+	if r1 == 0 {
+		return nil, e1
+	}
+	info := (*printerInfo2)(unsafe.Pointer(&buf[0]))
+	return &printerSnapshot{
+		port:       windows.UTF16PtrToString(info.portName),
+		status:     info.status,
+		attributes: info.attributes,
+		jobs:       info.jobs,
+	}, nil
+}
+
+// deviceCapabilities 调用 DeviceCapabilitiesW，output 为 nil 时只返回数量
+func deviceCapabilities(printerName, port string, capability uint16, output unsafe.Pointer) int {
+	device, _ := syscall.UTF16PtrFromString(printerName)
+	portPtr, _ := syscall.UTF16PtrFromString(port)
+	r1, _, _ := procDeviceCapabilitiesW.Call(
+		uintptr(unsafe.Pointer(device)),
+		uintptr(unsafe.Pointer(portPtr)),
+		uintptr(capability),
+		uintptr(output),
+		0,
+	)
+	return int(int32(r1))
+}
+
+// deviceCapabilityNames 读取定长宽字符数组形式的名称列表 (纸张名称、纸盒名称)
+func deviceCapabilityNames(printerName, port string, capability uint16, nameLen int) []string {
+	count := deviceCapabilities(printerName, port, capability, nil)
+	if count <= 0 {
+		return nil
+	}
+	buf := make([]uint16, count*nameLen)
+	count = deviceCapabilities(printerName, port, capability, unsafe.Pointer(&buf[0]))
+	names := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		if name := windows.UTF16ToString(buf[i*nameLen : (i+1)*nameLen]); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// deviceResolutions 读取打印机支持的分辨率列表
+func deviceResolutions(printerName, port string) []Resolution {
+	count := deviceCapabilities(printerName, port, dcEnumResolutions, nil)
+	if count <= 0 {
+		return nil
+	}
+	buf := make([]int32, count*2)
+	count = deviceCapabilities(printerName, port, dcEnumResolutions, unsafe.Pointer(&buf[0]))
+	resolutions := make([]Resolution, 0, count)
+	for i := 0; i < count; i++ {
+		resolutions = append(resolutions, Resolution{X: int(buf[i*2]), Y: int(buf[i*2+1])})
+	}
+	return resolutions
+}
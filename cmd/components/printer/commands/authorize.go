@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cse-go/cmd/components/printer/audit"
+	"cse-go/cmd/components/printer/policy"
+	"cse-go/internal/commandbus"
+	"cse-go/internal/tracing"
+	pb "cse-go/pkg/api/v1"
+)
+
+// errorCodePermissionDenied 是被访问策略拒绝时结果中的 code 字段
+const errorCodePermissionDenied = "PERMISSION_DENIED"
+
+// Authorize 在执行命令之前按访问策略检查调用方，允许时返回 nil，
+// 拒绝时返回 success 为 false、code 为 PERMISSION_DENIED 的结果，打印命令被拒绝时以 denied 结果写入审计日志
+func Authorize(ctx context.Context, commandName string, params *pb.CommandParams) *pb.CommandResult {
+	if services.Policy == nil {
+		return nil
+	}
+	req := policy.Request{
+		Command: commandName,
+		Printer: targetPrinter(params),
+		Caller:  commandbus.CallerFromContext(ctx),
+	}
+	// 逻辑打印机可能将作业发送到任意一台成员，每台成员都需要被允许
+	if services.Printers != nil && services.Printers.IsLogical(req.Printer) {
+		req.Members = services.Printers.Members(req.Printer)
+	}
+	decision := services.Policy.Check(req)
+	if decision.Allowed {
+		return nil
+	}
+
+	caller := req.Caller.User
+	if caller == "" {
+		caller = req.Caller.Origin
+	}
+	if caller == "" {
+		caller = "匿名调用方"
+	}
+	message := fmt.Sprintf("%s 无权执行命令 '%s'", caller, commandName)
+	if req.Printer != "" {
+		message = fmt.Sprintf("%s 无权对打印机 '%s' 执行命令 '%s'", caller, req.Printer, commandName)
+	}
+	log.Printf("[Policy] 拒绝调用: %s (规则 %s, 来源 %s)", message, decision.Rule, req.Caller.RemoteAddr)
+	if auditedCommands[commandName] {
+		recordAudit(&audit.Entry{
+			Time:             time.Now(),
+			Command:          commandName,
+			Caller:           req.Caller,
+			RequestID:        tracing.RequestID(ctx),
+			RequestedPrinter: req.Printer,
+			Result:           audit.ResultDenied,
+			Message:          fmt.Sprintf("%s (规则 %s)", message, decision.Rule),
+		})
+	}
+
+	result, _ := failure(message, map[string]interface{}{
+		"code":    errorCodePermissionDenied,
+		"command": commandName,
+		"printer": req.Printer,
+		"rule":    decision.Rule,
+	})
+	return result
+}
+
+// targetPrinter 返回命令操作的打印机：优先使用 printerName 参数，操作作业的命令使用作业所属的打印机
+func targetPrinter(params *pb.CommandParams) string {
+	var target struct {
+		PrinterName string `json:"printerName"`
+		JobID       string `json:"jobId"`
+	}
+	// 参数无法解析时由命令自身报告错误
+	_ = decodeParams(params, &target)
+	if target.PrinterName != "" || target.JobID == "" || services.Jobs == nil {
+		return target.PrinterName
+	}
+	if job, err := services.Jobs.Get(target.JobID); err == nil {
+		return job.Printer
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"cse-go/cmd/components/printer/audit"
+	"cse-go/cmd/components/printer/policy"
+	pb "cse-go/pkg/api/v1"
+)
+
+// TestAuthorizeAuditsDeniedPrintCommands 测试被拒绝的打印命令以 denied 结果写入审计日志
+func TestAuthorizeAuditsDeniedPrintCommands(t *testing.T) {
+	auditLog, err := audit.Open(t.TempDir(), audit.Options{})
+	if err != nil {
+		t.Fatalf("打开审计日志失败: %v", err)
+	}
+	defer auditLog.Close()
+	previous := services
+	SetServices(&Services{Audit: auditLog, Policy: &policy.Policy{Default: policy.Deny}})
+	defer SetServices(previous)
+
+	params := &pb.CommandParams{JsonPayload: `{"printerName": "P1"}`}
+	for _, command := range []string{"print.testPrint", "print.getPrinters"} {
+		if Authorize(context.Background(), command, params) == nil {
+			t.Fatalf("命令 '%s' 应被拒绝", command)
+		}
+	}
+
+	entries, err := auditLog.Query(audit.Query{})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("只有被拒绝的打印命令应写入审计日志: %+v (%v)", entries, err)
+	}
+	if e := entries[0]; e.Command != "print.testPrint" || e.Result != audit.ResultDenied || e.Success || e.RequestedPrinter != "P1" {
+		t.Errorf("拒绝记录不正确: %+v", e)
+	}
+}
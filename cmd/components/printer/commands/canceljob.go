@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"log"
+
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 CancelJobCmd 实现了 commandbus.Command 接口
+var _ commandbus.Command = (*CancelJobCmd)(nil)
+
+// CancelJobCmd 取消一个尚未完成的打印作业
+type CancelJobCmd struct{}
+
+// Name 返回命令名称
+func (c *CancelJobCmd) Name() string {
+	return "print.cancelJob"
+}
+
+// GetInfo 返回命令元数据
+func (c *CancelJobCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName:      c.Name(),
+		Description:      "取消一个尚未完成的打印作业。",
+		ParametersSchema: `{"type": "object", "properties": {"jobId": {"type": "string", "description": "作业 ID"}}, "required": ["jobId"]}`,
+		ResultSchema:     `{"type": "object", "properties": {"success": {"type": "boolean"}, "job": {"type": "object"}, "message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 取消打印作业
+func (c *CancelJobCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	var requestParams struct {
+		JobID string `json:"jobId"`
+	}
+	if err := decodeParams(params, &requestParams); err != nil {
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+	if requestParams.JobID == "" {
+		return failure("作业 ID 不能为空", nil)
+	}
+
+	job, err := services.Jobs.Cancel(requestParams.JobID)
+	if err != nil {
+		log.Printf("取消作业 '%s' 失败: %v", requestParams.JobID, err)
+		return failure(err.Error(), nil)
+	}
+
+	log.Printf("作业 '%s' 已取消", job.ID)
+	return jsonResult(map[string]interface{}{
+		"success": true,
+		"job":     job,
+		"message": "作业已取消",
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&CancelJobCmd{})
+}
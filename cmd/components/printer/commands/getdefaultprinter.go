@@ -1,18 +1,13 @@
-//go:build windows
-
-// Package commands 包含了打印组件特定的命令实现
 package commands
 
 import (
-	"encoding/json"
 	"log"
 
 	"cse-go/internal/commandbus"
 	pb "cse-go/pkg/api/v1"
-	winprinter "github.com/godoes/printers"
 )
 
-// 确保 GetPrintersCmd 实现了 commandbus.Command 接口
+// 确保 GetDefaultPrinterCmd 实现了 commandbus.Command 接口
 var _ commandbus.Command = (*GetDefaultPrinterCmd)(nil)
 
 // GetDefaultPrinterCmd 结构体定义
@@ -27,7 +22,7 @@ func (c *GetDefaultPrinterCmd) Name() string {
 func (c *GetDefaultPrinterCmd) GetInfo() *pb.CommandInfo {
 	return &pb.CommandInfo{
 		CommandName:      c.Name(),
-		Description:      "获取系统默认打印机 (Windows)。",
+		Description:      "获取系统默认打印机。",
 		ParametersSchema: `{}`,
 		ResultSchema:     `{"type": "object", "properties": {"success": {"type": "boolean"}, "defaultPrinter": {"type": "string"}, "message": {"type": "string"}}}`,
 	}
@@ -35,44 +30,23 @@ func (c *GetDefaultPrinterCmd) GetInfo() *pb.CommandInfo {
 
 // Execute 获取系统默认打印机
 func (c *GetDefaultPrinterCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
-	// 获取默认打印机
-	defaultPrinter, err := winprinter.GetDefault()
+	defaultPrinter, err := services.Backend.DefaultPrinter()
 	if err != nil {
 		log.Printf("获取默认打印机失败: %v", err)
-		result := map[string]interface{}{
-			"success":        false,
-			"defaultPrinter": "",
-			"message":        "获取默认打印机失败: " + err.Error(),
-		}
-		resultJson, _ := json.Marshal(result)
-		return &pb.CommandResult{
-			JsonPayload: string(resultJson),
-		}, nil
+		return failure("获取默认打印机失败: "+err.Error(), map[string]interface{}{"defaultPrinter": ""})
 	}
 
 	if defaultPrinter == "" {
 		log.Printf("系统未设置默认打印机")
-		result := map[string]interface{}{
-			"success":        false,
-			"defaultPrinter": "",
-			"message":        "系统未设置默认打印机",
-		}
-		resultJson, _ := json.Marshal(result)
-		return &pb.CommandResult{
-			JsonPayload: string(resultJson),
-		}, nil
+		return failure("系统未设置默认打印机", map[string]interface{}{"defaultPrinter": ""})
 	}
 
 	log.Printf("成功获取默认打印机: %s", defaultPrinter)
-	result := map[string]interface{}{
+	return jsonResult(map[string]interface{}{
 		"success":        true,
 		"defaultPrinter": defaultPrinter,
 		"message":        "成功获取默认打印机",
-	}
-	resultJson, _ := json.Marshal(result)
-	return &pb.CommandResult{
-		JsonPayload: string(resultJson),
-	}, nil
+	})
 }
 
 // init 自动注册命令
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 GetEventsCmd 实现了 commandbus.Command 接口
+var _ commandbus.Command = (*GetEventsCmd)(nil)
+
+// GetEventsCmd 获取打印机监视器发布的变化事件
+type GetEventsCmd struct{}
+
+// Name 返回命令名称
+func (c *GetEventsCmd) Name() string {
+	return "print.getEvents"
+}
+
+// GetInfo 返回命令元数据
+func (c *GetEventsCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName: c.Name(),
+		Description: "获取打印机的增加、移除、默认打印机变化与状态变化事件 (printer.added、printer.removed、printer.defaultChanged、printer.statusChanged)。" +
+			"传入上次返回的 lastSeq 以增量获取新事件。",
+		ParametersSchema: `{"type": "object", "properties": {` +
+			`"since": {"type": "integer", "description": "只返回序号大于该值的事件，默认为 0"}, ` +
+			`"limit": {"type": "integer", "description": "最多返回的事件数量"}}}`,
+		ResultSchema: `{"type": "object", "properties": {"success": {"type": "boolean"}, "events": {"type": "array", "items": {"type": "object"}}, "lastSeq": {"type": "integer", "description": "下次作为 since 传入的序号，结果被 limit 截断时为最后一个返回的事件的序号"}, "message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 返回满足条件的事件
+func (c *GetEventsCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	var requestParams struct {
+		Since uint64 `json:"since"`
+		Limit int    `json:"limit"`
+	}
+	if err := decodeParams(params, &requestParams); err != nil {
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+	if services.Monitor == nil {
+		return failure("打印机监视器未启动", nil)
+	}
+
+	events, lastSeq := services.Monitor.Events(requestParams.Since, requestParams.Limit)
+	return jsonResult(map[string]interface{}{
+		"success": true,
+		"events":  events,
+		"lastSeq": lastSeq,
+		"message": "",
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&GetEventsCmd{})
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 GetJobCmd 实现了 commandbus.Command 接口
+var _ commandbus.Command = (*GetJobCmd)(nil)
+
+// GetJobCmd 查询单个打印作业的状态
+type GetJobCmd struct{}
+
+// Name 返回命令名称
+func (c *GetJobCmd) Name() string {
+	return "print.getJob"
+}
+
+// GetInfo 返回命令元数据
+func (c *GetJobCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName:      c.Name(),
+		Description:      "查询打印作业的状态。",
+		ParametersSchema: `{"type": "object", "properties": {"jobId": {"type": "string", "description": "作业 ID"}}, "required": ["jobId"]}`,
+		ResultSchema:     `{"type": "object", "properties": {"success": {"type": "boolean"}, "job": {"type": "object"}, "message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 查询打印作业的状态
+func (c *GetJobCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	var requestParams struct {
+		JobID string `json:"jobId"`
+	}
+	if err := decodeParams(params, &requestParams); err != nil {
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+	if requestParams.JobID == "" {
+		return failure("作业 ID 不能为空", nil)
+	}
+
+	job, err := services.Jobs.Get(requestParams.JobID)
+	if err != nil {
+		return failure(err.Error()+": "+requestParams.JobID, nil)
+	}
+	return jsonResult(map[string]interface{}{
+		"success": true,
+		"job":     job,
+		"message": "",
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&GetJobCmd{})
+}
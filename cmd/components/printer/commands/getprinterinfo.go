@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"log"
+
+	"cse-go/cmd/components/printer/backend"
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 GetPrinterInfoCmd 实现了 commandbus.Command 接口
+var _ commandbus.Command = (*GetPrinterInfoCmd)(nil)
+
+// printerInfoSchema 是归一化后的打印机能力与状态对象的 JSON Schema
+const printerInfoSchema = `{"type": "object", "properties": {` +
+	`"name": {"type": "string"}, ` +
+	`"isDefault": {"type": "boolean"}, ` +
+	`"status": {"type": "string", "enum": ["idle", "printing", "offline", "paperOut", "jammed", "error", "unknown"]}, ` +
+	`"statusMessage": {"type": "string"}, ` +
+	`"capabilities": {"type": "object", "properties": {` +
+	`"paperSizes": {"type": "array", "items": {"type": "string"}}, ` +
+	`"trays": {"type": "array", "items": {"type": "string"}}, ` +
+	`"colorModes": {"type": "array", "items": {"type": "string", "enum": ["monochrome", "color"]}}, ` +
+	`"duplexModes": {"type": "array", "items": {"type": "string", "enum": ["none", "longEdge", "shortEdge"]}}, ` +
+	`"resolutions": {"type": "array", "items": {"type": "object", "properties": {"x": {"type": "integer"}, "y": {"type": "integer"}}}}}}}}`
+
+// GetPrinterInfoCmd 查询打印机的能力与状态
+type GetPrinterInfoCmd struct{}
+
+// Name 返回命令名称
+func (c *GetPrinterInfoCmd) Name() string {
+	return "print.getPrinterInfo"
+}
+
+// GetInfo 返回命令元数据
+func (c *GetPrinterInfoCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName:      c.Name(),
+		Description:      "查询打印机支持的纸张、纸盒、颜色、双面与分辨率，以及当前状态。",
+		ParametersSchema: `{"type": "object", "properties": {"printerName": {"type": "string", "description": "打印机名称或逻辑打印机名称"}}, "required": ["printerName"]}`,
+		ResultSchema:     `{"type": "object", "properties": {"success": {"type": "boolean"}, "printer": ` + printerInfoSchema + `, "message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 查询打印机的能力与状态
+func (c *GetPrinterInfoCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	var requestParams struct {
+		PrinterName string `json:"printerName"`
+	}
+	if err := decodeParams(params, &requestParams); err != nil {
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+	if requestParams.PrinterName == "" {
+		return failure("打印机名称不能为空", nil)
+	}
+
+	printerName, err := resolvePrinter(requestParams.PrinterName)
+	if err != nil {
+		return failure(err.Error(), nil)
+	}
+	info, err := services.Backend.PrinterInfo(printerName)
+	if err != nil {
+		log.Printf("查询打印机 '%s' 信息失败: %v", printerName, err)
+		return failure(err.Error(), nil)
+	}
+	return jsonResult(map[string]interface{}{
+		"success": true,
+		"printer": info,
+		"message": "",
+	})
+}
+
+// printerInfoOrUnknown 查询打印机信息，失败时返回状态为 unknown 的占位对象
+func printerInfoOrUnknown(name string) *backend.PrinterInfo {
+	info, err := services.Backend.PrinterInfo(name)
+	if err == nil {
+		return info
+	}
+	log.Printf("查询打印机 '%s' 信息失败: %v", name, err)
+	unknown := &backend.PrinterInfo{
+		Name:          name,
+		Status:        backend.StatusUnknown,
+		StatusMessage: err.Error(),
+	}
+	unknown.Capabilities.PaperSizes = []string{}
+	unknown.Capabilities.Trays = []string{}
+	unknown.Capabilities.ColorModes = []string{}
+	unknown.Capabilities.DuplexModes = []string{}
+	unknown.Capabilities.Resolutions = []backend.Resolution{}
+	return unknown
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&GetPrinterInfoCmd{})
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"log"
+
+	"cse-go/cmd/components/printer/backend"
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 GetPrintersCmd 实现了 commandbus.Command 接口
+var _ commandbus.Command = (*GetPrintersCmd)(nil)
+
+// GetPrintersCmd 结构体定义
+type GetPrintersCmd struct{}
+
+// Name 返回命令名称
+func (c *GetPrintersCmd) Name() string {
+	return "print.getPrinters"
+}
+
+// GetInfo 返回命令元数据
+func (c *GetPrintersCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName:      c.Name(),
+		Description:      "获取所有可用的打印机列表，detailed 为 true 时返回每台打印机的能力与状态。",
+		ParametersSchema: `{"type": "object", "properties": {"detailed": {"type": "boolean", "description": "是否返回详细信息", "default": false}}}`,
+		ResultSchema:     `{"type": "array", "items": {"oneOf": [{"type": "string"}, ` + printerInfoSchema + `]}}`,
+	}
+}
+
+// Execute 获取所有可用的打印机列表
+func (c *GetPrintersCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	var requestParams struct {
+		Detailed bool `json:"detailed"`
+	}
+	if err := decodeParams(params, &requestParams); err != nil {
+		log.Printf("解析参数失败: %v", err)
+	}
+
+	printerNames, err := services.Backend.Printers()
+	if err != nil {
+		log.Printf("获取打印机列表失败: %v", err)
+		// 如果获取失败，返回空列表而不是错误，保证系统稳定性
+		return jsonResult([]string{})
+	}
+	if printerNames == nil {
+		printerNames = []string{}
+	}
+
+	log.Printf("成功获取到 %d 个打印机", len(printerNames))
+	if !requestParams.Detailed {
+		return jsonResult(printerNames)
+	}
+
+	printers := make([]*backend.PrinterInfo, 0, len(printerNames))
+	for _, name := range printerNames {
+		printers = append(printers, printerInfoOrUnknown(name))
+	}
+	return jsonResult(printers)
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&GetPrintersCmd{})
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"time"
+
+	"cse-go/cmd/components/printer/backend"
+	"cse-go/cmd/components/printer/jobs"
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 ListJobsCmd 实现了 commandbus.Command 接口
+var _ commandbus.Command = (*ListJobsCmd)(nil)
+
+// ListJobsCmd 列出打印作业及历史记录
+type ListJobsCmd struct{}
+
+// Name 返回命令名称
+func (c *ListJobsCmd) Name() string {
+	return "print.listJobs"
+}
+
+// GetInfo 返回命令元数据
+func (c *ListJobsCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName: c.Name(),
+		Description: "列出打印作业，可按打印机、状态和创建时间筛选，结果按创建时间倒序排列。",
+		ParametersSchema: `{"type": "object", "properties": {` +
+			`"printerName": {"type": "string", "description": "打印机名称或逻辑打印机名称"}, ` +
+			`"state": {"type": "string", "enum": ["queued", "retrying", "spooling", "printing", "completed", "failed", "cancelled"]}, ` +
+			`"since": {"type": "string", "format": "date-time", "description": "起始时间 (RFC3339)"}, ` +
+			`"until": {"type": "string", "format": "date-time", "description": "结束时间 (RFC3339)"}, ` +
+			`"limit": {"type": "integer", "description": "最多返回的作业数量"}}}`,
+		ResultSchema: `{"type": "object", "properties": {"success": {"type": "boolean"}, "jobs": {"type": "array", "items": {"type": "object"}}, "message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 列出满足条件的打印作业
+func (c *ListJobsCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	var requestParams struct {
+		PrinterName string     `json:"printerName"`
+		State       string     `json:"state"`
+		Since       *time.Time `json:"since"`
+		Until       *time.Time `json:"until"`
+		Limit       int        `json:"limit"`
+	}
+	if err := decodeParams(params, &requestParams); err != nil {
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+
+	filter := jobs.Filter{
+		Printer: requestParams.PrinterName,
+		State:   backend.JobState(requestParams.State),
+		Limit:   requestParams.Limit,
+	}
+	// 逻辑打印机匹配其所有成员的作业
+	if services.Printers != nil && services.Printers.IsLogical(requestParams.PrinterName) {
+		filter.Printer = ""
+		filter.Printers = services.Printers.Members(requestParams.PrinterName)
+	}
+	if requestParams.Since != nil {
+		filter.Since = *requestParams.Since
+	}
+	if requestParams.Until != nil {
+		filter.Until = *requestParams.Until
+	}
+
+	return jsonResult(map[string]interface{}{
+		"success": true,
+		"jobs":    services.Jobs.List(filter),
+		"message": "",
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&ListJobsCmd{})
+}
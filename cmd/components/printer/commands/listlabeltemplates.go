@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"log"
+
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 ListLabelTemplatesCmd 实现了 commandbus.Command 接口
+var _ commandbus.Command = (*ListLabelTemplatesCmd)(nil)
+
+// ListLabelTemplatesCmd 列出所有可用的 ZPL 标签模板
+type ListLabelTemplatesCmd struct{}
+
+// Name 返回命令名称
+func (c *ListLabelTemplatesCmd) Name() string {
+	return "print.listLabelTemplates"
+}
+
+// GetInfo 返回命令元数据
+func (c *ListLabelTemplatesCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName:      c.Name(),
+		Description:      "列出所有可用的 ZPL 标签模板及其字段。",
+		ParametersSchema: `{}`,
+		ResultSchema: `{"type": "object", "properties": {"success": {"type": "boolean"}, "templates": {"type": "array", "items": {"type": "object", "properties": {` +
+			`"name": {"type": "string"}, "fields": {"type": "array", "items": {"type": "string"}}, "size": {"type": "integer"}, "modifiedAt": {"type": "string", "format": "date-time"}}}}, ` +
+			`"message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 列出标签模板
+func (c *ListLabelTemplatesCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	templates, err := services.Labels.List()
+	if err != nil {
+		log.Printf("列出标签模板失败: %v", err)
+		return failure(err.Error(), nil)
+	}
+	return jsonResult(map[string]interface{}{
+		"success":   true,
+		"templates": templates,
+		"message":   "",
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&ListLabelTemplatesCmd{})
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"cse-go/cmd/components/printer/audit"
+	"cse-go/cmd/components/printer/backend"
+	"cse-go/cmd/components/printer/labels"
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 PrintLabelCmd 实现了 commandbus.ContextCommand 接口
+var _ commandbus.ContextCommand = (*PrintLabelCmd)(nil)
+
+// PrintLabelCmd 使用 ZPL 模板生成标签并发送到标签打印机
+type PrintLabelCmd struct{}
+
+// missingFields 记录某条数据缺失的字段
+type missingFields struct {
+	Record int      `json:"record"`
+	Fields []string `json:"fields"`
+}
+
+// Name 返回命令名称
+func (c *PrintLabelCmd) Name() string {
+	return "print.printLabel"
+}
+
+// GetInfo 返回命令元数据
+func (c *PrintLabelCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName: c.Name(),
+		Description: "使用 ZPL 标签模板填充数据并发送到标签打印机，records 中的每条数据生成一张标签。",
+		ParametersSchema: `{"type": "object", "properties": {` +
+			`"printerName": {"type": "string", "description": "打印机名称或逻辑打印机名称"}, ` +
+			`"template": {"type": "string", "description": "模板名称"}, ` +
+			`"data": {"type": "object", "description": "单张标签的数据"}, ` +
+			`"records": {"type": "array", "items": {"type": "object"}, "description": "批量标签的数据"}}, ` +
+			`"required": ["printerName", "template"]}`,
+		ResultSchema: `{"type": "object", "properties": {"success": {"type": "boolean"}, "printer": {"type": "string", "description": "实际使用的打印机"}, "jobId": {"type": "string"}, "labels": {"type": "integer"}, ` +
+			`"missingFields": {"type": "array", "items": {"type": "object", "properties": {"record": {"type": "integer"}, "fields": {"type": "array", "items": {"type": "string"}}}}}, ` +
+			`"message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 在没有调用上下文时执行命令
+func (c *PrintLabelCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	return c.ExecuteContext(context.Background(), params)
+}
+
+// ExecuteContext 执行命令并记录审计日志
+func (c *PrintLabelCmd) ExecuteContext(ctx context.Context, params *pb.CommandParams) (*pb.CommandResult, error) {
+	return auditedExecute(ctx, c.Name(), params, c.execute)
+}
+
+// execute 填充模板并提交打印
+func (c *PrintLabelCmd) execute(params *pb.CommandParams, entry *audit.Entry) (*pb.CommandResult, error) {
+	var requestParams struct {
+		PrinterName string           `json:"printerName"`
+		Template    string           `json:"template"`
+		Data        map[string]any   `json:"data"`
+		Records     []map[string]any `json:"records"`
+	}
+	if err := decodeParams(params, &requestParams); err != nil {
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+	if requestParams.PrinterName == "" {
+		return failure("打印机名称不能为空", nil)
+	}
+	if requestParams.Template == "" {
+		return failure("模板名称不能为空", nil)
+	}
+	entry.RequestedPrinter = requestParams.PrinterName
+	entry.Document = "标签 " + requestParams.Template
+
+	records := requestParams.Records
+	if requestParams.Data != nil {
+		records = append([]map[string]any{requestParams.Data}, records...)
+	}
+	if len(records) == 0 {
+		return failure("必须提供 data 或 records", nil)
+	}
+
+	tpl, err := services.Labels.Load(requestParams.Template)
+	if err != nil {
+		if errors.Is(err, labels.ErrTemplateNotFound) {
+			return failure(err.Error(), nil)
+		}
+		log.Printf("加载标签模板失败: %v", err)
+		return failure("加载标签模板失败: "+err.Error(), nil)
+	}
+
+	// 在发送任何内容之前校验所有数据
+	var missing []missingFields
+	for i, record := range records {
+		if fields := tpl.Missing(record); len(fields) > 0 {
+			missing = append(missing, missingFields{Record: i, Fields: fields})
+		}
+	}
+	if len(missing) > 0 {
+		return failure(fmt.Sprintf("%d 条数据缺少模板字段", len(missing)),
+			map[string]interface{}{"missingFields": missing})
+	}
+
+	var sb strings.Builder
+	for _, record := range records {
+		label, err := tpl.Render(record)
+		if err != nil {
+			return failure("生成标签失败: "+err.Error(), nil)
+		}
+		sb.WriteString(label)
+	}
+
+	printerName, err := resolvePrinter(requestParams.PrinterName)
+	if err != nil {
+		return failure(err.Error(), nil)
+	}
+	entry.Printer = printerName
+
+	// 标签打印以标签张数作为页数
+	entry.Pages = len(records)
+	entry.SetContent([]byte(sb.String()))
+	job, err := services.Jobs.Submit(&backend.Document{
+		Printer:  printerName,
+		Name:     "标签 " + tpl.Name,
+		DataType: "RAW",
+		Data:     []byte(sb.String()),
+	})
+	if err != nil {
+		log.Printf("发送标签失败: %v", err)
+		return failure(fmt.Sprintf("发送标签到打印机 '%s' 失败: %v", printerName, err),
+			map[string]interface{}{"printer": printerName, "jobId": job.ID, "labels": len(records)})
+	}
+
+	log.Printf("成功发送 %d 张标签到打印机: %s (作业 %s)", len(records), printerName, job.ID)
+	return jsonResult(map[string]interface{}{
+		"success": true,
+		"printer": printerName,
+		"jobId":   job.ID,
+		"labels":  len(records),
+		"message": submitMessage(job, fmt.Sprintf("%d 张标签已成功发送到打印机 '%s'", len(records), printerName)),
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&PrintLabelCmd{})
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"cse-go/cmd/components/printer/audit"
+	"cse-go/cmd/components/printer/backend"
+	"cse-go/cmd/components/printer/escpos"
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 PrintReceiptCmd 实现了 commandbus.ContextCommand 接口
+var _ commandbus.ContextCommand = (*PrintReceiptCmd)(nil)
+
+// PrintReceiptCmd 将结构化小票编码为 ESC/POS 指令并发送到热敏打印机
+type PrintReceiptCmd struct{}
+
+// Name 返回命令名称
+func (c *PrintReceiptCmd) Name() string {
+	return "print.printReceipt"
+}
+
+// GetInfo 返回命令元数据
+func (c *PrintReceiptCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName: c.Name(),
+		Description: "将结构化小票文档编码为 ESC/POS 指令并发送到热敏打印机。",
+		ParametersSchema: `{"type": "object", "properties": {` +
+			`"printerName": {"type": "string", "description": "打印机名称或逻辑打印机名称"}, ` +
+			`"documentName": {"type": "string", "description": "打印队列中显示的文档名", "default": "小票"}, ` +
+			`"document": {"type": "object", "properties": {` +
+			`"width": {"type": "integer", "description": "每行半角字符数", "default": 48}, ` +
+			`"dotsPerLine": {"type": "integer", "description": "每行打印点数", "default": 576}, ` +
+			`"codePage": {"type": "string", "enum": ["gb18030", "cp437", "cp850", "cp858", "cp1252"], "default": "gb18030"}, ` +
+			`"elements": {"type": "array", "items": {"type": "object", "properties": {` +
+			`"type": {"type": "string", "enum": ["text", "separator", "table", "barcode", "qrcode", "image", "feed", "cut", "cashDrawer"]}}, "required": ["type"]}}}, ` +
+			`"required": ["elements"]}}, "required": ["printerName", "document"]}`,
+		ResultSchema: `{"type": "object", "properties": {"success": {"type": "boolean"}, "printer": {"type": "string", "description": "实际使用的打印机"}, "jobId": {"type": "string"}, "bytes": {"type": "integer"}, "message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 在没有调用上下文时执行命令
+func (c *PrintReceiptCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	return c.ExecuteContext(context.Background(), params)
+}
+
+// ExecuteContext 执行命令并记录审计日志
+func (c *PrintReceiptCmd) ExecuteContext(ctx context.Context, params *pb.CommandParams) (*pb.CommandResult, error) {
+	return auditedExecute(ctx, c.Name(), params, c.execute)
+}
+
+// execute 编码小票并提交打印
+func (c *PrintReceiptCmd) execute(params *pb.CommandParams, entry *audit.Entry) (*pb.CommandResult, error) {
+	var requestParams struct {
+		PrinterName  string           `json:"printerName"`
+		DocumentName string           `json:"documentName"`
+		Document     *escpos.Document `json:"document"`
+	}
+	if err := decodeParams(params, &requestParams); err != nil {
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+	if requestParams.PrinterName == "" {
+		return failure("打印机名称不能为空", nil)
+	}
+	if requestParams.Document == nil {
+		return failure("小票内容不能为空", nil)
+	}
+	if requestParams.DocumentName == "" {
+		requestParams.DocumentName = "小票"
+	}
+	entry.RequestedPrinter = requestParams.PrinterName
+	entry.Document = requestParams.DocumentName
+
+	data, err := escpos.Encode(requestParams.Document)
+	if err != nil {
+		return failure("小票编码失败: "+err.Error(), nil)
+	}
+
+	printerName, err := resolvePrinter(requestParams.PrinterName)
+	if err != nil {
+		return failure(err.Error(), nil)
+	}
+	entry.Printer = printerName
+
+	entry.SetContent(data)
+	job, err := services.Jobs.Submit(&backend.Document{
+		Printer:  printerName,
+		Name:     requestParams.DocumentName,
+		DataType: "RAW",
+		Data:     data,
+	})
+	if err != nil {
+		log.Printf("发送小票失败: %v", err)
+		return failure(fmt.Sprintf("发送小票到打印机 '%s' 失败: %v", printerName, err),
+			map[string]interface{}{"printer": printerName, "jobId": job.ID, "bytes": len(data)})
+	}
+
+	log.Printf("成功发送小票到打印机: %s (作业 %s, %d 字节)", printerName, job.ID, len(data))
+	return jsonResult(map[string]interface{}{
+		"success": true,
+		"printer": printerName,
+		"jobId":   job.ID,
+		"bytes":   len(data),
+		"message": submitMessage(job, fmt.Sprintf("小票已成功发送到打印机 '%s'", printerName)),
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&PrintReceiptCmd{})
+}
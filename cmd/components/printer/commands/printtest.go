@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cse-go/cmd/components/printer/audit"
+	"cse-go/cmd/components/printer/backend"
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 PrintTestCmd 实现了 commandbus.ContextCommand 接口
+var _ commandbus.ContextCommand = (*PrintTestCmd)(nil)
+
+// PrintTestCmd 结构体定义
+type PrintTestCmd struct{}
+
+// Name 返回命令名称
+func (c *PrintTestCmd) Name() string {
+	return "print.testPrint"
+}
+
+// GetInfo 返回命令元数据
+func (c *PrintTestCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName:      c.Name(),
+		Description:      "发送测试页到指定的打印机。",
+		ParametersSchema: `{"type": "object", "properties": {"printerName": {"type": "string", "description": "打印机名称或逻辑打印机名称"}}, "required": ["printerName"]}`,
+		ResultSchema:     `{"type": "object", "properties": {"success": {"type": "boolean"}, "printer": {"type": "string", "description": "实际使用的打印机"}, "jobId": {"type": "string"}, "message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 在没有调用上下文时执行命令
+func (c *PrintTestCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	return c.ExecuteContext(context.Background(), params)
+}
+
+// ExecuteContext 执行命令并记录审计日志
+func (c *PrintTestCmd) ExecuteContext(ctx context.Context, params *pb.CommandParams) (*pb.CommandResult, error) {
+	return auditedExecute(ctx, c.Name(), params, c.execute)
+}
+
+// execute 发送测试页到指定的打印机
+func (c *PrintTestCmd) execute(params *pb.CommandParams, entry *audit.Entry) (*pb.CommandResult, error) {
+	// 解析参数
+	var requestParams struct {
+		PrinterName string `json:"printerName"`
+	}
+
+	if err := decodeParams(params, &requestParams); err != nil {
+		log.Printf("解析参数失败: %v", err)
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+
+	if requestParams.PrinterName == "" {
+		return failure("打印机名称不能为空", nil)
+	}
+
+	entry.RequestedPrinter = requestParams.PrinterName
+	printerName, err := resolvePrinter(requestParams.PrinterName)
+	if err != nil {
+		return failure(err.Error(), nil)
+	}
+	entry.Printer = printerName
+
+	// 写入测试内容
+	currentTime := time.Now().Format("2006-01-02 15:04:05")
+	testContent := fmt.Sprintf(`打印机测试页
+
+打印机名称: %s
+打印时间: %s
+
+这是一个测试页面，用于验证打印机是否正常工作。
+如果您能看到这个页面，说明打印机工作正常。
+
+功能测试项目:
+✓ 打印机连接正常
+✓ 数据传输正常
+✓ 文本输出正常
+
+测试完成。`, printerName, currentTime)
+
+	entry.Document = "测试页"
+	entry.Pages = 1
+	entry.SetContent([]byte(testContent))
+	job, err := services.Jobs.Submit(&backend.Document{
+		Printer:  printerName,
+		Name:     "测试页",
+		DataType: "RAW",
+		Data:     []byte(testContent),
+	})
+	if err != nil {
+		log.Printf("发送测试页失败: %v", err)
+		return failure(fmt.Sprintf("发送测试页到打印机 '%s' 失败: %v", printerName, err),
+			map[string]interface{}{"printer": printerName, "jobId": job.ID})
+	}
+
+	log.Printf("成功发送测试页到打印机: %s (作业 %s)", printerName, job.ID)
+	return jsonResult(map[string]interface{}{
+		"success": true,
+		"printer": printerName,
+		"jobId":   job.ID,
+		"message": submitMessage(job, fmt.Sprintf("测试页已成功发送到打印机 '%s'", printerName)),
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&PrintTestCmd{})
+}
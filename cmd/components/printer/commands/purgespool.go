@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 PurgeSpoolCmd 实现了 commandbus.Command 接口
+var _ commandbus.Command = (*PurgeSpoolCmd)(nil)
+
+// PurgeSpoolCmd 删除假脱机目录中保存的文档内容
+type PurgeSpoolCmd struct{}
+
+// Name 返回命令名称
+func (c *PurgeSpoolCmd) Name() string {
+	return "print.purgeSpool"
+}
+
+// GetInfo 返回命令元数据
+func (c *PurgeSpoolCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName: c.Name(),
+		Description: "删除假脱机目录中保存的文档内容。指定 jobId 时只清除该作业，否则清除所有已结束作业的内容以及残留文件。被清除的作业不能再重试。",
+		ParametersSchema: `{"type": "object", "properties": {` +
+			`"jobId": {"type": "string", "description": "作业 ID，为空时清理全部"}, ` +
+			`"includePending": {"type": "boolean", "description": "同时放弃等待重试的作业并将其标记为失败"}}}`,
+		ResultSchema: `{"type": "object", "properties": {"success": {"type": "boolean"}, "jobs": {"type": "array", "items": {"type": "string"}}, "files": {"type": "integer"}, "bytes": {"type": "integer"}, "message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 清理假脱机目录
+func (c *PurgeSpoolCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	var requestParams struct {
+		JobID          string `json:"jobId"`
+		IncludePending bool   `json:"includePending"`
+	}
+	if err := decodeParams(params, &requestParams); err != nil {
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+
+	result, err := services.Jobs.PurgeSpool(requestParams.JobID, requestParams.IncludePending)
+	if err != nil && result == nil {
+		log.Printf("清理假脱机目录失败: %v", err)
+		return failure(err.Error(), nil)
+	}
+
+	message := fmt.Sprintf("已清除 %d 个文件，共 %d 字节", result.Files, result.Bytes)
+	if err != nil {
+		message += "，但未能完全清理: " + err.Error()
+	}
+	log.Printf("[Job Manager] %s", message)
+	return jsonResult(map[string]interface{}{
+		"success": err == nil,
+		"jobs":    result.Jobs,
+		"files":   result.Files,
+		"bytes":   result.Bytes,
+		"message": message,
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&PurgeSpoolCmd{})
+}
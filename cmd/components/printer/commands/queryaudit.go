@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"log"
+	"time"
+
+	"cse-go/cmd/components/printer/audit"
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 QueryAuditCmd 实现了 commandbus.Command 接口
+var _ commandbus.Command = (*QueryAuditCmd)(nil)
+
+// QueryAuditCmd 检索打印审计日志
+type QueryAuditCmd struct{}
+
+// Name 返回命令名称
+func (c *QueryAuditCmd) Name() string {
+	return "print.queryAudit"
+}
+
+// GetInfo 返回命令元数据
+func (c *QueryAuditCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName: c.Name(),
+		Description: "检索打印审计日志，可按时间范围、打印机、调用用户、命令和作业筛选，结果按时间倒序排列。被访问策略拒绝的调用以 result 为 denied 记录，自动重试的结果以命令 print.autoRetry 记录，其 jobId 与原始记录相同。",
+		ParametersSchema: `{"type": "object", "properties": {` +
+			`"since": {"type": "string", "format": "date-time", "description": "起始时间 (RFC3339)"}, ` +
+			`"until": {"type": "string", "format": "date-time", "description": "结束时间 (RFC3339)"}, ` +
+			`"printerName": {"type": "string", "description": "打印机名称或逻辑打印机名称"}, ` +
+			`"user": {"type": "string", "description": "调用用户"}, ` +
+			`"command": {"type": "string", "description": "命令名称，例如 print.printReceipt"}, ` +
+			`"jobId": {"type": "string", "description": "作业 ID，返回该作业的原始记录与自动重试记录"}, ` +
+			`"limit": {"type": "integer", "description": "最多返回的记录数量", "default": 100}}}`,
+		ResultSchema: `{"type": "object", "properties": {"success": {"type": "boolean"}, "entries": {"type": "array", "items": {"type": "object"}}, "message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 检索审计日志
+func (c *QueryAuditCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	var requestParams struct {
+		Since       *time.Time `json:"since"`
+		Until       *time.Time `json:"until"`
+		PrinterName string     `json:"printerName"`
+		User        string     `json:"user"`
+		Command     string     `json:"command"`
+		JobID       string     `json:"jobId"`
+		Limit       int        `json:"limit"`
+	}
+	if err := decodeParams(params, &requestParams); err != nil {
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+	if services.Audit == nil {
+		return failure("审计日志未启用", nil)
+	}
+
+	query := audit.Query{
+		User:    requestParams.User,
+		Command: requestParams.Command,
+		JobID:   requestParams.JobID,
+		Limit:   requestParams.Limit,
+	}
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+	if requestParams.PrinterName != "" {
+		// 逻辑打印机同时匹配其所有成员
+		query.Printers = []string{requestParams.PrinterName}
+		if services.Printers != nil && services.Printers.IsLogical(requestParams.PrinterName) {
+			query.Printers = append(query.Printers, services.Printers.Members(requestParams.PrinterName)...)
+		}
+	}
+	if requestParams.Since != nil {
+		query.Since = *requestParams.Since
+	}
+	if requestParams.Until != nil {
+		query.Until = *requestParams.Until
+	}
+
+	entries, err := services.Audit.Query(query)
+	if err != nil {
+		log.Printf("检索审计日志失败: %v", err)
+		return failure(err.Error(), nil)
+	}
+	return jsonResult(map[string]interface{}{
+		"success": true,
+		"entries": entries,
+		"message": "",
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&QueryAuditCmd{})
+}
@@ -15,6 +15,19 @@ func TestAutoRegistration(t *testing.T) {
 		"print.getDefaultPrinter",
 		"print.setDefaultPrinter",
 		"print.testPrint",
+		"print.getJob",
+		"print.listJobs",
+		"print.cancelJob",
+		"print.getPrinterInfo",
+		"print.printReceipt",
+		"print.printLabel",
+		"print.listLabelTemplates",
+		"print.renderAndPrint",
+		"print.renderPreview",
+		"print.retryJob",
+		"print.purgeSpool",
+		"print.getEvents",
+		"print.queryAudit",
 	}
 	
 	for _, expectedCmd := range expectedCommands {
@@ -74,14 +87,14 @@ func TestRegistryThreadSafety(t *testing.T) {
 func TestRegistryUtilityMethods(t *testing.T) {
 	// 测试命令数量
 	count := GlobalRegistry.GetCommandCount()
-	if count != 4 {
-		t.Errorf("预期命令数量为 4，实际为 %d", count)
+	if count != 17 {
+		t.Errorf("预期命令数量为 17，实际为 %d", count)
 	}
 	
 	// 测试命令列表
 	cmdNames := GlobalRegistry.ListCommands()
-	if len(cmdNames) != 4 {
-		t.Errorf("预期命令列表长度为 4，实际为 %d", len(cmdNames))
+	if len(cmdNames) != 17 {
+		t.Errorf("预期命令列表长度为 17，实际为 %d", len(cmdNames))
 	}
 	
 	// 验证所有预期的命令都在列表中
@@ -90,6 +103,19 @@ func TestRegistryUtilityMethods(t *testing.T) {
 		"print.getDefaultPrinter":  false,
 		"print.setDefaultPrinter":  false,
 		"print.testPrint":          false,
+		"print.getJob":             false,
+		"print.listJobs":           false,
+		"print.cancelJob":          false,
+		"print.getPrinterInfo":     false,
+		"print.printReceipt":       false,
+		"print.printLabel":         false,
+		"print.listLabelTemplates": false,
+		"print.renderAndPrint":     false,
+		"print.renderPreview":      false,
+		"print.retryJob":           false,
+		"print.purgeSpool":         false,
+		"print.getEvents":          false,
+		"print.queryAudit":         false,
 	}
 	
 	for _, cmdName := range cmdNames {
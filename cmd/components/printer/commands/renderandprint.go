@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"cse-go/cmd/components/printer/audit"
+	"cse-go/cmd/components/printer/backend"
+	"cse-go/cmd/components/printer/document"
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// renderParamsSchema 是渲染类命令共用的参数定义
+const renderParamsSchema = `"template": {"type": "string", "description": "Go text/template 语法的模板"}, ` +
+	`"format": {"type": "string", "enum": ["markup", "text"], "default": "markup", "description": "markup 支持 h1-h3、p、div、center、br、hr、b、ul、ol、table 等标记; text 按行原样输出"}, ` +
+	`"data": {"description": "模板数据"}, ` +
+	`"title": {"type": "string", "description": "PDF 文档标题"}, ` +
+	`"page": {"type": "object", "properties": {` +
+	`"size": {"type": "string", "enum": ["A4", "A5", "A6", "B5", "Letter", "Legal"], "default": "A4"}, ` +
+	`"widthMm": {"type": "number"}, "heightMm": {"type": "number"}, "landscape": {"type": "boolean"}, ` +
+	`"marginMm": {"type": "number", "default": 15}, "fontSize": {"type": "number", "default": 10.5}}}`
+
+// renderRequest 解析渲染参数并生成 PDF，失败时返回可直接作为命令结果的 failure
+func renderRequest(req *document.Request) (*document.Result, *pb.CommandResult) {
+	if services.Renderer == nil {
+		result, _ := failure("文档渲染服务未初始化", nil)
+		return nil, result
+	}
+	rendered, err := services.Renderer.Render(req)
+	if err != nil {
+		result, _ := failure("文档渲染失败: "+err.Error(), nil)
+		return nil, result
+	}
+	return rendered, nil
+}
+
+// 确保 RenderAndPrintCmd 实现了 commandbus.ContextCommand 接口
+var _ commandbus.ContextCommand = (*RenderAndPrintCmd)(nil)
+
+// RenderAndPrintCmd 将模板与数据渲染为 PDF 并提交打印
+type RenderAndPrintCmd struct{}
+
+// Name 返回命令名称
+func (c *RenderAndPrintCmd) Name() string {
+	return "print.renderAndPrint"
+}
+
+// GetInfo 返回命令元数据
+func (c *RenderAndPrintCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName: c.Name(),
+		Description: "使用模板与数据渲染 PDF 文档 (如送货单、报表) 并发送到打印机。Windows 下打印机需配置为支持 PDF 直接打印 (pdfPrinters)。",
+		ParametersSchema: `{"type": "object", "properties": {` +
+			`"printerName": {"type": "string", "description": "打印机名称或逻辑打印机名称"}, ` +
+			`"documentName": {"type": "string", "description": "打印队列中显示的文档名，默认为标题"}, ` +
+			renderParamsSchema + `}, "required": ["printerName", "template"]}`,
+		ResultSchema: `{"type": "object", "properties": {"success": {"type": "boolean"}, "printer": {"type": "string", "description": "实际使用的打印机"}, "jobId": {"type": "string"}, "pages": {"type": "integer"}, "bytes": {"type": "integer"}, "message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 在没有调用上下文时执行命令
+func (c *RenderAndPrintCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	return c.ExecuteContext(context.Background(), params)
+}
+
+// ExecuteContext 执行命令并记录审计日志
+func (c *RenderAndPrintCmd) ExecuteContext(ctx context.Context, params *pb.CommandParams) (*pb.CommandResult, error) {
+	return auditedExecute(ctx, c.Name(), params, c.execute)
+}
+
+// execute 渲染文档并提交打印
+func (c *RenderAndPrintCmd) execute(params *pb.CommandParams, entry *audit.Entry) (*pb.CommandResult, error) {
+	var requestParams struct {
+		document.Request
+		PrinterName  string `json:"printerName"`
+		DocumentName string `json:"documentName"`
+	}
+	if err := decodeParams(params, &requestParams); err != nil {
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+	if requestParams.PrinterName == "" {
+		return failure("打印机名称不能为空", nil)
+	}
+	documentName := requestParams.DocumentName
+	if documentName == "" {
+		documentName = requestParams.Title
+	}
+	if documentName == "" {
+		documentName = "文档"
+	}
+	entry.RequestedPrinter = requestParams.PrinterName
+	entry.Document = documentName
+
+	rendered, result := renderRequest(&requestParams.Request)
+	if result != nil {
+		return result, nil
+	}
+
+	printerName, err := resolvePrinter(requestParams.PrinterName)
+	if err != nil {
+		return failure(err.Error(), nil)
+	}
+	entry.Printer = printerName
+
+	entry.Pages = rendered.Pages
+	entry.SetContent(rendered.PDF)
+	job, err := services.Jobs.Submit(&backend.Document{
+		Printer:  printerName,
+		Name:     documentName,
+		DataType: backend.DataTypePDF,
+		Data:     rendered.PDF,
+	})
+	if err != nil {
+		log.Printf("发送文档失败: %v", err)
+		return failure(fmt.Sprintf("发送文档到打印机 '%s' 失败: %v", printerName, err),
+			map[string]interface{}{"printer": printerName, "jobId": job.ID, "pages": rendered.Pages, "bytes": len(rendered.PDF)})
+	}
+
+	log.Printf("成功发送文档到打印机: %s (作业 %s, %d 页)", printerName, job.ID, rendered.Pages)
+	return jsonResult(map[string]interface{}{
+		"success": true,
+		"printer": printerName,
+		"jobId":   job.ID,
+		"pages":   rendered.Pages,
+		"bytes":   len(rendered.PDF),
+		"message": submitMessage(job, fmt.Sprintf("文档已成功发送到打印机 '%s'", printerName)),
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&RenderAndPrintCmd{})
+}
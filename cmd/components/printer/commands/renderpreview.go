@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"encoding/base64"
+
+	"cse-go/cmd/components/printer/document"
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 RenderPreviewCmd 实现了 commandbus.Command 接口
+var _ commandbus.Command = (*RenderPreviewCmd)(nil)
+
+// RenderPreviewCmd 将模板与数据渲染为 PDF 并返回文件内容，用于打印前预览
+type RenderPreviewCmd struct{}
+
+// Name 返回命令名称
+func (c *RenderPreviewCmd) Name() string {
+	return "print.renderPreview"
+}
+
+// GetInfo 返回命令元数据
+func (c *RenderPreviewCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName:      c.Name(),
+		Description:      "使用模板与数据渲染 PDF 文档并以 base64 返回，不进行打印。",
+		ParametersSchema: `{"type": "object", "properties": {` + renderParamsSchema + `}, "required": ["template"]}`,
+		ResultSchema:     `{"type": "object", "properties": {"success": {"type": "boolean"}, "pdf": {"type": "string", "description": "base64 编码的 PDF"}, "pages": {"type": "integer"}, "bytes": {"type": "integer"}, "message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 渲染文档并返回 PDF
+func (c *RenderPreviewCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	var requestParams document.Request
+	if err := decodeParams(params, &requestParams); err != nil {
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+
+	rendered, result := renderRequest(&requestParams)
+	if result != nil {
+		return result, nil
+	}
+	return jsonResult(map[string]interface{}{
+		"success": true,
+		"pdf":     base64.StdEncoding.EncodeToString(rendered.PDF),
+		"pages":   rendered.Pages,
+		"bytes":   len(rendered.PDF),
+		"message": "文档渲染成功",
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&RenderPreviewCmd{})
+}
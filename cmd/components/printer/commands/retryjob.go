@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"log"
+
+	"cse-go/cmd/components/printer/backend"
+	"cse-go/internal/commandbus"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 确保 RetryJobCmd 实现了 commandbus.Command 接口
+var _ commandbus.Command = (*RetryJobCmd)(nil)
+
+// RetryJobCmd 使用假脱机目录中保存的文档内容重新提交失败或等待重试的作业
+type RetryJobCmd struct{}
+
+// Name 返回命令名称
+func (c *RetryJobCmd) Name() string {
+	return "print.retryJob"
+}
+
+// GetInfo 返回命令元数据
+func (c *RetryJobCmd) GetInfo() *pb.CommandInfo {
+	return &pb.CommandInfo{
+		CommandName:      c.Name(),
+		Description:      "立即重新提交失败或等待重试的作业，并开始新的重试窗口。作业的文档内容必须仍保存在假脱机目录中。",
+		ParametersSchema: `{"type": "object", "properties": {"jobId": {"type": "string", "description": "作业 ID"}}, "required": ["jobId"]}`,
+		ResultSchema:     `{"type": "object", "properties": {"success": {"type": "boolean"}, "job": {"type": "object"}, "message": {"type": "string"}}}`,
+	}
+}
+
+// Execute 重新提交打印作业
+func (c *RetryJobCmd) Execute(params *pb.CommandParams) (*pb.CommandResult, error) {
+	var requestParams struct {
+		JobID string `json:"jobId"`
+	}
+	if err := decodeParams(params, &requestParams); err != nil {
+		return failure("参数解析失败: "+err.Error(), nil)
+	}
+	if requestParams.JobID == "" {
+		return failure("作业 ID 不能为空", nil)
+	}
+
+	job, err := services.Jobs.Retry(requestParams.JobID)
+	if job == nil {
+		log.Printf("重试作业 '%s' 失败: %v", requestParams.JobID, err)
+		return failure(err.Error(), nil)
+	}
+	if err != nil && job.State == backend.JobFailed {
+		log.Printf("重试作业 '%s' 失败: %v", job.ID, err)
+		return failure(err.Error(), map[string]interface{}{"job": job})
+	}
+
+	log.Printf("作业 '%s' 已重新提交，当前状态: %s", job.ID, job.State)
+	return jsonResult(map[string]interface{}{
+		"success": true,
+		"job":     job,
+		"message": submitMessage(job, "作业已重新提交"),
+	})
+}
+
+// init 自动注册命令
+func init() {
+	GlobalRegistry.Register(&RetryJobCmd{})
+}
@@ -0,0 +1,167 @@
+// Package commands 包含了打印组件特定的命令实现
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"cse-go/cmd/components/printer/audit"
+	"cse-go/cmd/components/printer/backend"
+	"cse-go/cmd/components/printer/document"
+	"cse-go/cmd/components/printer/jobs"
+	"cse-go/cmd/components/printer/labels"
+	"cse-go/cmd/components/printer/monitor"
+	"cse-go/cmd/components/printer/policy"
+	"cse-go/cmd/components/printer/pools"
+	"cse-go/internal/commandbus"
+	"cse-go/internal/tracing"
+	pb "cse-go/pkg/api/v1"
+)
+
+// Services 汇集了命令执行时依赖的共享服务，由组件在启动时注入
+type Services struct {
+	Backend  backend.Backend
+	Jobs     *jobs.Manager
+	Labels   *labels.Store
+	Renderer *document.Renderer
+	Printers *pools.Resolver
+	Monitor  *monitor.Monitor
+	Audit    *audit.Log
+	Policy   *policy.Policy
+}
+
+var services = &Services{}
+
+// SetServices 注入命令所需的共享服务，必须在处理任何命令之前调用
+func SetServices(s *Services) {
+	services = s
+}
+
+// resolvePrinter 将 printerName 参数解析为物理打印机，逻辑打印机按其策略选择一台可用的成员
+func resolvePrinter(name string) (string, error) {
+	if services.Printers == nil {
+		return name, nil
+	}
+	return services.Printers.Resolve(name)
+}
+
+// submitMessage 返回提交作业后的提示信息，提交失败但已转入自动重试的作业给出重试说明
+func submitMessage(job *jobs.Job, sent string) string {
+	if job.State == backend.JobRetrying {
+		return fmt.Sprintf("打印机 '%s' 暂时无法接收作业，作业 '%s' 已保存并将自动重试: %s", job.Printer, job.ID, job.LastError)
+	}
+	return sent
+}
+
+// auditedCommands 是经由 auditedExecute 执行、产生打印输出的命令，被访问策略拒绝的调用同样写入审计日志
+var auditedCommands = map[string]bool{
+	"print.testPrint":      true,
+	"print.printReceipt":   true,
+	"print.printLabel":     true,
+	"print.renderAndPrint": true,
+}
+
+// autoRetryCommand 是自动重试记录的命令名称，记录的 JobID 与提交作业的原始记录相同
+const autoRetryCommand = "print.autoRetry"
+
+// auditedExecute 执行产生打印输出的命令，并将调用方、打印机、内容摘要、结果与耗时写入审计日志。
+// execute 负责填写 entry 中与命令相关的字段，结果与作业 ID 从命令返回值中读取
+func auditedExecute(ctx context.Context, command string, params *pb.CommandParams,
+	execute func(*pb.CommandParams, *audit.Entry) (*pb.CommandResult, error)) (*pb.CommandResult, error) {
+	start := time.Now()
+	entry := &audit.Entry{Time: start, Command: command, Caller: commandbus.CallerFromContext(ctx), RequestID: tracing.RequestID(ctx)}
+	result, err := execute(params, entry)
+	entry.DurationMs = time.Since(start).Milliseconds()
+
+	if err != nil {
+		entry.Message = err.Error()
+	} else {
+		var outcome struct {
+			Success bool   `json:"success"`
+			Message string `json:"message"`
+			JobID   string `json:"jobId"`
+		}
+		if json.Unmarshal([]byte(result.GetJsonPayload()), &outcome) == nil {
+			entry.Success = outcome.Success
+			entry.Message = outcome.Message
+			entry.JobID = outcome.JobID
+		}
+	}
+	entry.Result = audit.ResultFailed
+	if entry.Success {
+		entry.Result = audit.ResultSuccess
+	}
+
+	recordAudit(entry)
+	return result, err
+}
+
+// AuditRetry 将作业管理器自动重试的结果写入审计日志，作为 jobs.Options.OnRetry 使用
+func AuditRetry(job *jobs.Job, err error) {
+	entry := &audit.Entry{
+		Time:     time.Now(),
+		Command:  autoRetryCommand,
+		Printer:  job.Printer,
+		Document: job.Document,
+		JobID:    job.ID,
+		Bytes:    job.Size,
+		Attempt:  job.Attempts,
+		Message:  job.Message,
+	}
+	switch {
+	case job.State == backend.JobRetrying:
+		entry.Result = audit.ResultRetrying
+		entry.Message = job.LastError
+	case err != nil || job.State == backend.JobFailed:
+		entry.Result = audit.ResultFailed
+	default:
+		entry.Success = true
+		entry.Result = audit.ResultSuccess
+	}
+	recordAudit(entry)
+}
+
+// recordAudit 写入一条审计记录，未启用审计日志时忽略
+func recordAudit(entry *audit.Entry) {
+	if services.Audit == nil {
+		return
+	}
+	if err := services.Audit.Record(entry); err != nil {
+		log.Printf("[Audit] 写入审计记录失败: %v", err)
+	}
+}
+
+// jsonResult 将结果对象序列化为命令返回值
+func jsonResult(v any) (*pb.CommandResult, error) {
+	resultJson, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CommandResult{
+		JsonPayload: string(resultJson),
+	}, nil
+}
+
+// failure 构造一个 success 为 false 的标准结果，extra 中的字段会合并到结果中
+func failure(message string, extra map[string]interface{}) (*pb.CommandResult, error) {
+	result := map[string]interface{}{
+		"success": false,
+		"message": message,
+	}
+	for k, v := range extra {
+		result[k] = v
+	}
+	return jsonResult(result)
+}
+
+// decodeParams 解析命令参数，空参数视为空对象
+func decodeParams(params *pb.CommandParams, v any) error {
+	payload := params.GetJsonPayload()
+	if payload == "" || payload == "null" {
+		return nil
+	}
+	return json.Unmarshal([]byte(payload), v)
+}
@@ -0,0 +1,128 @@
+// Package config 读取打印组件自身的配置文件
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"cse-go/cmd/components/printer/backend"
+	"cse-go/cmd/components/printer/pools"
+)
+
+// Config 是打印组件的配置
+type Config struct {
+	// Printers 定义逻辑打印机，键为逻辑名称，值为成员定义或单台打印机的名称 (别名)
+	Printers map[string]*pools.Pool `json:"printers,omitempty"`
+	// PDFPrinters 为支持 PDF 直接打印的打印机。Windows 后端只向这些打印机提交 PDF 文档，
+	// 其他打印机的 PDF 作业直接失败；CUPS 后端会转换 PDF，不需要配置
+	PDFPrinters []string `json:"pdfPrinters,omitempty"`
+	// Spool 定义假脱机与提交失败后的重试策略
+	Spool Spool `json:"spool"`
+	// Monitor 定义打印机监视器的配置
+	Monitor Monitor `json:"monitor"`
+	// Audit 定义审计日志的轮转策略
+	Audit Audit `json:"audit"`
+}
+
+// Settings 是 Supervisor 推送的组件设置 (组件配置中的 settings)，覆盖命令行参数与配置文件中的对应项
+type Settings struct {
+	// Backend 为打印后端，为空时使用 --backend 参数。只在组件启动后首次收到设置时生效
+	Backend string `json:"backend,omitempty"`
+	// Printers 不为 nil 时替换配置文件中的逻辑打印机定义，重新加载设置时立即生效
+	Printers map[string]*pools.Pool `json:"printers,omitempty"`
+}
+
+// Validate 校验设置
+func (s *Settings) Validate() error {
+	if s.Backend != "" && !slices.Contains(backend.Names(), s.Backend) {
+		return fmt.Errorf("未知的打印后端 '%s' (可用: %v)", s.Backend, backend.Names())
+	}
+	return pools.Validate(s.Printers)
+}
+
+// Audit 是审计日志配置
+type Audit struct {
+	// MaxSizeMB 为单个日志文件的大小上限 (MB)，超过后轮转
+	MaxSizeMB int `json:"maxSizeMB"`
+	// MaxFiles 为保留的日志文件数量
+	MaxFiles int `json:"maxFiles"`
+}
+
+// Monitor 是打印机监视器配置
+type Monitor struct {
+	// Interval 为采集打印机列表与状态的间隔
+	Interval Duration `json:"interval"`
+}
+
+// Spool 是假脱机与重试配置
+type Spool struct {
+	// RetryWindow 为提交失败后持续重试的时长，为 0 时不重试
+	RetryWindow Duration `json:"retryWindow"`
+	// InitialBackoff 为首次重试前的等待时间，之后每次加倍
+	InitialBackoff Duration `json:"initialBackoff"`
+	// MaxBackoff 为两次重试之间的最长等待时间
+	MaxBackoff Duration `json:"maxBackoff"`
+}
+
+// Duration 是以 "10m"、"30s" 等字符串表示的时长
+type Duration time.Duration
+
+// UnmarshalJSON 解析时长字符串
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("时长必须是字符串，例如 \"10m\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if v < 0 {
+		return fmt.Errorf("时长不能为负数: %s", s)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON 将时长输出为字符串
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// defaults 返回未配置时使用的默认值
+func defaults() *Config {
+	return &Config{
+		Spool: Spool{
+			RetryWindow:    Duration(10 * time.Minute),
+			InitialBackoff: Duration(2 * time.Second),
+			MaxBackoff:     Duration(time.Minute),
+		},
+		Monitor: Monitor{
+			Interval: Duration(5 * time.Second),
+		},
+		Audit: Audit{
+			MaxSizeMB: 10,
+			MaxFiles:  10,
+		},
+	}
+}
+
+// Load 读取配置文件，文件不存在时返回默认配置
+func Load(path string) (*Config, error) {
+	cfg := defaults()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取配置文件 '%s': %w", path, err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("无法解析配置文件 '%s': %w", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,46 @@
+package document
+
+import (
+	_ "embed"
+	"fmt"
+	"sync"
+)
+
+// Font 是渲染 PDF 时使用的字体，可被多个渲染过程共享
+type Font interface {
+	// Name 返回字体名称
+	Name() string
+	// face 为一次渲染创建字体实例，实例记录本次用到的字符
+	face() fontFace
+}
+
+// fontFace 是单次渲染中使用的字体实例
+type fontFace interface {
+	// advance 返回字符的宽度，单位为 1/1000 em
+	advance(r rune) float64
+	// encode 将文本编码为 PDF 十六进制字符串 (不含尖括号)，并记录用到的字符
+	encode(s string) string
+	// ascent、descent 返回字体的上升与下降高度，单位为 1/1000 em
+	ascent() float64
+	descent() float64
+	// writeTo 将字体对象写入 PDF，返回 Type0 字体对象的编号
+	writeTo(w *pdfWriter) (int, error)
+}
+
+//go:embed fonts/wqy-microhei-gb2312.ttf
+var defaultFontData []byte
+
+// defaultFont 在首次使用时解析内置字体
+var defaultFont = sync.OnceValue(func() *TrueTypeFont {
+	f, err := ParseTrueTypeFont("WenQuanYi Micro Hei", defaultFontData)
+	if err != nil {
+		panic(fmt.Sprintf("无法解析内置字体: %v", err))
+	}
+	return f
+})
+
+// DefaultFont 返回内置的中文字体 (文泉驿微米黑的 GB2312 子集)，在未配置 TrueType 字体时使用。
+// 与配置的字体一样以子集形式嵌入 PDF，生成的文件不依赖阅读器或打印机安装中文字体
+func DefaultFont() Font {
+	return defaultFont()
+}
@@ -0,0 +1,268 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	lineSpacing = 1.4 // 行高相对字号的倍数
+	cellPadding = 3.0 // 表格单元格内边距 (pt)
+	ruleWidth   = 0.5 // 分隔线与表格边框的线宽 (pt)
+)
+
+// layout 将块排版到页面上，生成每页的内容流
+type layout struct {
+	face     fontFace
+	fontSize float64
+	width    float64
+	height   float64
+	margin   float64
+
+	pages []*bytes.Buffer
+	y     float64
+}
+
+// segment 是一行中样式相同的一段文本
+type segment struct {
+	text  string
+	bold  bool
+	width float64
+}
+
+// line 是排版后的一行
+type line struct {
+	segments []segment
+	width    float64
+}
+
+func (l *layout) contentWidth() float64 {
+	return l.width - 2*l.margin
+}
+
+func (l *layout) page() *bytes.Buffer {
+	if len(l.pages) == 0 {
+		l.newPage()
+	}
+	return l.pages[len(l.pages)-1]
+}
+
+func (l *layout) newPage() {
+	l.pages = append(l.pages, &bytes.Buffer{})
+	l.y = l.height - l.margin
+}
+
+// reserve 确保当前页剩余空间足够，否则换页。页面为空时不换页
+func (l *layout) reserve(h float64) {
+	if len(l.pages) == 0 {
+		l.newPage()
+		return
+	}
+	if l.y-h < l.margin && l.y < l.height-l.margin {
+		l.newPage()
+	}
+}
+
+func (l *layout) render(blocks []*block) {
+	for _, b := range blocks {
+		switch b.kind {
+		case blockParagraph:
+			l.paragraph(b)
+		case blockRule:
+			l.reserve(l.fontSize)
+			y := l.y - l.fontSize/2
+			fmt.Fprintf(l.page(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", ruleWidth, l.margin, y, l.width-l.margin, y)
+			l.y -= l.fontSize
+		case blockTable:
+			l.table(b)
+		case blockPageBreak:
+			l.newPage()
+		}
+		l.y -= b.gap * l.fontSize
+	}
+	if len(l.pages) == 0 {
+		l.newPage()
+	}
+}
+
+func (l *layout) paragraph(b *block) {
+	size := l.fontSize * b.scale
+	for _, ln := range l.wrap(b.runs, size, l.contentWidth()) {
+		lineHeight := size * lineSpacing
+		l.reserve(lineHeight)
+		l.drawLine(ln, size, l.margin, l.contentWidth(), l.y, b.align)
+		l.y -= lineHeight
+	}
+}
+
+func (l *layout) table(b *block) {
+	size := l.fontSize
+	lineHeight := size * lineSpacing
+	total := l.contentWidth()
+	for _, row := range b.rows {
+		cells := make([][]line, len(b.widths))
+		rowHeight := lineHeight
+		for i := range b.widths {
+			if i < len(row.cells) {
+				cells[i] = l.wrap(row.cells[i].runs, size, b.widths[i]*total-2*cellPadding)
+			}
+			rowHeight = max(rowHeight, float64(len(cells[i]))*lineHeight)
+		}
+		rowHeight += 2 * cellPadding
+		l.reserve(rowHeight)
+
+		x := l.margin
+		buf := l.page()
+		for i, w := range b.widths {
+			colWidth := w * total
+			if b.border {
+				fmt.Fprintf(buf, "%.2f w %.2f %.2f %.2f %.2f re S\n", ruleWidth, x, l.y-rowHeight, colWidth, rowHeight)
+			}
+			align := ""
+			if i < len(row.cells) {
+				align = row.cells[i].align
+			}
+			y := l.y - cellPadding
+			for _, ln := range cells[i] {
+				l.drawLine(ln, size, x+cellPadding, colWidth-2*cellPadding, y, align)
+				y -= lineHeight
+			}
+			x += colWidth
+		}
+		l.y -= rowHeight
+	}
+}
+
+// drawLine 在行框顶部为 top 的位置绘制一行文本
+func (l *layout) drawLine(ln line, size, x, width, top float64, align string) {
+	switch align {
+	case "center":
+		x += (width - ln.width) / 2
+	case "right":
+		x += width - ln.width
+	}
+	ascent, descent := l.face.ascent()/1000*size, l.face.descent()/1000*size
+	baseline := top - (size*lineSpacing-(ascent-descent))/2 - ascent
+
+	buf := l.page()
+	for _, seg := range ln.segments {
+		if seg.text == "" {
+			continue
+		}
+		if seg.bold {
+			// 以填充加描边模拟粗体
+			fmt.Fprintf(buf, "q %.2f w BT /F1 %.2f Tf 2 Tr %.2f %.2f Td <%s> Tj ET Q\n",
+				size*0.04, size, x, baseline, l.face.encode(seg.text))
+		} else {
+			fmt.Fprintf(buf, "BT /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, baseline, l.face.encode(seg.text))
+		}
+		x += seg.width
+	}
+}
+
+// wrap 按宽度折行。英文单词尽量不拆开，中日韩文字可在任意字符间断行
+func (l *layout) wrap(runs []run, size, width float64) []line {
+	var lines []line
+	var cur line
+	wrapped := false
+	flushLine := func() {
+		// 去掉行尾空格
+		for len(cur.segments) > 0 {
+			last := &cur.segments[len(cur.segments)-1]
+			trimmed := strings.TrimRight(last.text, " ")
+			cur.width -= last.width - l.textWidth(trimmed, size)
+			last.width = l.textWidth(trimmed, size)
+			last.text = trimmed
+			if trimmed != "" {
+				break
+			}
+			cur.segments = cur.segments[:len(cur.segments)-1]
+		}
+		lines = append(lines, cur)
+		cur = line{}
+	}
+	add := func(text string, bold bool) {
+		w := l.textWidth(text, size)
+		if n := len(cur.segments); n > 0 && cur.segments[n-1].bold == bold {
+			cur.segments[n-1].text += text
+			cur.segments[n-1].width += w
+		} else {
+			cur.segments = append(cur.segments, segment{text: text, bold: bold, width: w})
+		}
+		cur.width += w
+	}
+
+	for _, r := range runs {
+		for i, part := range strings.Split(r.text, "\n") {
+			if i > 0 {
+				flushLine()
+				wrapped = false
+			}
+			for _, word := range splitWords(part) {
+				// 自动折行产生的行不以空格开头
+				if word == " " && wrapped && len(cur.segments) == 0 {
+					continue
+				}
+				wrapped = false
+				w := l.textWidth(word, size)
+				if cur.width+w <= width {
+					add(word, r.bold)
+					continue
+				}
+				if word == " " {
+					continue
+				}
+				if cur.width > 0 {
+					flushLine()
+					wrapped = true
+				}
+				// 单个单词超过行宽时按字符拆分
+				for _, c := range word {
+					cw := l.face.advance(c) / 1000 * size
+					if cur.width+cw > width && cur.width > 0 {
+						flushLine()
+						wrapped = true
+					}
+					add(string(c), r.bold)
+				}
+			}
+		}
+	}
+	if len(cur.segments) > 0 || len(lines) == 0 {
+		flushLine()
+	}
+	return lines
+}
+
+func (l *layout) textWidth(s string, size float64) float64 {
+	w := 0.0
+	for _, r := range s {
+		w += l.face.advance(r)
+	}
+	return w / 1000 * size
+}
+
+// splitWords 将文本拆分为可断行的单元: 连续的 ASCII 非空白字符、单个空格或单个非 ASCII 字符
+func splitWords(s string) []string {
+	var words []string
+	start := -1
+	for i, r := range s {
+		if r < utf8.RuneSelf && r != ' ' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			words = append(words, s[start:i])
+			start = -1
+		}
+		words = append(words, string(r))
+	}
+	if start >= 0 {
+		words = append(words, s[start:])
+	}
+	return words
+}
@@ -0,0 +1,343 @@
+package document
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// 块的类型
+const (
+	blockParagraph = iota
+	blockRule
+	blockTable
+	blockPageBreak
+)
+
+// block 是排版的基本单元
+type block struct {
+	kind  int
+	runs  []run
+	align string
+	// scale 为相对正文字号的倍数
+	scale float64
+	// gap 为块后的间距 (相对正文字号)
+	gap float64
+
+	rows   []tableRow
+	widths []float64
+	border bool
+}
+
+// run 是一段样式相同的文本，文本中的 '\n' 表示强制换行
+type run struct {
+	text string
+	bold bool
+}
+
+type tableRow struct {
+	cells []tableCell
+}
+
+type tableCell struct {
+	runs  []run
+	align string
+}
+
+// headingScales 为标题相对正文的字号倍数
+var headingScales = map[atom.Atom]float64{
+	atom.H1: 1.8,
+	atom.H2: 1.4,
+	atom.H3: 1.2,
+}
+
+// parseMarkup 将类 HTML 标记解析为块。支持的元素:
+// h1-h3、p、div、center、br、hr (class="page-break" 表示分页)、b、strong、
+// ul、ol、li、table、tr、th、td (width 为百分比)，align 属性可用于块与单元格
+func parseMarkup(source string) ([]*block, error) {
+	doc, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return nil, fmt.Errorf("无法解析标记: %w", err)
+	}
+	p := &markupParser{}
+	p.walk(doc, false, "")
+	p.flush("", 1, false)
+	return p.blocks, nil
+}
+
+type markupParser struct {
+	blocks []*block
+	runs   []run
+}
+
+// flush 将已收集的行内文本输出为段落
+func (p *markupParser) flush(align string, scale float64, bold bool) {
+	if len(p.runs) == 0 {
+		return
+	}
+	runs := trimRuns(p.runs)
+	p.runs = nil
+	if len(runs) == 0 {
+		return
+	}
+	if bold {
+		for i := range runs {
+			runs[i].bold = true
+		}
+	}
+	p.blocks = append(p.blocks, &block{kind: blockParagraph, runs: runs, align: align, scale: scale, gap: 0.3})
+}
+
+func (p *markupParser) walk(n *html.Node, bold bool, align string) {
+	switch n.Type {
+	case html.TextNode:
+		p.runs = append(p.runs, run{text: collapseSpace(n.Data), bold: bold})
+		return
+	case html.ElementNode:
+	default:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			p.walk(c, bold, align)
+		}
+		return
+	}
+
+	if a := attr(n, "align"); a != "" {
+		align = strings.ToLower(a)
+	}
+	switch n.DataAtom {
+	case atom.Head, atom.Script, atom.Style:
+	case atom.B, atom.Strong:
+		p.walkChildren(n, true, align)
+	case atom.Br:
+		p.runs = append(p.runs, run{text: "\n", bold: bold})
+	case atom.Hr:
+		p.flush(align, 1, false)
+		if attr(n, "class") == "page-break" {
+			p.blocks = append(p.blocks, &block{kind: blockPageBreak})
+		} else {
+			p.blocks = append(p.blocks, &block{kind: blockRule, gap: 0.3})
+		}
+	case atom.H1, atom.H2, atom.H3:
+		p.flush(align, 1, false)
+		p.walkChildren(n, true, align)
+		p.flush(align, headingScales[n.DataAtom], true)
+	case atom.Center:
+		p.flush(align, 1, false)
+		p.walkChildren(n, bold, "center")
+		p.flush("center", 1, false)
+	case atom.Ul, atom.Ol:
+		p.flush(align, 1, false)
+		index := 0
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+				continue
+			}
+			index++
+			marker := "· "
+			if n.DataAtom == atom.Ol {
+				marker = strconv.Itoa(index) + ". "
+			}
+			p.runs = append(p.runs, run{text: marker, bold: bold})
+			p.walkChildren(c, bold, align)
+			p.flush(align, 1, false)
+		}
+	case atom.Table:
+		p.flush(align, 1, false)
+		p.blocks = append(p.blocks, parseTable(n))
+	case atom.P, atom.Div, atom.Li, atom.Body, atom.Html:
+		p.flush(align, 1, false)
+		p.walkChildren(n, bold, align)
+		p.flush(align, 1, false)
+	default:
+		p.walkChildren(n, bold, align)
+	}
+}
+
+func (p *markupParser) walkChildren(n *html.Node, bold bool, align string) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.walk(c, bold, align)
+	}
+}
+
+// parseTable 读取表格的行与单元格，列宽取自第一行单元格的 width 属性
+func parseTable(n *html.Node) *block {
+	b := &block{kind: blockTable, gap: 0.3}
+	if v, ok := attrValue(n, "border"); ok && v != "0" {
+		b.border = true
+	}
+
+	var collect func(*html.Node)
+	collect = func(node *html.Node) {
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				collect(c)
+			case atom.Tr:
+				b.rows = append(b.rows, parseRow(c))
+			}
+		}
+	}
+	collect(n)
+
+	columns := 0
+	for _, row := range b.rows {
+		columns = max(columns, len(row.cells))
+	}
+	if columns == 0 {
+		return b
+	}
+
+	// 未指定宽度的列平分剩余宽度
+	b.widths = make([]float64, columns)
+	specified, remaining := 0.0, columns
+	for i, c := range firstRowCells(n) {
+		if i >= columns {
+			break
+		}
+		if w, err := strconv.ParseFloat(strings.TrimSuffix(attr(c, "width"), "%"), 64); err == nil && w > 0 {
+			b.widths[i] = w / 100
+			specified += w / 100
+			remaining--
+		}
+	}
+	for i := range b.widths {
+		if b.widths[i] == 0 {
+			b.widths[i] = max(0, 1-specified) / float64(remaining)
+		}
+	}
+	return b
+}
+
+func parseRow(tr *html.Node) tableRow {
+	var row tableRow
+	for c := tr.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || (c.DataAtom != atom.Td && c.DataAtom != atom.Th) {
+			continue
+		}
+		p := &markupParser{}
+		p.walkChildren(c, c.DataAtom == atom.Th, "")
+		row.cells = append(row.cells, tableCell{runs: trimRuns(p.collectText()), align: strings.ToLower(attr(c, "align"))})
+	}
+	return row
+}
+
+// collectText 将单元格中的块与行内文本合并为一组以换行分隔的文本
+func (p *markupParser) collectText() []run {
+	var runs []run
+	for _, b := range p.blocks {
+		if b.kind != blockParagraph {
+			continue
+		}
+		runs = append(runs, b.runs...)
+		runs = append(runs, run{text: "\n"})
+	}
+	return append(runs, p.runs...)
+}
+
+func firstRowCells(table *html.Node) []*html.Node {
+	var cells []*html.Node
+	var find func(*html.Node) bool
+	find = func(n *html.Node) bool {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				if find(c) {
+					return true
+				}
+			case atom.Tr:
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+						cells = append(cells, cell)
+					}
+				}
+				return true
+			}
+		}
+		return false
+	}
+	find(table)
+	return cells
+}
+
+func attr(n *html.Node, key string) string {
+	v, _ := attrValue(n, key)
+	return v
+}
+
+func attrValue(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val), true
+		}
+	}
+	return "", false
+}
+
+// collapseSpace 将连续空白合并为一个空格
+func collapseSpace(s string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			space = true
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteRune(r)
+	}
+	if space {
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
+
+// trimRuns 去掉首尾空白与每行行首空格，全部为空白时返回 nil
+func trimRuns(runs []run) []run {
+	var out []run
+	lineStart := true
+	for _, r := range runs {
+		text := r.text
+		var sb strings.Builder
+		for _, c := range text {
+			if c == '\n' {
+				lineStart = true
+				// 去掉换行前的空格
+				trimmed := strings.TrimRight(sb.String(), " ")
+				sb.Reset()
+				sb.WriteString(trimmed)
+				sb.WriteRune(c)
+				continue
+			}
+			if c == ' ' && lineStart {
+				continue
+			}
+			lineStart = false
+			sb.WriteRune(c)
+		}
+		if sb.Len() > 0 {
+			out = append(out, run{text: sb.String(), bold: r.bold})
+		}
+	}
+	// 去掉末尾的空白与换行
+	for len(out) > 0 {
+		last := &out[len(out)-1]
+		last.text = strings.TrimRight(last.text, " \n")
+		if last.text != "" {
+			break
+		}
+		out = out[:len(out)-1]
+	}
+	return out
+}
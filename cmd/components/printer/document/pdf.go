@@ -0,0 +1,85 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+)
+
+// pdfWriter 以增量方式生成 PDF 文件，负责对象编号与交叉引用表
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func newPDFWriter() *pdfWriter {
+	w := &pdfWriter{}
+	// 二进制注释行提示传输工具按二进制处理文件
+	w.buf.WriteString("%PDF-1.7\n%\xE2\xE3\xCF\xD3\n")
+	return w
+}
+
+// reserve 预留一个对象编号，稍后通过 writeObject 写入
+func (w *pdfWriter) reserve() int {
+	w.offsets = append(w.offsets, -1)
+	return len(w.offsets)
+}
+
+// writeObject 写入一个字典或数组对象
+func (w *pdfWriter) writeObject(ref int, body string) {
+	w.offsets[ref-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", ref, body)
+}
+
+// addObject 分配编号并写入对象
+func (w *pdfWriter) addObject(body string) int {
+	ref := w.reserve()
+	w.writeObject(ref, body)
+	return ref
+}
+
+// addStream 写入一个 Flate 压缩的流对象，extra 为附加的字典条目
+func (w *pdfWriter) addStream(data []byte, extra string) int {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(data)
+	zw.Close()
+
+	ref := w.reserve()
+	w.offsets[ref-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< /Length %d /Filter /FlateDecode %s>>\nstream\n", ref, compressed.Len(), extra)
+	w.buf.Write(compressed.Bytes())
+	w.buf.WriteString("\nendstream\nendobj\n")
+	return ref
+}
+
+// finish 写入交叉引用表与文件尾，返回完整的 PDF 字节
+func (w *pdfWriter) finish(root, info int) []byte {
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.offsets)+1, root, info, xref)
+	return w.buf.Bytes()
+}
+
+// pdfString 将文本编码为 PDF 的 UTF-16BE 十六进制字符串，用于文档信息字典
+func pdfString(s string) string {
+	return "<FEFF" + utf16Hex(s) + ">"
+}
+
+// utf16Hex 返回文本的 UTF-16BE 十六进制表示
+func utf16Hex(s string) string {
+	var sb bytes.Buffer
+	for _, r := range s {
+		if r > 0xFFFF {
+			r -= 0x10000
+			fmt.Fprintf(&sb, "%04X%04X", 0xD800+(r>>10)&0x3FF, 0xDC00+r&0x3FF)
+			continue
+		}
+		fmt.Fprintf(&sb, "%04X", r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,205 @@
+// Package document 将模板与数据渲染为 PDF 文档，纯 Go 实现，不依赖外部程序
+package document
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// 模板格式
+const (
+	// FormatMarkup 为类 HTML 标记，数据中的值会被转义
+	FormatMarkup = "markup"
+	// FormatText 为纯文本，按行原样输出
+	FormatText = "text"
+)
+
+const (
+	mmToPt          = 72 / 25.4
+	defaultMarginMm = 15
+	defaultFontSize = 10.5
+)
+
+// pageSizes 为常用纸张尺寸 (mm)
+var pageSizes = map[string][2]float64{
+	"A4":     {210, 297},
+	"A5":     {148, 210},
+	"A6":     {105, 148},
+	"B5":     {176, 250},
+	"LETTER": {215.9, 279.4},
+	"LEGAL":  {215.9, 355.6},
+}
+
+// Page 描述页面设置
+type Page struct {
+	// Size 为纸张尺寸: A4 (默认)、A5、A6、B5、Letter、Legal，指定 WidthMm 与 HeightMm 时忽略
+	Size      string  `json:"size,omitempty"`
+	WidthMm   float64 `json:"widthMm,omitempty"`
+	HeightMm  float64 `json:"heightMm,omitempty"`
+	Landscape bool    `json:"landscape,omitempty"`
+	// MarginMm 为页边距，默认 15mm
+	MarginMm float64 `json:"marginMm,omitempty"`
+	// FontSize 为正文字号 (pt)，默认 10.5
+	FontSize float64 `json:"fontSize,omitempty"`
+}
+
+// Request 是一次渲染请求
+type Request struct {
+	// Template 为 Go text/template 语法的模板
+	Template string `json:"template"`
+	// Format 为模板执行结果的格式: markup (默认) 或 text
+	Format string `json:"format,omitempty"`
+	Data   any    `json:"data,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Page   Page   `json:"page,omitempty"`
+}
+
+// Result 是渲染结果
+type Result struct {
+	PDF   []byte
+	Pages int
+}
+
+// Renderer 使用指定字体渲染文档，可并发使用
+type Renderer struct {
+	font Font
+}
+
+// NewRenderer 创建渲染器，font 为 nil 时使用内置的中文字体
+func NewRenderer(font Font) *Renderer {
+	if font == nil {
+		font = DefaultFont()
+	}
+	return &Renderer{font: font}
+}
+
+// Font 返回渲染器使用的字体
+func (r *Renderer) Font() Font {
+	return r.font
+}
+
+// Render 执行模板并生成 PDF
+func (r *Renderer) Render(req *Request) (*Result, error) {
+	width, height, err := req.Page.size()
+	if err != nil {
+		return nil, err
+	}
+	margin := req.Page.MarginMm
+	if margin <= 0 {
+		margin = defaultMarginMm
+	}
+	margin *= mmToPt
+	if 2*margin >= width || 2*margin >= height {
+		return nil, fmt.Errorf("页边距过大")
+	}
+	fontSize := req.Page.FontSize
+	if fontSize <= 0 {
+		fontSize = defaultFontSize
+	}
+
+	source, err := executeTemplate(req)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []*block
+	switch req.format() {
+	case FormatMarkup:
+		if blocks, err = parseMarkup(source); err != nil {
+			return nil, err
+		}
+	case FormatText:
+		blocks = parseText(source)
+	}
+
+	face := r.font.face()
+	l := &layout{face: face, fontSize: fontSize, width: width, height: height, margin: margin}
+	l.render(blocks)
+
+	w := newPDFWriter()
+	catalog := w.reserve()
+	pagesRef := w.reserve()
+	fontRef, err := face.writeTo(w)
+	if err != nil {
+		return nil, err
+	}
+	kids := make([]string, len(l.pages))
+	for i, content := range l.pages {
+		contentRef := w.addStream(content.Bytes(), "")
+		kids[i] = fmt.Sprintf("%d 0 R", w.addObject(fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesRef, width, height, fontRef, contentRef)))
+	}
+	w.writeObject(pagesRef, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	w.writeObject(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesRef))
+	info := w.addObject(fmt.Sprintf("<< /Producer (cse-go) /Title %s /CreationDate (D:%s) >>",
+		pdfString(req.Title), time.Now().UTC().Format("20060102150405Z")))
+
+	return &Result{PDF: w.finish(catalog, info), Pages: len(l.pages)}, nil
+}
+
+func (req *Request) format() string {
+	if req.Format == "" {
+		return FormatMarkup
+	}
+	return req.Format
+}
+
+// executeTemplate 执行模板。markup 格式使用 html/template 转义数据，避免数据中的标签改变版式
+func executeTemplate(req *Request) (string, error) {
+	if strings.TrimSpace(req.Template) == "" {
+		return "", fmt.Errorf("模板不能为空")
+	}
+	var buf bytes.Buffer
+	switch req.format() {
+	case FormatMarkup:
+		t, err := htmltemplate.New("document").Option("missingkey=error").Parse(req.Template)
+		if err != nil {
+			return "", fmt.Errorf("模板语法错误: %w", err)
+		}
+		if err := t.Execute(&buf, req.Data); err != nil {
+			return "", fmt.Errorf("模板执行失败: %w", err)
+		}
+	case FormatText:
+		t, err := texttemplate.New("document").Option("missingkey=error").Parse(req.Template)
+		if err != nil {
+			return "", fmt.Errorf("模板语法错误: %w", err)
+		}
+		if err := t.Execute(&buf, req.Data); err != nil {
+			return "", fmt.Errorf("模板执行失败: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("不支持的模板格式 '%s'", req.Format)
+	}
+	return buf.String(), nil
+}
+
+// parseText 将纯文本作为一个段落，保留空格与换行
+func parseText(source string) []*block {
+	source = strings.ReplaceAll(strings.ReplaceAll(source, "\r\n", "\n"), "\t", "    ")
+	source = strings.TrimRight(source, "\n")
+	return []*block{{kind: blockParagraph, runs: []run{{text: source}}, scale: 1}}
+}
+
+// size 返回页面宽高 (pt)
+func (p *Page) size() (float64, float64, error) {
+	w, h := p.WidthMm, p.HeightMm
+	if w <= 0 || h <= 0 {
+		name := strings.ToUpper(p.Size)
+		if name == "" {
+			name = "A4"
+		}
+		s, ok := pageSizes[name]
+		if !ok {
+			return 0, 0, fmt.Errorf("不支持的纸张尺寸 '%s'", p.Size)
+		}
+		w, h = s[0], s[1]
+	}
+	if p.Landscape {
+		w, h = h, w
+	}
+	return w * mmToPt, h * mmToPt, nil
+}
@@ -0,0 +1,291 @@
+package document
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// checkPDF 验证文件头与交叉引用表中每个对象的偏移量
+func checkPDF(t *testing.T, pdf []byte) {
+	t.Helper()
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.7\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("PDF 文件头或文件尾错误")
+	}
+	m := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatalf("缺少 startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref 指向错误的位置")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(string(e[1]))
+		if !bytes.HasPrefix(pdf[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))) {
+			t.Fatalf("对象 %d 的偏移量错误", i+1)
+		}
+	}
+}
+
+func TestRenderMarkup(t *testing.T) {
+	r := NewRenderer(nil)
+	result, err := r.Render(&Request{
+		Template: `<h1 align="center">{{.title}}</h1>
+<table border="1">
+  <tr><th width="70%">品名</th><th align="right">数量</th></tr>
+  {{range .items}}<tr><td>{{.name}}</td><td align="right">{{.qty}}</td></tr>{{end}}
+</table>
+<hr><p>备注: <b>{{.note}}</b></p>`,
+		Data: map[string]any{
+			"title": "送货单",
+			"items": []any{map[string]any{"name": "打印纸", "qty": 10}},
+			"note":  "易碎",
+		},
+		Title: "送货单",
+	})
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	checkPDF(t, result.PDF)
+	if result.Pages != 1 {
+		t.Errorf("期望 1 页，实际 %d 页", result.Pages)
+	}
+	if !regexp.MustCompile(`/BaseFont /[A-Z]{6}\+WenQuanYiMicroHei /Encoding /Identity-H`).Match(result.PDF) || !bytes.Contains(result.PDF, []byte("/FontFile2")) {
+		t.Errorf("未嵌入内置中文字体")
+	}
+	for _, r := range "送货单品名数量打印纸备注易碎¥“”：，。" {
+		if defaultFont().cmap[r] == 0 {
+			t.Errorf("内置字体缺少字符 %q", r)
+		}
+	}
+}
+
+func TestRenderPagination(t *testing.T) {
+	r := NewRenderer(nil)
+	result, err := r.Render(&Request{
+		Template: `{{range .}}第 {{.}} 行
+{{end}}`,
+		Format: FormatText,
+		Data:   make([]int, 120),
+		Page:   Page{Size: "A6"},
+	})
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	checkPDF(t, result.PDF)
+	if result.Pages < 2 {
+		t.Errorf("内容超过一页时应分页，实际 %d 页", result.Pages)
+	}
+
+	result, err = r.Render(&Request{Template: `<p>一</p><hr class="page-break"><p>二</p>`})
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	if result.Pages != 2 {
+		t.Errorf("分页符后应有 2 页，实际 %d 页", result.Pages)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	r := NewRenderer(nil)
+	cases := []*Request{
+		{Template: ""},
+		{Template: "{{.missing}}", Data: map[string]any{}},
+		{Template: "{{.a", Data: map[string]any{}},
+		{Template: "x", Format: "docx"},
+		{Template: "x", Page: Page{Size: "A0"}},
+		{Template: "x", Page: Page{Size: "A6", MarginMm: 60}},
+	}
+	for i, req := range cases {
+		if _, err := r.Render(req); err == nil {
+			t.Errorf("用例 %d: 期望返回错误", i)
+		}
+	}
+}
+
+func TestMarkupEscapesData(t *testing.T) {
+	source, err := executeTemplate(&Request{Template: "<p>{{.v}}</p>", Data: map[string]any{"v": "<b>x</b>"}})
+	if err != nil {
+		t.Fatalf("模板执行失败: %v", err)
+	}
+	blocks, err := parseMarkup(source)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(blocks) != 1 || len(blocks[0].runs) != 1 {
+		t.Fatalf("期望 1 个段落 1 段文本，实际 %+v", blocks)
+	}
+	if run := blocks[0].runs[0]; run.text != "<b>x</b>" || run.bold {
+		t.Errorf("数据中的标签应按文本输出，实际 %+v", run)
+	}
+}
+
+func TestParseMarkup(t *testing.T) {
+	blocks, err := parseMarkup(`<h2>标题</h2><p>a <b>b</b><br>c</p><ol><li>x</li><li>y</li></ol>
+<table><tr><td width="25%">1</td><td>2</td><td>3</td></tr></table>`)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(blocks) != 5 {
+		t.Fatalf("期望 5 个块，实际 %d", len(blocks))
+	}
+	if b := blocks[0]; b.scale != 1.4 || !b.runs[0].bold {
+		t.Errorf("标题应加粗放大: %+v", b)
+	}
+	if got := blocks[1].runs; len(got) != 4 || got[0].text != "a " || !got[1].bold || got[2].text != "\n" || got[3].text != "c" {
+		t.Errorf("段落解析错误: %+v", got)
+	}
+	if got := blocks[3].runs[0].text; got != "2. " {
+		t.Errorf("有序列表编号错误: %q", got)
+	}
+	table := blocks[4]
+	if len(table.widths) != 3 || table.widths[0] != 0.25 || table.widths[1] != 0.375 || table.widths[2] != 0.375 {
+		t.Errorf("列宽计算错误: %v", table.widths)
+	}
+}
+
+// fixedFace 是 ASCII 字符宽 500、其余字符宽 1000 的测试字体
+type fixedFace struct{}
+
+func (fixedFace) advance(r rune) float64 {
+	if r >= 0x20 && r <= 0x7E {
+		return 500
+	}
+	return 1000
+}
+
+func (fixedFace) encode(s string) string            { return "" }
+func (fixedFace) ascent() float64                   { return 880 }
+func (fixedFace) descent() float64                  { return -120 }
+func (fixedFace) writeTo(w *pdfWriter) (int, error) { return 0, nil }
+
+func TestWrap(t *testing.T) {
+	l := &layout{face: fixedFace{}}
+	// 字号 10 时半角字符宽 5，全角字符宽 10
+	lines := l.wrap([]run{{text: "hello world 中文折行"}}, 10, 50)
+	var got []string
+	for _, ln := range lines {
+		var sb strings.Builder
+		for _, seg := range ln.segments {
+			sb.WriteString(seg.text)
+		}
+		got = append(got, sb.String())
+	}
+	want := []string{"hello", "world 中文", "折行"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("折行结果 %q，期望 %q", got, want)
+	}
+
+	lines = l.wrap([]run{{text: "abcdefghijklmn"}}, 10, 30)
+	if len(lines) != 3 {
+		t.Errorf("超长单词应按字符拆分，实际 %d 行", len(lines))
+	}
+}
+
+// buildTestFont 生成一个包含 4 个字形的 TrueType 字体:
+// 0 为 .notdef，1 映射 'A'，2 为引用字形 1 的复合字形并映射 '中'，3 映射 'z'
+func buildTestFont() []byte {
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint16(head[18:], 1000)
+	binary.BigEndian.PutUint16(head[40:], 1000)
+	binary.BigEndian.PutUint16(head[42:], 800)
+
+	hhea := make([]byte, 36)
+	binary.BigEndian.PutUint16(hhea[4:], 800)
+	binary.BigEndian.PutUint16(hhea[6:], 0xFF38) // -200
+	binary.BigEndian.PutUint16(hhea[34:], 4)
+
+	maxp := make([]byte, 6)
+	binary.BigEndian.PutUint16(maxp[4:], 4)
+
+	hmtx := make([]byte, 16)
+	for i, advance := range []uint16{500, 600, 1000, 700} {
+		binary.BigEndian.PutUint16(hmtx[4*i:], advance)
+	}
+
+	simple := append([]byte{0, 1}, make([]byte, 18)...)
+	composite := []byte{0xFF, 0xFF, 0, 0, 0, 0, 0, 0, 0, 0, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00}
+	glyphs := [][]byte{nil, simple, composite, simple}
+	var glyf []byte
+	loca := make([]byte, 2*(len(glyphs)+1))
+	for i, g := range glyphs {
+		binary.BigEndian.PutUint16(loca[2*i:], uint16(len(glyf)/2))
+		glyf = append(glyf, g...)
+	}
+	binary.BigEndian.PutUint16(loca[2*len(glyphs):], uint16(len(glyf)/2))
+
+	return buildSfnt(map[string][]byte{
+		"head": head,
+		"hhea": hhea,
+		"maxp": maxp,
+		"hmtx": hmtx,
+		"cmap": buildCmap(map[uint16]rune{1: 'A', 2: '中', 3: 'z'}),
+		"loca": loca,
+		"glyf": glyf,
+	})
+}
+
+func TestTrueTypeSubset(t *testing.T) {
+	font, err := ParseTrueTypeFont("Test Font", buildTestFont())
+	if err != nil {
+		t.Fatalf("解析字体失败: %v", err)
+	}
+	if font.Name() != "TestFont" {
+		t.Errorf("字体名称应去掉空格，实际 %q", font.Name())
+	}
+	face := font.face()
+	if got := face.advance('中'); got != 1000 {
+		t.Errorf("'中' 的宽度应为 1000，实际 %v", got)
+	}
+	if got := face.encode("中"); got != "0002" {
+		t.Errorf("'中' 应编码为字形 2，实际 %s", got)
+	}
+
+	data, err := font.subset(face.(*trueTypeFace).used)
+	if err != nil {
+		t.Fatalf("生成子集失败: %v", err)
+	}
+	sub, err := ParseTrueTypeFont("subset", data)
+	if err != nil {
+		t.Fatalf("解析子集失败: %v", err)
+	}
+	if sub.numGlyphs != 4 || sub.cmap['中'] != 2 || sub.cmap['A'] != 0 {
+		t.Errorf("子集字形数量或字符映射错误: %d %v", sub.numGlyphs, sub.cmap)
+	}
+	for gid, kept := range []bool{false, true, true, false} {
+		r, err := sub.glyphRange(uint16(gid))
+		if err != nil {
+			t.Fatalf("读取字形 %d 失败: %v", gid, err)
+		}
+		if (r[1] > r[0]) != kept {
+			t.Errorf("字形 %d 是否保留: %v，期望 %v", gid, r[1] > r[0], kept)
+		}
+	}
+
+	result, err := NewRenderer(font).Render(&Request{Template: "A中z", Format: FormatText})
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	checkPDF(t, result.PDF)
+	if !regexp.MustCompile(`/BaseFont /[A-Z]{6}\+TestFont /Encoding /Identity-H`).Match(result.PDF) {
+		t.Errorf("应嵌入字体子集")
+	}
+}
+
+func TestParseTrueTypeFontRejectsUnsupported(t *testing.T) {
+	for _, data := range [][]byte{
+		[]byte("OTTO\x00\x00\x00\x00\x00\x00\x00\x00"),
+		[]byte("ttcf\x00\x00\x00\x00\x00\x00\x00\x00"),
+		[]byte("short"),
+	} {
+		if _, err := ParseTrueTypeFont("x", data); err == nil {
+			t.Errorf("%q 应返回错误", data[:4])
+		}
+	}
+}
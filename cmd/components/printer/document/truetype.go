@@ -0,0 +1,568 @@
+package document
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TrueTypeFont 是从 .ttf 文件加载的字体，渲染时以子集形式嵌入 PDF，
+// 因此生成的文件不依赖阅读器或打印机安装中文字体
+type TrueTypeFont struct {
+	name       string
+	tables     map[string][]byte
+	unitsPerEm float64
+	bbox       [4]int16
+	asc, desc  int16
+	numGlyphs  int
+	advances   []uint16
+	cmap       map[rune]uint16
+	longLoca   bool
+}
+
+// LoadTrueTypeFont 读取 TrueType 字体文件，仅支持 glyf 轮廓 (不支持 CFF 与 .ttc 字体集合)
+func LoadTrueTypeFont(path string) (*TrueTypeFont, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取字体文件 '%s': %w", path, err)
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	f, err := ParseTrueTypeFont(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析字体文件 '%s': %w", path, err)
+	}
+	return f, nil
+}
+
+// ParseTrueTypeFont 从内存中解析 TrueType 字体
+func ParseTrueTypeFont(name string, data []byte) (*TrueTypeFont, error) {
+	if len(data) < 12 {
+		return nil, errors.New("文件过短")
+	}
+	switch binary.BigEndian.Uint32(data) {
+	case 0x00010000, 0x74727565: // 1.0 或 'true'
+	case 0x4F54544F: // 'OTTO'
+		return nil, errors.New("不支持 CFF 轮廓的 OpenType 字体")
+	case 0x74746366: // 'ttcf'
+		return nil, errors.New("不支持字体集合 (.ttc)")
+	default:
+		return nil, errors.New("不是 TrueType 字体")
+	}
+
+	f := &TrueTypeFont{name: sanitizeFontName(name), tables: make(map[string][]byte)}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*numTables {
+		return nil, errors.New("表目录不完整")
+	}
+	for i := 0; i < numTables; i++ {
+		record := data[12+16*i:]
+		tag := string(record[:4])
+		offset := binary.BigEndian.Uint32(record[8:])
+		length := binary.BigEndian.Uint32(record[12:])
+		if uint64(offset)+uint64(length) > uint64(len(data)) {
+			return nil, fmt.Errorf("表 '%s' 超出文件范围", tag)
+		}
+		f.tables[tag] = data[offset : offset+length]
+	}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "cmap", "loca", "glyf"} {
+		if _, ok := f.tables[tag]; !ok {
+			return nil, fmt.Errorf("缺少 '%s' 表", tag)
+		}
+	}
+
+	head := f.tables["head"]
+	if len(head) < 54 {
+		return nil, errors.New("head 表不完整")
+	}
+	f.unitsPerEm = float64(binary.BigEndian.Uint16(head[18:]))
+	if f.unitsPerEm == 0 {
+		return nil, errors.New("unitsPerEm 为 0")
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int16(binary.BigEndian.Uint16(head[36+2*i:]))
+	}
+	f.longLoca = binary.BigEndian.Uint16(head[50:]) == 1
+
+	hhea := f.tables["hhea"]
+	if len(hhea) < 36 {
+		return nil, errors.New("hhea 表不完整")
+	}
+	f.asc = int16(binary.BigEndian.Uint16(hhea[4:]))
+	f.desc = int16(binary.BigEndian.Uint16(hhea[6:]))
+	numHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+
+	maxp := f.tables["maxp"]
+	if len(maxp) < 6 {
+		return nil, errors.New("maxp 表不完整")
+	}
+	f.numGlyphs = int(binary.BigEndian.Uint16(maxp[4:]))
+
+	hmtx := f.tables["hmtx"]
+	if numHMetrics == 0 || len(hmtx) < 4*numHMetrics {
+		return nil, errors.New("hmtx 表不完整")
+	}
+	f.advances = make([]uint16, numHMetrics)
+	for i := range f.advances {
+		f.advances[i] = binary.BigEndian.Uint16(hmtx[4*i:])
+	}
+
+	if _, err := f.glyphRange(uint16(f.numGlyphs - 1)); err != nil {
+		return nil, errors.New("loca 表不完整")
+	}
+
+	cmap, err := parseCmap(f.tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	f.cmap = cmap
+	return f, nil
+}
+
+// Name 返回字体名称
+func (f *TrueTypeFont) Name() string {
+	return f.name
+}
+
+func (f *TrueTypeFont) face() fontFace {
+	return &trueTypeFace{font: f, used: map[uint16]rune{0: 0}}
+}
+
+// glyphAdvance 返回字形的原始宽度 (字体单位)
+func (f *TrueTypeFont) glyphAdvance(gid uint16) uint16 {
+	if int(gid) < len(f.advances) {
+		return f.advances[gid]
+	}
+	return f.advances[len(f.advances)-1]
+}
+
+// glyphRange 返回字形在 glyf 表中的起止位置
+func (f *TrueTypeFont) glyphRange(gid uint16) ([2]uint32, error) {
+	loca := f.tables["loca"]
+	var r [2]uint32
+	if f.longLoca {
+		if len(loca) < 4*(int(gid)+2) {
+			return r, errors.New("loca 越界")
+		}
+		r[0] = binary.BigEndian.Uint32(loca[4*int(gid):])
+		r[1] = binary.BigEndian.Uint32(loca[4*int(gid)+4:])
+	} else {
+		if len(loca) < 2*(int(gid)+2) {
+			return r, errors.New("loca 越界")
+		}
+		r[0] = uint32(binary.BigEndian.Uint16(loca[2*int(gid):])) * 2
+		r[1] = uint32(binary.BigEndian.Uint16(loca[2*int(gid)+2:])) * 2
+	}
+	if r[0] > r[1] || int(r[1]) > len(f.tables["glyf"]) {
+		return r, errors.New("glyf 越界")
+	}
+	return r, nil
+}
+
+// componentGlyphs 返回复合字形引用的子字形
+func (f *TrueTypeFont) componentGlyphs(glyph []byte) []uint16 {
+	if len(glyph) < 10 || int16(binary.BigEndian.Uint16(glyph)) >= 0 {
+		return nil
+	}
+	var components []uint16
+	p := 10
+	for p+4 <= len(glyph) {
+		flags := binary.BigEndian.Uint16(glyph[p:])
+		components = append(components, binary.BigEndian.Uint16(glyph[p+2:]))
+		p += 4
+		if flags&0x0001 != 0 { // ARG_1_AND_2_ARE_WORDS
+			p += 4
+		} else {
+			p += 2
+		}
+		switch {
+		case flags&0x0008 != 0: // WE_HAVE_A_SCALE
+			p += 2
+		case flags&0x0040 != 0: // WE_HAVE_AN_X_AND_Y_SCALE
+			p += 4
+		case flags&0x0080 != 0: // WE_HAVE_A_TWO_BY_TWO
+			p += 8
+		}
+		if flags&0x0020 == 0 { // MORE_COMPONENTS
+			break
+		}
+	}
+	return components
+}
+
+// subset 生成仅包含指定字形的字体文件，字形编号保持不变
+func (f *TrueTypeFont) subset(used map[uint16]rune) ([]byte, error) {
+	keep := make(map[uint16]bool)
+	var visit func(gid uint16) error
+	visit = func(gid uint16) error {
+		if keep[gid] || int(gid) >= f.numGlyphs {
+			return nil
+		}
+		keep[gid] = true
+		r, err := f.glyphRange(gid)
+		if err != nil {
+			return err
+		}
+		for _, component := range f.componentGlyphs(f.tables["glyf"][r[0]:r[1]]) {
+			if err := visit(component); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for gid := range used {
+		if err := visit(gid); err != nil {
+			return nil, err
+		}
+	}
+
+	var glyf []byte
+	loca := make([]byte, 4*(f.numGlyphs+1))
+	for gid := 0; gid < f.numGlyphs; gid++ {
+		binary.BigEndian.PutUint32(loca[4*gid:], uint32(len(glyf)))
+		if !keep[uint16(gid)] {
+			continue
+		}
+		r, _ := f.glyphRange(uint16(gid))
+		glyf = append(glyf, f.tables["glyf"][r[0]:r[1]]...)
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+	}
+	binary.BigEndian.PutUint32(loca[4*f.numGlyphs:], uint32(len(glyf)))
+
+	head := append([]byte(nil), f.tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0)  // checkSumAdjustment
+	binary.BigEndian.PutUint16(head[50:], 1) // indexToLocFormat: long
+
+	tables := map[string][]byte{
+		"head": head,
+		"hhea": f.tables["hhea"],
+		"maxp": f.tables["maxp"],
+		"hmtx": f.tables["hmtx"],
+		"loca": loca,
+		"glyf": glyf,
+	}
+	for _, tag := range []string{"cvt ", "fpgm", "prep", "OS/2", "name"} {
+		if t, ok := f.tables[tag]; ok {
+			tables[tag] = t
+		}
+	}
+	// post 表只保留头部 (版本 3.0，不含字形名称)
+	if post, ok := f.tables["post"]; ok && len(post) >= 32 {
+		post = append([]byte(nil), post[:32]...)
+		binary.BigEndian.PutUint32(post, 0x00030000)
+		tables["post"] = post
+	}
+	tables["cmap"] = buildCmap(used)
+	return buildSfnt(tables), nil
+}
+
+// buildCmap 为子集中的 BMP 字符生成格式 4 的字符映射，使子集字体可被独立解析
+func buildCmap(used map[uint16]rune) []byte {
+	byRune := make(map[rune]uint16)
+	for gid, r := range used {
+		if r > 0 && r < 0xFFFF {
+			byRune[r] = gid
+		}
+	}
+	runes := make([]int, 0, len(byRune))
+	for r := range byRune {
+		runes = append(runes, int(r))
+	}
+	sort.Ints(runes)
+
+	// 每个字符单独成段，末尾为必需的 0xFFFF 段
+	segCount := len(runes) + 1
+	length := 16 + 8*segCount
+	sub := make([]byte, length)
+	binary.BigEndian.PutUint16(sub, 4)
+	binary.BigEndian.PutUint16(sub[2:], uint16(length))
+	binary.BigEndian.PutUint16(sub[6:], uint16(2*segCount))
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= segCount {
+		entrySelector++
+	}
+	binary.BigEndian.PutUint16(sub[8:], uint16(2<<entrySelector))
+	binary.BigEndian.PutUint16(sub[10:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(sub[12:], uint16(2*segCount-2<<entrySelector))
+	ends, starts, deltas := sub[14:], sub[16+2*segCount:], sub[16+4*segCount:]
+	for i, r := range runes {
+		binary.BigEndian.PutUint16(ends[2*i:], uint16(r))
+		binary.BigEndian.PutUint16(starts[2*i:], uint16(r))
+		binary.BigEndian.PutUint16(deltas[2*i:], byRune[rune(r)]-uint16(r))
+	}
+	last := 2 * (segCount - 1)
+	binary.BigEndian.PutUint16(ends[last:], 0xFFFF)
+	binary.BigEndian.PutUint16(starts[last:], 0xFFFF)
+	binary.BigEndian.PutUint16(deltas[last:], 1)
+
+	header := make([]byte, 12)
+	binary.BigEndian.PutUint16(header[2:], 1)
+	binary.BigEndian.PutUint16(header[4:], 3)
+	binary.BigEndian.PutUint16(header[6:], 1)
+	binary.BigEndian.PutUint32(header[8:], 12)
+	return append(header, sub...)
+}
+
+// buildSfnt 按表名排序组装字体文件
+func buildSfnt(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= len(tags) {
+		entrySelector++
+	}
+	searchRange := (1 << entrySelector) * 16
+
+	header := make([]byte, 12+16*len(tags))
+	binary.BigEndian.PutUint32(header, 0x00010000)
+	binary.BigEndian.PutUint16(header[4:], uint16(len(tags)))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(header[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[10:], uint16(len(tags)*16-searchRange))
+
+	var body []byte
+	for i, tag := range tags {
+		table := tables[tag]
+		record := header[12+16*i:]
+		copy(record, tag)
+		binary.BigEndian.PutUint32(record[4:], tableChecksum(table))
+		binary.BigEndian.PutUint32(record[8:], uint32(len(header)+len(body)))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(table)))
+		body = append(body, table...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	return append(header, body...)
+}
+
+func tableChecksum(table []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(table); i += 4 {
+		var word [4]byte
+		copy(word[:], table[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+// parseCmap 读取 Unicode 字符映射，优先使用格式 12 (完整 Unicode)，其次格式 4 (BMP)
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, errors.New("cmap 表不完整")
+	}
+	best, bestScore := -1, 0
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables && 4+8*i+8 <= len(cmap); i++ {
+		record := cmap[4+8*i:]
+		platform := binary.BigEndian.Uint16(record)
+		encoding := binary.BigEndian.Uint16(record[2:])
+		offset := int(binary.BigEndian.Uint32(record[4:]))
+		if offset+2 > len(cmap) {
+			continue
+		}
+		format := binary.BigEndian.Uint16(cmap[offset:])
+		score := 0
+		switch {
+		case format == 12 && (platform == 3 && encoding == 10 || platform == 0):
+			score = 3
+		case format == 4 && platform == 3 && encoding == 1:
+			score = 2
+		case format == 4 && platform == 0:
+			score = 1
+		}
+		if score > bestScore {
+			best, bestScore = offset, score
+		}
+	}
+	if best < 0 {
+		return nil, errors.New("缺少 Unicode 字符映射")
+	}
+
+	sub := cmap[best:]
+	m := make(map[rune]uint16)
+	if binary.BigEndian.Uint16(sub) == 12 {
+		if len(sub) < 16 {
+			return nil, errors.New("cmap 格式 12 不完整")
+		}
+		n := int(binary.BigEndian.Uint32(sub[12:]))
+		if len(sub) < 16+12*n {
+			return nil, errors.New("cmap 格式 12 不完整")
+		}
+		for i := 0; i < n; i++ {
+			group := sub[16+12*i:]
+			start := binary.BigEndian.Uint32(group)
+			end := binary.BigEndian.Uint32(group[4:])
+			gid := binary.BigEndian.Uint32(group[8:])
+			for c := start; c <= end && c <= 0x10FFFF; c++ {
+				m[rune(c)] = uint16(gid + c - start)
+			}
+		}
+		return m, nil
+	}
+
+	if len(sub) < 14 {
+		return nil, errors.New("cmap 格式 4 不完整")
+	}
+	segCount := int(binary.BigEndian.Uint16(sub[6:])) / 2
+	if len(sub) < 16+8*segCount {
+		return nil, errors.New("cmap 格式 4 不完整")
+	}
+	ends := sub[14:]
+	starts := sub[16+2*segCount:]
+	deltas := sub[16+4*segCount:]
+	rangeOffsets := sub[16+6*segCount:]
+	for i := 0; i < segCount; i++ {
+		end := binary.BigEndian.Uint16(ends[2*i:])
+		start := binary.BigEndian.Uint16(starts[2*i:])
+		delta := binary.BigEndian.Uint16(deltas[2*i:])
+		rangeOffset := int(binary.BigEndian.Uint16(rangeOffsets[2*i:]))
+		for c := uint32(start); c <= uint32(end) && c != 0xFFFF; c++ {
+			var gid uint16
+			if rangeOffset == 0 {
+				gid = uint16(c) + delta
+			} else {
+				p := 16 + 6*segCount + 2*i + rangeOffset + 2*int(c-uint32(start))
+				if p+2 > len(sub) {
+					continue
+				}
+				gid = binary.BigEndian.Uint16(sub[p:])
+				if gid != 0 {
+					gid += delta
+				}
+			}
+			if gid != 0 {
+				m[rune(c)] = gid
+			}
+		}
+	}
+	return m, nil
+}
+
+// sanitizeFontName 去掉 PDF 名称中不允许的字符
+func sanitizeFontName(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if r > 0x20 && r < 0x7F && !strings.ContainsRune("()<>[]{}/%#", r) {
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() == 0 {
+		return "Font"
+	}
+	return sb.String()
+}
+
+// trueTypeFace 记录一次渲染中用到的字形，写入时只嵌入这些字形
+type trueTypeFace struct {
+	font *TrueTypeFont
+	used map[uint16]rune
+}
+
+func (t *trueTypeFace) scale(v int) float64 {
+	return float64(v) * 1000 / t.font.unitsPerEm
+}
+
+func (t *trueTypeFace) advance(r rune) float64 {
+	return t.scale(int(t.font.glyphAdvance(t.font.cmap[r])))
+}
+
+// encode 使用 Identity-H 编码，CID 即字形编号
+func (t *trueTypeFace) encode(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		gid := t.font.cmap[r]
+		if _, ok := t.used[gid]; !ok || gid == 0 {
+			t.used[gid] = r
+		}
+		fmt.Fprintf(&sb, "%04X", gid)
+	}
+	return sb.String()
+}
+
+func (t *trueTypeFace) ascent() float64 {
+	return t.scale(int(t.font.asc))
+}
+
+func (t *trueTypeFace) descent() float64 {
+	return t.scale(int(t.font.desc))
+}
+
+func (t *trueTypeFace) writeTo(w *pdfWriter) (int, error) {
+	data, err := t.font.subset(t.used)
+	if err != nil {
+		return 0, fmt.Errorf("无法生成字体子集: %w", err)
+	}
+	baseFont := subsetTag(t.used) + "+" + t.font.name
+
+	gids := make([]int, 0, len(t.used))
+	for gid := range t.used {
+		gids = append(gids, int(gid))
+	}
+	sort.Ints(gids)
+
+	var widths, toUnicode strings.Builder
+	toUnicode.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	var mappings []string
+	for _, gid := range gids {
+		fmt.Fprintf(&widths, "%d [%.0f] ", gid, t.scale(int(t.font.glyphAdvance(uint16(gid)))))
+		if r := t.used[uint16(gid)]; r != 0 {
+			mappings = append(mappings, fmt.Sprintf("<%04X> <%s>", gid, utf16Hex(string(r))))
+		}
+	}
+	// 每个 bfchar 段最多 100 项
+	for len(mappings) > 0 {
+		n := min(len(mappings), 100)
+		fmt.Fprintf(&toUnicode, "%d beginbfchar\n", n)
+		for _, m := range mappings[:n] {
+			toUnicode.WriteString(m + "\n")
+		}
+		toUnicode.WriteString("endbfchar\n")
+		mappings = mappings[n:]
+	}
+	toUnicode.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+
+	fontFile := w.addStream(data, fmt.Sprintf("/Length1 %d ", len(data)))
+	bbox := t.font.bbox
+	descriptor := w.addObject(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 4 "+
+		"/FontBBox [%.0f %.0f %.0f %.0f] /ItalicAngle 0 /Ascent %.0f /Descent %.0f /CapHeight %.0f /StemV 80 /FontFile2 %d 0 R >>",
+		baseFont, t.scale(int(bbox[0])), t.scale(int(bbox[1])), t.scale(int(bbox[2])), t.scale(int(bbox[3])),
+		t.ascent(), t.descent(), t.ascent(), fontFile))
+	cidFont := w.addObject(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+		"/FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>", baseFont, descriptor, widths.String()))
+	unicodeMap := w.addStream([]byte(toUnicode.String()), "")
+	return w.addObject(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H "+
+		"/DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", baseFont, cidFont, unicodeMap)), nil
+}
+
+// subsetTag 根据子集内容生成 6 个大写字母的标记
+func subsetTag(used map[uint16]rune) string {
+	var h uint32 = 2166136261
+	gids := make([]int, 0, len(used))
+	for gid := range used {
+		gids = append(gids, int(gid))
+	}
+	sort.Ints(gids)
+	for _, gid := range gids {
+		h = (h ^ uint32(gid)) * 16777619
+	}
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + byte(h%26)
+		h /= 26
+	}
+	return string(tag)
+}
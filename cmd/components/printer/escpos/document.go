@@ -0,0 +1,201 @@
+// Package escpos 将结构化的小票文档编码为 ESC/POS 指令
+package escpos
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 元素类型
+const (
+	TypeText       = "text"
+	TypeSeparator  = "separator"
+	TypeTable      = "table"
+	TypeBarcode    = "barcode"
+	TypeQRCode     = "qrcode"
+	TypeImage      = "image"
+	TypeFeed       = "feed"
+	TypeCut        = "cut"
+	TypeCashDrawer = "cashDrawer"
+)
+
+// 对齐方式
+const (
+	AlignLeft   = "left"
+	AlignCenter = "center"
+	AlignRight  = "right"
+)
+
+const (
+	defaultWidth       = 48  // 80mm 纸宽、12x24 字体下每行字符数
+	defaultDotsPerLine = 576 // 80mm 纸宽、203dpi 下每行点数
+	defaultCodePage    = "gb18030"
+)
+
+// Document 是一张小票的结构化描述
+type Document struct {
+	// Width 为每行可容纳的半角字符数，默认 48
+	Width int `json:"width,omitempty"`
+	// DotsPerLine 为每行的打印点数，用于限制图片宽度，默认 576
+	DotsPerLine int `json:"dotsPerLine,omitempty"`
+	// CodePage 为文本编码: gb18030 (默认)、cp437、cp850、cp858、cp1252
+	CodePage string    `json:"codePage,omitempty"`
+	Elements []Element `json:"elements"`
+}
+
+// Element 是小票中的一个元素，字段按 Type 取用
+type Element struct {
+	Type string `json:"type"`
+
+	// text
+	Text      string `json:"text,omitempty"`
+	Align     string `json:"align,omitempty"`
+	Bold      bool   `json:"bold,omitempty"`
+	Underline bool   `json:"underline,omitempty"`
+	// WidthScale、HeightScale 为字符放大倍数 (1-8)
+	WidthScale  int `json:"widthScale,omitempty"`
+	HeightScale int `json:"heightScale,omitempty"`
+
+	// separator
+	Char string `json:"char,omitempty"`
+
+	// table
+	Columns []Column   `json:"columns,omitempty"`
+	Rows    [][]string `json:"rows,omitempty"`
+
+	// barcode、qrcode
+	Data string `json:"data,omitempty"`
+	// Symbology 为条码类型: UPC-A、UPC-E、EAN13、EAN8、CODE39、ITF、CODABAR、CODE93、CODE128
+	Symbology string `json:"symbology,omitempty"`
+	// Height 为条码高度 (点)
+	Height int `json:"height,omitempty"`
+	// ModuleWidth 为条码单元宽度 (2-6) 或二维码模块大小 (1-16)
+	ModuleWidth int `json:"moduleWidth,omitempty"`
+	// HRI 为条码下方可读字符的位置: none、above、below、both
+	HRI string `json:"hri,omitempty"`
+	// ErrorCorrection 为二维码纠错等级: L、M、Q、H
+	ErrorCorrection string `json:"errorCorrection,omitempty"`
+
+	// image: Data 为 base64 编码的 PNG、JPEG 或 GIF 图片
+	Threshold int `json:"threshold,omitempty"`
+
+	// feed、cut
+	Lines   int  `json:"lines,omitempty"`
+	Partial bool `json:"partial,omitempty"`
+
+	// cashDrawer: 0 表示 2 号引脚，1 表示 5 号引脚
+	Pin int `json:"pin,omitempty"`
+}
+
+// Column 描述表格的一列
+type Column struct {
+	// Width 为列宽 (半角字符数)
+	Width int    `json:"width"`
+	Align string `json:"align,omitempty"`
+}
+
+// Validate 检查文档是否合法，并返回第一个错误
+func (d *Document) Validate() error {
+	if len(d.Elements) == 0 {
+		return fmt.Errorf("小票内容不能为空")
+	}
+	if _, ok := codePages[d.codePage()]; !ok {
+		return fmt.Errorf("不支持的编码 '%s'", d.CodePage)
+	}
+	for i := range d.Elements {
+		if err := d.Elements[i].validate(d.width()); err != nil {
+			return fmt.Errorf("第 %d 个元素 (%s): %w", i+1, d.Elements[i].Type, err)
+		}
+	}
+	return nil
+}
+
+func (e *Element) validate(width int) error {
+	switch e.Align {
+	case "", AlignLeft, AlignCenter, AlignRight:
+	default:
+		return fmt.Errorf("不支持的对齐方式 '%s'", e.Align)
+	}
+
+	switch e.Type {
+	case TypeText:
+		if e.WidthScale < 0 || e.WidthScale > 8 || e.HeightScale < 0 || e.HeightScale > 8 {
+			return fmt.Errorf("字符放大倍数必须在 1-8 之间")
+		}
+	case TypeSeparator, TypeFeed, TypeCut:
+	case TypeTable:
+		if len(e.Columns) == 0 {
+			return fmt.Errorf("表格必须定义列")
+		}
+		total := 0
+		for _, col := range e.Columns {
+			if col.Width <= 0 {
+				return fmt.Errorf("列宽必须大于 0")
+			}
+			if col.Align != "" && col.Align != AlignLeft && col.Align != AlignCenter && col.Align != AlignRight {
+				return fmt.Errorf("不支持的列对齐方式 '%s'", col.Align)
+			}
+			total += col.Width
+		}
+		if total > width {
+			return fmt.Errorf("列宽之和 %d 超过了行宽 %d", total, width)
+		}
+		for _, row := range e.Rows {
+			if len(row) > len(e.Columns) {
+				return fmt.Errorf("行的单元格数量 %d 超过了列数 %d", len(row), len(e.Columns))
+			}
+		}
+	case TypeBarcode:
+		if e.Data == "" {
+			return fmt.Errorf("条码内容不能为空")
+		}
+		if _, ok := barcodeSymbologies[strings.ToUpper(e.Symbology)]; !ok {
+			return fmt.Errorf("不支持的条码类型 '%s'", e.Symbology)
+		}
+		if _, ok := hriPositions[e.HRI]; !ok {
+			return fmt.Errorf("不支持的 HRI 位置 '%s'", e.HRI)
+		}
+	case TypeQRCode:
+		if e.Data == "" {
+			return fmt.Errorf("二维码内容不能为空")
+		}
+		if len(e.Data) > 7089 {
+			return fmt.Errorf("二维码内容过长")
+		}
+		if _, ok := qrErrorCorrection[strings.ToUpper(e.ErrorCorrection)]; !ok {
+			return fmt.Errorf("不支持的纠错等级 '%s'", e.ErrorCorrection)
+		}
+	case TypeImage:
+		if e.Data == "" {
+			return fmt.Errorf("图片内容不能为空")
+		}
+	case TypeCashDrawer:
+		if e.Pin != 0 && e.Pin != 1 {
+			return fmt.Errorf("钱箱引脚只能为 0 或 1")
+		}
+	default:
+		return fmt.Errorf("未知的元素类型")
+	}
+	return nil
+}
+
+func (d *Document) width() int {
+	if d.Width > 0 {
+		return d.Width
+	}
+	return defaultWidth
+}
+
+func (d *Document) dotsPerLine() int {
+	if d.DotsPerLine > 0 {
+		return d.DotsPerLine
+	}
+	return defaultDotsPerLine
+}
+
+func (d *Document) codePage() string {
+	if d.CodePage == "" {
+		return defaultCodePage
+	}
+	return strings.ToLower(d.CodePage)
+}
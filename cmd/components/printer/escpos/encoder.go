@@ -0,0 +1,347 @@
+package escpos
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/width"
+)
+
+// 控制字符
+const (
+	lf  = 0x0A
+	esc = 0x1B
+	fs  = 0x1C
+	gs  = 0x1D
+)
+
+// codePage 描述一种文本编码及其对应的打印机代码页
+type codePage struct {
+	// table 为 ESC t 的代码页编号
+	table byte
+	// chinese 为 true 时使用汉字模式 (FS &) 并以 GB18030 编码
+	chinese bool
+	charmap *charmap.Charmap
+}
+
+var codePages = map[string]codePage{
+	"gb18030": {chinese: true},
+	"cp437":   {table: 0, charmap: charmap.CodePage437},
+	"cp850":   {table: 2, charmap: charmap.CodePage850},
+	"cp858":   {table: 19, charmap: charmap.CodePage858},
+	"cp1252":  {table: 16, charmap: charmap.Windows1252},
+}
+
+// barcodeSymbologies 为 GS k 格式二中的条码类型编号
+var barcodeSymbologies = map[string]byte{
+	"UPC-A":   65,
+	"UPC-E":   66,
+	"EAN13":   67,
+	"EAN8":    68,
+	"CODE39":  69,
+	"ITF":     70,
+	"CODABAR": 71,
+	"CODE93":  72,
+	"CODE128": 73,
+}
+
+var hriPositions = map[string]byte{
+	"":      2,
+	"none":  0,
+	"above": 1,
+	"below": 2,
+	"both":  3,
+}
+
+var qrErrorCorrection = map[string]byte{
+	"":  49,
+	"L": 48,
+	"M": 49,
+	"Q": 50,
+	"H": 51,
+}
+
+// Encode 将小票文档编码为 ESC/POS 字节流
+func Encode(doc *Document) ([]byte, error) {
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+	e := &encoder{doc: doc, cp: codePages[doc.codePage()]}
+
+	// 初始化打印机并选择代码页
+	e.write(esc, '@')
+	if e.cp.chinese {
+		e.write(fs, '&')
+	} else {
+		e.write(fs, '.', esc, 't', e.cp.table)
+	}
+
+	for i := range doc.Elements {
+		if err := e.element(&doc.Elements[i]); err != nil {
+			return nil, fmt.Errorf("第 %d 个元素 (%s): %w", i+1, doc.Elements[i].Type, err)
+		}
+	}
+	return e.buf.Bytes(), nil
+}
+
+type encoder struct {
+	doc *Document
+	cp  codePage
+	buf bytes.Buffer
+}
+
+func (e *encoder) write(b ...byte) {
+	e.buf.Write(b)
+}
+
+func (e *encoder) element(el *Element) error {
+	switch el.Type {
+	case TypeText:
+		e.text(el)
+	case TypeSeparator:
+		e.separator(el)
+	case TypeTable:
+		e.table(el)
+	case TypeBarcode:
+		return e.barcode(el)
+	case TypeQRCode:
+		e.qrcode(el)
+	case TypeImage:
+		return e.image(el)
+	case TypeFeed:
+		e.write(esc, 'd', byte(clamp(el.Lines, 1, 255, 1)))
+	case TypeCut:
+		mode := byte(65)
+		if el.Partial {
+			mode = 66
+		}
+		e.write(gs, 'V', mode, byte(clamp(el.Lines, 0, 255, 3)))
+	case TypeCashDrawer:
+		e.write(esc, 'p', byte(el.Pin), 25, 250)
+	}
+	return nil
+}
+
+func (e *encoder) text(el *Element) {
+	e.align(el.Align)
+	if el.Bold {
+		e.write(esc, 'E', 1)
+	}
+	if el.Underline {
+		e.write(esc, '-', 1)
+	}
+	size := byte(clamp(el.WidthScale, 1, 8, 1)-1)<<4 | byte(clamp(el.HeightScale, 1, 8, 1)-1)
+	if size != 0 {
+		e.write(gs, '!', size)
+	}
+
+	for _, line := range strings.Split(el.Text, "\n") {
+		e.encodeText(line)
+		e.write(lf)
+	}
+
+	if size != 0 {
+		e.write(gs, '!', 0)
+	}
+	if el.Underline {
+		e.write(esc, '-', 0)
+	}
+	if el.Bold {
+		e.write(esc, 'E', 0)
+	}
+	e.resetAlign(el.Align)
+}
+
+func (e *encoder) separator(el *Element) {
+	char := el.Char
+	if char == "" {
+		char = "-"
+	}
+	count := e.doc.width() / max(displayWidth(char), 1)
+	e.encodeText(strings.Repeat(char, count))
+	e.write(lf)
+}
+
+func (e *encoder) table(el *Element) {
+	for _, row := range el.Rows {
+		// 每个单元格按列宽折行，行高取决于最高的单元格
+		cells := make([][]string, len(el.Columns))
+		height := 1
+		for i, col := range el.Columns {
+			text := ""
+			if i < len(row) {
+				text = row[i]
+			}
+			cells[i] = wrap(text, col.Width)
+			height = max(height, len(cells[i]))
+		}
+		for line := 0; line < height; line++ {
+			var sb strings.Builder
+			for i, col := range el.Columns {
+				text := ""
+				if line < len(cells[i]) {
+					text = cells[i][line]
+				}
+				sb.WriteString(pad(text, col.Width, col.Align))
+			}
+			e.encodeText(strings.TrimRight(sb.String(), " "))
+			e.write(lf)
+		}
+	}
+}
+
+func (e *encoder) barcode(el *Element) error {
+	symbology := strings.ToUpper(el.Symbology)
+	data := el.Data
+	if symbology == "CODE128" && !strings.HasPrefix(data, "{") {
+		// 未指定字符集时默认使用 CODE B
+		data = "{B" + data
+	}
+	if len(data) > 255 {
+		return fmt.Errorf("条码内容过长")
+	}
+
+	e.align(el.Align)
+	e.write(gs, 'h', byte(clamp(el.Height, 1, 255, 80)))
+	e.write(gs, 'w', byte(clamp(el.ModuleWidth, 2, 6, 3)))
+	e.write(gs, 'H', hriPositions[el.HRI])
+	e.write(gs, 'k', barcodeSymbologies[symbology], byte(len(data)))
+	e.buf.WriteString(data)
+	e.resetAlign(el.Align)
+	return nil
+}
+
+func (e *encoder) qrcode(el *Element) {
+	e.align(el.Align)
+	// 选择模型 2
+	e.write(gs, '(', 'k', 4, 0, 49, 65, 50, 0)
+	// 模块大小
+	e.write(gs, '(', 'k', 3, 0, 49, 67, byte(clamp(el.ModuleWidth, 1, 16, 6)))
+	// 纠错等级
+	e.write(gs, '(', 'k', 3, 0, 49, 69, qrErrorCorrection[strings.ToUpper(el.ErrorCorrection)])
+	// 存储数据
+	n := len(el.Data) + 3
+	e.write(gs, '(', 'k', byte(n%256), byte(n/256), 49, 80, 48)
+	e.buf.WriteString(el.Data)
+	// 打印
+	e.write(gs, '(', 'k', 3, 0, 49, 81, 48)
+	e.resetAlign(el.Align)
+}
+
+func (e *encoder) image(el *Element) error {
+	raster, err := decodeRaster(el.Data, e.doc.dotsPerLine(), clamp(el.Threshold, 1, 255, 128))
+	if err != nil {
+		return err
+	}
+	e.align(el.Align)
+	e.write(gs, 'v', '0', 0,
+		byte(raster.widthBytes%256), byte(raster.widthBytes/256),
+		byte(raster.height%256), byte(raster.height/256))
+	e.buf.Write(raster.data)
+	e.resetAlign(el.Align)
+	return nil
+}
+
+func (e *encoder) align(align string) {
+	switch align {
+	case AlignCenter:
+		e.write(esc, 'a', 1)
+	case AlignRight:
+		e.write(esc, 'a', 2)
+	}
+}
+
+func (e *encoder) resetAlign(align string) {
+	if align == AlignCenter || align == AlignRight {
+		e.write(esc, 'a', 0)
+	}
+}
+
+// encodeText 按文档代码页编码文本，无法表示的字符以 '?' 代替
+func (e *encoder) encodeText(s string) {
+	if e.cp.chinese {
+		encoded, err := simplifiedchinese.GB18030.NewEncoder().String(s)
+		if err == nil {
+			e.buf.WriteString(encoded)
+			return
+		}
+	}
+	for _, r := range s {
+		if e.cp.charmap == nil {
+			e.buf.WriteByte('?')
+			continue
+		}
+		if b, ok := e.cp.charmap.EncodeRune(r); ok {
+			e.buf.WriteByte(b)
+		} else {
+			e.buf.WriteByte('?')
+		}
+	}
+}
+
+// runeWidth 返回字符占用的半角宽度，全角与宽字符为 2
+func runeWidth(r rune) int {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	return w
+}
+
+// wrap 将文本按显示宽度折行
+func wrap(s string, limit int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		var sb strings.Builder
+		w := 0
+		for _, r := range paragraph {
+			rw := runeWidth(r)
+			if w+rw > limit && w > 0 {
+				lines = append(lines, sb.String())
+				sb.Reset()
+				w = 0
+			}
+			sb.WriteRune(r)
+			w += rw
+		}
+		lines = append(lines, sb.String())
+	}
+	return lines
+}
+
+// pad 按对齐方式将文本填充到指定显示宽度
+func pad(s string, limit int, align string) string {
+	gap := limit - displayWidth(s)
+	if gap <= 0 {
+		return s
+	}
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", gap) + s
+	case AlignCenter:
+		left := gap / 2
+		return strings.Repeat(" ", left) + s + strings.Repeat(" ", gap-left)
+	default:
+		return s + strings.Repeat(" ", gap)
+	}
+}
+
+// clamp 将 v 限制在 [lo, hi] 内，v 为 0 时使用默认值
+func clamp(v, lo, hi, def int) int {
+	if v == 0 {
+		return def
+	}
+	return min(max(v, lo), hi)
+}
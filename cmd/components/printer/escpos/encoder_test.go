@@ -0,0 +1,148 @@
+package escpos
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "重新生成 testdata 中的 golden 文件")
+
+var header = []byte{esc, '@', fs, '&'}
+
+func encodeElements(t *testing.T, elements ...Element) []byte {
+	t.Helper()
+	out, err := Encode(&Document{Width: 16, Elements: elements})
+	if err != nil {
+		t.Fatalf("编码失败: %v", err)
+	}
+	if !bytes.HasPrefix(out, header) {
+		t.Fatalf("缺少初始化指令: % X", out)
+	}
+	return out[len(header):]
+}
+
+func assertBytes(t *testing.T, got, want []byte) {
+	t.Helper()
+	if !bytes.Equal(got, want) {
+		t.Errorf("字节不匹配:\n got: % X\nwant: % X", got, want)
+	}
+}
+
+// TestEncodeText 测试文本样式与 GB18030 编码
+func TestEncodeText(t *testing.T) {
+	got := encodeElements(t, Element{Type: TypeText, Text: "中A", Align: AlignCenter, Bold: true, WidthScale: 2})
+	want := []byte{
+		esc, 'a', 1, esc, 'E', 1, gs, '!', 0x10,
+		0xD6, 0xD0, 'A', lf,
+		gs, '!', 0, esc, 'E', 0, esc, 'a', 0,
+	}
+	assertBytes(t, got, want)
+}
+
+// TestEncodeTable 测试表格按显示宽度对齐与折行
+func TestEncodeTable(t *testing.T) {
+	got := encodeElements(t, Element{
+		Type:    TypeTable,
+		Columns: []Column{{Width: 6}, {Width: 4, Align: AlignRight}},
+		Rows:    [][]string{{"茶", "1"}, {"abcdefgh", "22"}},
+	})
+	want := []byte("\xB2\xE8       1\nabcdef  22\ngh\n")
+	assertBytes(t, got, want)
+}
+
+// TestEncodeCodePage 测试西文代码页与不可编码字符
+func TestEncodeCodePage(t *testing.T) {
+	out, err := Encode(&Document{CodePage: "cp437", Elements: []Element{{Type: TypeText, Text: "é中"}}})
+	if err != nil {
+		t.Fatalf("编码失败: %v", err)
+	}
+	assertBytes(t, out, []byte{esc, '@', fs, '.', esc, 't', 0, 0x82, '?', lf})
+}
+
+// TestEncodeBarcodeAndQRCode 测试条码与二维码指令
+func TestEncodeBarcodeAndQRCode(t *testing.T) {
+	got := encodeElements(t,
+		Element{Type: TypeBarcode, Symbology: "ean13", Data: "690123456789", HRI: "none"},
+		Element{Type: TypeQRCode, Data: "hi"},
+	)
+	want := []byte{gs, 'h', 80, gs, 'w', 3, gs, 'H', 0, gs, 'k', 67, 12}
+	want = append(want, "690123456789"...)
+	want = append(want,
+		gs, '(', 'k', 4, 0, 49, 65, 50, 0,
+		gs, '(', 'k', 3, 0, 49, 67, 6,
+		gs, '(', 'k', 3, 0, 49, 69, 49,
+		gs, '(', 'k', 5, 0, 49, 80, 48, 'h', 'i',
+		gs, '(', 'k', 3, 0, 49, 81, 48,
+	)
+	assertBytes(t, got, want)
+}
+
+// TestEncodeImage 测试图片转换为单色位图
+func TestEncodeImage(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 10, 2))
+	for x := 0; x < 10; x++ {
+		img.Set(x, 0, color.White)
+		img.Set(x, 1, color.White)
+	}
+	img.Set(0, 0, color.Black)
+	img.Set(9, 1, color.Black)
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+
+	got := encodeElements(t, Element{Type: TypeImage, Data: base64.StdEncoding.EncodeToString(buf.Bytes())})
+	want := []byte{gs, 'v', '0', 0, 2, 0, 2, 0, 0x80, 0x00, 0x00, 0x40}
+	assertBytes(t, got, want)
+}
+
+// TestValidate 测试非法文档被拒绝
+func TestValidate(t *testing.T) {
+	cases := []Document{
+		{},
+		{CodePage: "ebcdic", Elements: []Element{{Type: TypeFeed}}},
+		{Elements: []Element{{Type: "unknown"}}},
+		{Width: 10, Elements: []Element{{Type: TypeTable, Columns: []Column{{Width: 6}, {Width: 6}}}}},
+		{Elements: []Element{{Type: TypeBarcode, Symbology: "PDF417", Data: "1"}}},
+		{Elements: []Element{{Type: TypeText, Text: "x", WidthScale: 9}}},
+	}
+	for i, doc := range cases {
+		if _, err := Encode(&doc); err == nil {
+			t.Errorf("第 %d 个用例应当校验失败", i+1)
+		}
+	}
+}
+
+// TestGoldenReceipt 使用 golden 文件校验完整小票的编码结果
+func TestGoldenReceipt(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join("testdata", "receipt.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc Document
+	if err := json.Unmarshal(input, &doc); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Encode(&doc)
+	if err != nil {
+		t.Fatalf("编码失败: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "receipt.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBytes(t, got, want)
+}
@@ -0,0 +1,68 @@
+package escpos
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // 注册 GIF 解码器
+	_ "image/jpeg" // 注册 JPEG 解码器
+	_ "image/png"  // 注册 PNG 解码器
+)
+
+// raster 是 GS v 0 所需的单色位图
+type raster struct {
+	widthBytes int
+	height     int
+	data       []byte
+}
+
+// decodeRaster 解码 base64 图片并转换为单色位图，宽度超过 maxDots 时等比缩小
+func decodeRaster(encoded string, maxDots, threshold int) (*raster, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("图片不是合法的 base64 数据: %w", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("无法解码图片: %w", err)
+	}
+
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW == 0 || srcH == 0 {
+		return nil, fmt.Errorf("图片尺寸为空")
+	}
+	dstW, dstH := srcW, srcH
+	if dstW > maxDots {
+		dstW = maxDots
+		dstH = max(srcH*maxDots/srcW, 1)
+	}
+	if dstH > 0xFFFF {
+		return nil, fmt.Errorf("图片过高")
+	}
+
+	r := &raster{widthBytes: (dstW + 7) / 8, height: dstH}
+	r.data = make([]byte, r.widthBytes*dstH)
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			// 最近邻采样
+			c := img.At(bounds.Min.X+x*srcW/dstW, bounds.Min.Y+y*srcH/dstH)
+			if isDark(c, threshold) {
+				r.data[y*r.widthBytes+x/8] |= 0x80 >> (x % 8)
+			}
+		}
+	}
+	return r, nil
+}
+
+// isDark 判断像素是否应打印为黑点，透明像素视为白色
+func isDark(c color.Color, threshold int) bool {
+	_, _, _, a := c.RGBA()
+	if a < 0x8000 {
+		return false
+	}
+	gray := color.GrayModel.Convert(c).(color.Gray)
+	return int(gray.Y) < threshold
+}
@@ -0,0 +1,541 @@
+// Package jobs 负责打印作业的编号、状态跟踪与历史记录
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"cse-go/cmd/components/printer/backend"
+)
+
+const (
+	defaultHistoryLimit   = 500
+	defaultPollInterval   = 2 * time.Second
+	defaultInitialBackoff = 2 * time.Second
+	defaultMaxBackoff     = time.Minute
+	stateFileName         = "jobs.json"
+)
+
+// ErrNotFound 表示作业 ID 不存在
+var ErrNotFound = errors.New("作业不存在")
+
+// Job 描述一个由打印组件提交的作业
+type Job struct {
+	ID           string           `json:"id"`
+	Printer      string           `json:"printer"`
+	Document     string           `json:"document"`
+	State        backend.JobState `json:"state"`
+	Message      string           `json:"message,omitempty"`
+	BackendRef   string           `json:"backendRef,omitempty"`
+	Size         int              `json:"size"`
+	TotalPages   int              `json:"totalPages,omitempty"`
+	PagesPrinted int              `json:"pagesPrinted,omitempty"`
+	DataType     string           `json:"dataType,omitempty"`
+	CreatedAt    time.Time        `json:"createdAt"`
+	UpdatedAt    time.Time        `json:"updatedAt"`
+	FinishedAt   *time.Time       `json:"finishedAt,omitempty"`
+
+	// Spooled 表示文档内容保存在假脱机目录中，可以重新提交
+	Spooled bool `json:"spooled"`
+	// Attempts 为向后端提交的次数
+	Attempts int `json:"attempts,omitempty"`
+	// LastError 为最近一次提交失败的原因
+	LastError string `json:"lastError,omitempty"`
+	// NextRetryAt 为下一次自动重试的时间，RetryDeadline 为自动重试的截止时间
+	NextRetryAt   *time.Time `json:"nextRetryAt,omitempty"`
+	RetryDeadline *time.Time `json:"retryDeadline,omitempty"`
+}
+
+// Filter 定义了 List 的筛选条件，零值字段表示不限制
+type Filter struct {
+	Printer string
+	// Printers 匹配其中任意一台打印机，用于按逻辑打印机筛选
+	Printers []string
+	State    backend.JobState
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+}
+
+// Options 定义了作业管理器的配置
+type Options struct {
+	// Dir 为历史记录的持久化目录，为空时不持久化
+	Dir string
+	// HistoryLimit 为保留的已结束作业数量上限
+	HistoryLimit int
+	// PollInterval 为轮询后端作业状态与检查到期重试的间隔
+	PollInterval time.Duration
+	// SpoolDir 为保存待打印文档内容的假脱机目录，为空时不保存，作业也无法重试
+	SpoolDir string
+	// RetryWindow 为提交失败后自动重试的时长，为 0 时提交失败立即标记为 failed
+	RetryWindow time.Duration
+	// InitialBackoff 为首次重试的等待时间，之后每次翻倍，最长为 MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// OnRetry 在每次自动重试提交之后调用，job 为提交后的作业快照，err 为作业因此失败时的错误
+	OnRetry func(job *Job, err error)
+}
+
+// Manager 跟踪所有经由打印组件提交的作业
+type Manager struct {
+	backend backend.Backend
+	opts    Options
+
+	mu     sync.Mutex
+	jobs   map[string]*Job
+	nextID int
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// persistedState 是写入磁盘的历史记录格式
+type persistedState struct {
+	NextID int    `json:"nextId"`
+	Jobs   []*Job `json:"jobs"`
+}
+
+// NewManager 创建作业管理器，并从磁盘恢复历史记录
+func NewManager(b backend.Backend, opts Options) (*Manager, error) {
+	if opts.HistoryLimit <= 0 {
+		opts.HistoryLimit = defaultHistoryLimit
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = defaultInitialBackoff
+	}
+	if opts.MaxBackoff < opts.InitialBackoff {
+		opts.MaxBackoff = max(defaultMaxBackoff, opts.InitialBackoff)
+	}
+	m := &Manager{
+		backend: b,
+		opts:    opts,
+		jobs:    make(map[string]*Job),
+	}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Start 启动后台轮询协程
+func (m *Manager) Start() {
+	m.stop = make(chan struct{})
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.opts.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.Poll()
+				m.RetryDue()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台轮询协程
+func (m *Manager) Stop() {
+	if m.stop == nil {
+		return
+	}
+	close(m.stop)
+	m.wg.Wait()
+	m.stop = nil
+}
+
+// Submit 为文档分配作业 ID，保存到假脱机目录后提交给后端。
+// 提交失败且启用了自动重试时作业进入 retrying 状态，不返回错误；
+// 否则作业以 failed 状态记录，同时返回错误。
+func (m *Manager) Submit(doc *backend.Document) (*Job, error) {
+	now := time.Now()
+	m.mu.Lock()
+	m.nextID++
+	job := &Job{
+		ID:        fmt.Sprintf("job-%06d", m.nextID),
+		Printer:   doc.Printer,
+		Document:  doc.Name,
+		State:     backend.JobQueued,
+		Size:      len(doc.Data),
+		DataType:  doc.DataType,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.jobs[job.ID] = job
+	m.mu.Unlock()
+
+	spooled := m.writeSpool(job.ID, doc.Data)
+
+	m.mu.Lock()
+	job.Spooled = spooled
+	if job.State.Terminal() {
+		// 写入假脱机文件期间作业已被取消，finish 时尚无文件可删除
+		m.removeSpool(job)
+	}
+	m.persist()
+	m.mu.Unlock()
+
+	err := m.attempt(job, doc.Data)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return job.clone(), err
+}
+
+// attempt 向后端提交一次作业，失败时按重试策略安排下一次提交。
+// 调用方需先将作业置为 queued 状态，作业已不在该状态 (例如已被取消) 时不提交
+func (m *Manager) attempt(job *Job, data []byte) error {
+	m.mu.Lock()
+	if job.State != backend.JobQueued {
+		m.mu.Unlock()
+		return nil
+	}
+	// 在文档名中带上作业 ID，便于后端在队列中唯一识别该作业
+	doc := &backend.Document{
+		Printer:  job.Printer,
+		Name:     fmt.Sprintf("%s [%s]", job.Document, job.ID),
+		DataType: job.DataType,
+		Data:     data,
+	}
+	job.Attempts++
+	m.mu.Unlock()
+
+	ref, err := m.backend.Submit(doc)
+
+	m.mu.Lock()
+	if job.State.Terminal() {
+		// 提交期间作业已被取消
+		m.mu.Unlock()
+		if err == nil {
+			if cancelErr := m.backend.CancelJob(doc.Printer, ref); cancelErr != nil {
+				log.Printf("[Job Manager] 取消已提交的作业 '%s' 失败: %v", job.ID, cancelErr)
+			}
+		}
+		return nil
+	}
+	defer m.mu.Unlock()
+	defer m.persist()
+
+	job.NextRetryAt = nil
+	if err == nil {
+		job.BackendRef = ref
+		job.LastError = ""
+		m.transition(job, backend.JobSpooling, "")
+		return nil
+	}
+
+	job.LastError = err.Error()
+	now := time.Now()
+	// 打印机不支持文档格式时重新提交也不会成功
+	if m.opts.RetryWindow > 0 && job.Spooled && !errors.Is(err, backend.ErrPDFNotSupported) {
+		if job.RetryDeadline == nil {
+			deadline := now.Add(m.opts.RetryWindow)
+			job.RetryDeadline = &deadline
+		}
+		if now.Before(*job.RetryDeadline) {
+			next := now.Add(m.backoff(job.Attempts))
+			if next.After(*job.RetryDeadline) {
+				next = *job.RetryDeadline
+			}
+			job.NextRetryAt = &next
+			m.transition(job, backend.JobRetrying,
+				fmt.Sprintf("第 %d 次提交失败，将于 %s 重试", job.Attempts, next.Format("15:04:05")))
+			log.Printf("[Job Manager] 作业 '%s' 提交失败，将于 %s 重试: %v", job.ID, next.Format(time.RFC3339), err)
+			return nil
+		}
+		m.finish(job, backend.JobFailed, fmt.Sprintf("重试 %d 次后仍提交失败: %v", job.Attempts, err))
+		return err
+	}
+	m.finish(job, backend.JobFailed, err.Error())
+	return err
+}
+
+// backoff 返回第 attempts 次失败后的等待时间
+func (m *Manager) backoff(attempts int) time.Duration {
+	d := m.opts.InitialBackoff
+	for i := 1; i < attempts && d < m.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, m.opts.MaxBackoff)
+}
+
+// Get 返回指定作业的快照
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return job.clone(), nil
+}
+
+// List 按创建时间倒序返回满足筛选条件的作业
+func (m *Manager) List(filter Filter) []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if filter.Printer != "" && job.Printer != filter.Printer {
+			continue
+		}
+		if len(filter.Printers) > 0 && !slices.Contains(filter.Printers, job.Printer) {
+			continue
+		}
+		if filter.State != "" && job.State != filter.State {
+			continue
+		}
+		if !filter.Since.IsZero() && job.CreatedAt.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && job.CreatedAt.After(filter.Until) {
+			continue
+		}
+		result = append(result, job.clone())
+	}
+	sortNewestFirst(result)
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result
+}
+
+// Cancel 取消一个尚未结束的作业
+func (m *Manager) Cancel(id string) (*Job, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	if job.State.Terminal() {
+		state := job.State
+		m.mu.Unlock()
+		return nil, fmt.Errorf("作业 '%s' 已处于 %s 状态，无法取消", id, state)
+	}
+	printer, ref := job.Printer, job.BackendRef
+	m.mu.Unlock()
+
+	if ref != "" {
+		if err := m.backend.CancelJob(printer, ref); err != nil && !errors.Is(err, backend.ErrJobNotFound) {
+			return nil, fmt.Errorf("取消作业 '%s' 失败: %w", id, err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.finish(job, backend.JobCancelled, "作业已被取消")
+	m.persist()
+	return job.clone(), nil
+}
+
+// Poll 向后端查询所有进行中作业的最新状态
+func (m *Manager) Poll() {
+	m.mu.Lock()
+	active := make([]*Job, 0)
+	for _, job := range m.jobs {
+		if job.BackendRef != "" && !job.State.Terminal() {
+			active = append(active, job.clone())
+		}
+	}
+	m.mu.Unlock()
+
+	if len(active) == 0 {
+		return
+	}
+
+	changed := false
+	for _, snapshot := range active {
+		status, err := m.backend.JobStatus(snapshot.Printer, snapshot.BackendRef)
+
+		m.mu.Lock()
+		job, ok := m.jobs[snapshot.ID]
+		if !ok || job.State.Terminal() {
+			m.mu.Unlock()
+			continue
+		}
+		switch {
+		case errors.Is(err, backend.ErrJobNotFound):
+			// 作业已离开打印队列，视为打印完成
+			m.finish(job, backend.JobCompleted, "")
+			changed = true
+		case err != nil:
+			log.Printf("[Job Manager] 查询作业 '%s' 状态失败: %v", job.ID, err)
+		default:
+			if m.apply(job, status) {
+				changed = true
+			}
+		}
+		m.mu.Unlock()
+	}
+
+	if changed {
+		m.mu.Lock()
+		m.persist()
+		m.mu.Unlock()
+	}
+}
+
+// apply 将后端状态合并到作业中，返回作业是否发生变化
+func (m *Manager) apply(job *Job, status *backend.JobStatus) bool {
+	if job.State == status.State && job.Message == status.Message &&
+		job.TotalPages == status.TotalPages && job.PagesPrinted == status.PagesPrinted {
+		return false
+	}
+	job.TotalPages = status.TotalPages
+	job.PagesPrinted = status.PagesPrinted
+	if status.State.Terminal() {
+		m.finish(job, status.State, status.Message)
+	} else {
+		m.transition(job, status.State, status.Message)
+	}
+	return true
+}
+
+func (m *Manager) transition(job *Job, state backend.JobState, message string) {
+	job.State = state
+	job.Message = message
+	job.UpdatedAt = time.Now()
+}
+
+func (m *Manager) finish(job *Job, state backend.JobState, message string) {
+	m.transition(job, state, message)
+	finishedAt := job.UpdatedAt
+	job.FinishedAt = &finishedAt
+	job.NextRetryAt = nil
+	// 失败的作业保留假脱机内容以便手动重试，其余结束状态不再需要
+	if state != backend.JobFailed {
+		m.removeSpool(job)
+	}
+	m.prune()
+}
+
+// prune 在已结束作业超过上限时删除最旧的记录，进行中的作业总是保留
+func (m *Manager) prune() {
+	finished := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if job.State.Terminal() {
+			finished = append(finished, job)
+		}
+	}
+	if len(finished) <= m.opts.HistoryLimit {
+		return
+	}
+	sortNewestFirst(finished)
+	for _, job := range finished[m.opts.HistoryLimit:] {
+		m.removeSpool(job)
+		delete(m.jobs, job.ID)
+	}
+}
+
+// load 从磁盘恢复历史记录
+func (m *Manager) load() error {
+	if m.opts.Dir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(m.opts.Dir, stateFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取作业历史失败: %w", err)
+	}
+
+	var state persistedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("解析作业历史失败: %w", err)
+	}
+	m.nextID = state.NextID
+	now := time.Now()
+	for _, job := range state.Jobs {
+		m.jobs[job.ID] = job
+		if job.State != backend.JobQueued && job.State != backend.JobRetrying {
+			continue
+		}
+		// 组件在提交完成前退出。保存了文档内容时继续重试 (若退出前后端已接收，可能重复打印)
+		if job.Spooled && m.opts.RetryWindow > 0 && (job.RetryDeadline == nil || now.Before(*job.RetryDeadline)) {
+			if job.RetryDeadline == nil {
+				deadline := now.Add(m.opts.RetryWindow)
+				job.RetryDeadline = &deadline
+			}
+			job.NextRetryAt = &now
+			m.transition(job, backend.JobRetrying, "组件重启后恢复重试")
+			continue
+		}
+		m.finish(job, backend.JobFailed, "组件重启时作业尚未提交")
+	}
+	m.prune()
+	return nil
+}
+
+// persist 将历史记录写入磁盘，调用方需持有锁
+func (m *Manager) persist() {
+	if m.opts.Dir == "" {
+		return
+	}
+	state := persistedState{NextID: m.nextID, Jobs: make([]*Job, 0, len(m.jobs))}
+	for _, job := range m.jobs {
+		state.Jobs = append(state.Jobs, job)
+	}
+	sortNewestFirst(state.Jobs)
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		log.Printf("[Job Manager] 序列化作业历史失败: %v", err)
+		return
+	}
+	if err := writeFileAtomic(filepath.Join(m.opts.Dir, stateFileName), data); err != nil {
+		log.Printf("[Job Manager] 保存作业历史失败: %v", err)
+	}
+}
+
+func (j *Job) clone() *Job {
+	c := *j
+	c.FinishedAt = cloneTime(j.FinishedAt)
+	c.NextRetryAt = cloneTime(j.NextRetryAt)
+	c.RetryDeadline = cloneTime(j.RetryDeadline)
+	return &c
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func sortNewestFirst(jobs []*Job) {
+	sort.Slice(jobs, func(i, k int) bool {
+		if jobs[i].CreatedAt.Equal(jobs[k].CreatedAt) {
+			return jobs[i].ID > jobs[k].ID
+		}
+		return jobs[i].CreatedAt.After(jobs[k].CreatedAt)
+	})
+}
+
+// writeFileAtomic 先写入临时文件再重命名，避免进程中断时留下损坏的文件
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
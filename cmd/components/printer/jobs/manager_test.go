@@ -0,0 +1,145 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+
+	"cse-go/cmd/components/printer/backend"
+)
+
+func newTestManager(t *testing.T, fake *backend.Fake, dir string) *Manager {
+	t.Helper()
+	m, err := NewManager(fake, Options{Dir: dir, HistoryLimit: 3})
+	if err != nil {
+		t.Fatalf("创建作业管理器失败: %v", err)
+	}
+	return m
+}
+
+// TestJobLifecycle 测试作业从提交到完成的状态流转
+func TestJobLifecycle(t *testing.T) {
+	fake := backend.NewFake("P1")
+	m := newTestManager(t, fake, "")
+
+	job, err := m.Submit(&backend.Document{Printer: "P1", Name: "doc", Data: []byte("hello")})
+	if err != nil {
+		t.Fatalf("提交作业失败: %v", err)
+	}
+	if job.State != backend.JobSpooling || job.Size != 5 {
+		t.Fatalf("提交后的作业状态不正确: %+v", job)
+	}
+	if docs := fake.Submitted(); len(docs) != 1 || docs[0].Name != "doc ["+job.ID+"]" {
+		t.Fatalf("后端收到的文档名不正确: %+v", docs)
+	}
+
+	fake.SetJobState(job.BackendRef, backend.JobPrinting)
+	m.Poll()
+	if got, _ := m.Get(job.ID); got.State != backend.JobPrinting {
+		t.Errorf("预期状态为 printing，实际为 %s", got.State)
+	}
+
+	// 作业离开队列后视为已完成
+	fake.RemoveJob(job.BackendRef)
+	m.Poll()
+	got, _ := m.Get(job.ID)
+	if got.State != backend.JobCompleted || got.FinishedAt == nil {
+		t.Errorf("预期作业已完成，实际为 %+v", got)
+	}
+}
+
+// TestSubmitFailure 测试后端提交失败时作业被记录为 failed
+func TestSubmitFailure(t *testing.T) {
+	fake := backend.NewFake("P1")
+	fake.SetSubmitError(errors.New("offline"))
+	m := newTestManager(t, fake, "")
+
+	job, err := m.Submit(&backend.Document{Printer: "P1", Name: "doc"})
+	if err == nil {
+		t.Fatal("预期提交失败")
+	}
+	if job.State != backend.JobFailed || job.Message != "offline" {
+		t.Errorf("失败作业的状态不正确: %+v", job)
+	}
+}
+
+// TestCancel 测试取消作业
+func TestCancel(t *testing.T) {
+	fake := backend.NewFake("P1")
+	m := newTestManager(t, fake, "")
+
+	job, _ := m.Submit(&backend.Document{Printer: "P1", Name: "doc"})
+	cancelled, err := m.Cancel(job.ID)
+	if err != nil {
+		t.Fatalf("取消作业失败: %v", err)
+	}
+	if cancelled.State != backend.JobCancelled {
+		t.Errorf("预期状态为 cancelled，实际为 %s", cancelled.State)
+	}
+	if _, err := m.Cancel(job.ID); err == nil {
+		t.Error("已取消的作业不应能再次取消")
+	}
+	if _, err := m.Cancel("job-unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("预期 ErrNotFound，实际为 %v", err)
+	}
+}
+
+// TestListFilterAndHistoryLimit 测试筛选与历史记录上限
+func TestListFilterAndHistoryLimit(t *testing.T) {
+	fake := backend.NewFake("P1", "P2")
+	m := newTestManager(t, fake, "")
+
+	for i := 0; i < 5; i++ {
+		printer := "P1"
+		if i%2 == 1 {
+			printer = "P2"
+		}
+		job, _ := m.Submit(&backend.Document{Printer: printer, Name: "doc"})
+		m.Cancel(job.ID)
+	}
+	active, _ := m.Submit(&backend.Document{Printer: "P1", Name: "active"})
+
+	all := m.List(Filter{})
+	if len(all) != 4 {
+		t.Fatalf("预期保留 3 个历史作业和 1 个进行中作业，实际为 %d", len(all))
+	}
+	if all[0].ID != active.ID {
+		t.Errorf("预期最新的作业排在最前，实际为 %s", all[0].ID)
+	}
+	if got := m.List(Filter{Printer: "P2"}); len(got) != 1 {
+		t.Errorf("预期 P2 有 1 个作业，实际为 %d", len(got))
+	}
+	if got := m.List(Filter{Printers: []string{"P2", "P3"}}); len(got) != 1 {
+		t.Errorf("预期 P2、P3 共有 1 个作业，实际为 %d", len(got))
+	}
+	if got := m.List(Filter{State: backend.JobCancelled}); len(got) != 3 {
+		t.Errorf("预期 3 个已取消作业，实际为 %d", len(got))
+	}
+	if got := m.List(Filter{Since: active.CreatedAt}); len(got) != 1 {
+		t.Errorf("按时间筛选的结果不正确: %d", len(got))
+	}
+}
+
+// TestPersistence 测试历史记录在重启后恢复
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+	fake := backend.NewFake("P1")
+
+	m := newTestManager(t, fake, dir)
+	first, _ := m.Submit(&backend.Document{Printer: "P1", Name: "doc"})
+	m.Cancel(first.ID)
+
+	restored := newTestManager(t, fake, dir)
+	got, err := restored.Get(first.ID)
+	if err != nil {
+		t.Fatalf("重启后未能恢复作业: %v", err)
+	}
+	if got.State != backend.JobCancelled {
+		t.Errorf("恢复的作业状态不正确: %s", got.State)
+	}
+
+	// 作业编号在重启后继续递增
+	second, _ := restored.Submit(&backend.Document{Printer: "P1", Name: "doc"})
+	if second.ID == first.ID {
+		t.Errorf("重启后作业 ID 重复: %s", second.ID)
+	}
+}
@@ -0,0 +1,219 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cse-go/cmd/components/printer/backend"
+)
+
+// spoolExt 是假脱机文件的扩展名
+const spoolExt = ".prn"
+
+// PurgeResult 是清理假脱机目录的结果
+type PurgeResult struct {
+	Jobs  []string `json:"jobs"`
+	Files int      `json:"files"`
+	Bytes int64    `json:"bytes"`
+}
+
+func (m *Manager) spoolPath(id string) string {
+	return filepath.Join(m.opts.SpoolDir, id+spoolExt)
+}
+
+// writeSpool 将文档内容写入假脱机目录，返回是否成功
+func (m *Manager) writeSpool(id string, data []byte) bool {
+	if m.opts.SpoolDir == "" {
+		return false
+	}
+	if err := writeFileAtomic(m.spoolPath(id), data); err != nil {
+		log.Printf("[Job Manager] 写入假脱机文件失败，作业 '%s' 将无法重试: %v", id, err)
+		return false
+	}
+	return true
+}
+
+func (m *Manager) readSpool(id string) ([]byte, error) {
+	data, err := os.ReadFile(m.spoolPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("读取假脱机文件失败: %w", err)
+	}
+	return data, nil
+}
+
+// removeSpool 删除作业的假脱机文件，调用方需持有锁
+func (m *Manager) removeSpool(job *Job) int64 {
+	if !job.Spooled {
+		return 0
+	}
+	job.Spooled = false
+	path := m.spoolPath(job.ID)
+	var size int64
+	if fi, err := os.Stat(path); err == nil {
+		size = fi.Size()
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("[Job Manager] 删除假脱机文件失败: %v", err)
+	}
+	return size
+}
+
+// RetryDue 重新提交已到重试时间的作业
+func (m *Manager) RetryDue() {
+	now := time.Now()
+	m.mu.Lock()
+	var due []*Job
+	for _, job := range m.jobs {
+		if job.State == backend.JobRetrying && job.NextRetryAt != nil && !job.NextRetryAt.After(now) {
+			// 提交期间作业处于 queued 状态，不会被再次调度、手动重试或清除
+			job.NextRetryAt = nil
+			m.transition(job, backend.JobQueued, fmt.Sprintf("第 %d 次重试", job.Attempts))
+			due = append(due, job)
+		}
+	}
+	if len(due) > 0 {
+		m.persist()
+	}
+	m.mu.Unlock()
+
+	for _, job := range due {
+		err := m.resubmit(job)
+		if m.opts.OnRetry != nil {
+			m.mu.Lock()
+			snapshot := job.clone()
+			m.mu.Unlock()
+			m.opts.OnRetry(snapshot, err)
+		}
+	}
+}
+
+// Retry 立即重新提交失败或等待重试的作业，并开始新的重试窗口
+func (m *Manager) Retry(id string) (*Job, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	if job.State != backend.JobFailed && job.State != backend.JobRetrying {
+		state := job.State
+		m.mu.Unlock()
+		return nil, fmt.Errorf("作业 '%s' 处于 %s 状态，只有失败或等待重试的作业可以重试", id, state)
+	}
+	if !job.Spooled {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("作业 '%s' 的文档内容未保存在假脱机目录中，无法重试", id)
+	}
+	job.BackendRef = ""
+	job.Attempts = 0
+	job.TotalPages = 0
+	job.PagesPrinted = 0
+	job.FinishedAt = nil
+	job.NextRetryAt = nil
+	job.RetryDeadline = nil
+	m.transition(job, backend.JobQueued, "手动重试")
+	m.persist()
+	m.mu.Unlock()
+
+	err := m.resubmit(job)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return job.clone(), err
+}
+
+// resubmit 从假脱机目录读取文档内容并重新提交
+func (m *Manager) resubmit(job *Job) error {
+	data, err := m.readSpool(job.ID)
+	if err != nil {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if !job.State.Terminal() {
+			job.Spooled = false
+			m.finish(job, backend.JobFailed, err.Error())
+			m.persist()
+		}
+		return err
+	}
+	return m.attempt(job, data)
+}
+
+// PurgeSpool 删除假脱机目录中的文档内容。id 为空时清理所有已结束的作业以及不属于任何作业的文件；
+// includePending 为 true 时同时放弃等待重试的作业，将其标记为失败。正在重新提交的作业不会被清除
+func (m *Manager) PurgeSpool(id string, includePending bool) (*PurgeResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := &PurgeResult{Jobs: []string{}}
+	purge := func(job *Job) {
+		if job.State == backend.JobRetrying {
+			m.finish(job, backend.JobFailed, "已从假脱机目录清除，不再重试")
+		}
+		result.Bytes += m.removeSpool(job)
+		result.Files++
+		result.Jobs = append(result.Jobs, job.ID)
+	}
+	purgeable := func(job *Job) bool {
+		return job.Spooled && (job.State.Terminal() || includePending && job.State == backend.JobRetrying)
+	}
+
+	if id != "" {
+		job, ok := m.jobs[id]
+		if !ok {
+			return nil, ErrNotFound
+		}
+		if !job.Spooled {
+			return nil, fmt.Errorf("作业 '%s' 没有假脱机内容", id)
+		}
+		if !purgeable(job) {
+			return nil, fmt.Errorf("作业 '%s' 处于 %s 状态，不能清除其假脱机内容", id, job.State)
+		}
+		purge(job)
+		m.persist()
+		return result, nil
+	}
+
+	for _, job := range m.jobs {
+		if purgeable(job) {
+			purge(job)
+		}
+	}
+	m.persist()
+
+	// 清理不属于任何作业的残留文件
+	if m.opts.SpoolDir == "" {
+		return result, nil
+	}
+	entries, err := os.ReadDir(m.opts.SpoolDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("读取假脱机目录失败: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, spoolExt) {
+			continue
+		}
+		if job, ok := m.jobs[strings.TrimSuffix(name, spoolExt)]; ok && job.Spooled {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(filepath.Join(m.opts.SpoolDir, name)); err != nil {
+			log.Printf("[Job Manager] 删除假脱机文件失败: %v", err)
+			continue
+		}
+		result.Files++
+		result.Bytes += info.Size()
+	}
+	return result, nil
+}
@@ -0,0 +1,266 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cse-go/cmd/components/printer/backend"
+)
+
+func newSpoolManager(t *testing.T, fake *backend.Fake, dir string, window time.Duration) *Manager {
+	t.Helper()
+	m, err := NewManager(fake, Options{
+		Dir:            dir,
+		SpoolDir:       filepath.Join(dir, "spool"),
+		RetryWindow:    window,
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("创建作业管理器失败: %v", err)
+	}
+	return m
+}
+
+// makeDue 将等待重试的作业调整为立即到期
+func makeDue(m *Manager, id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	past := time.Now().Add(-time.Millisecond)
+	m.jobs[id].NextRetryAt = &past
+}
+
+// TestRetryAfterTransientFailure 测试提交失败后进入重试并在打印机恢复后提交成功
+func TestRetryAfterTransientFailure(t *testing.T) {
+	dir := t.TempDir()
+	fake := backend.NewFake("P1")
+	fake.SetSubmitError(errors.New("offline"))
+	m := newSpoolManager(t, fake, dir, time.Minute)
+
+	job, err := m.Submit(&backend.Document{Printer: "P1", Name: "doc", DataType: "RAW", Data: []byte("hello")})
+	if err != nil {
+		t.Fatalf("启用重试时提交失败不应返回错误: %v", err)
+	}
+	if job.State != backend.JobRetrying || job.Attempts != 1 || job.LastError != "offline" || !job.Spooled {
+		t.Fatalf("重试作业的状态不正确: %+v", job)
+	}
+	if job.NextRetryAt == nil || job.RetryDeadline == nil || job.NextRetryAt.Sub(job.UpdatedAt) > 2*time.Second {
+		t.Fatalf("重试时间不正确: %+v", job)
+	}
+	spoolFile := filepath.Join(dir, "spool", job.ID+".prn")
+	if data, err := os.ReadFile(spoolFile); err != nil || string(data) != "hello" {
+		t.Fatalf("假脱机文件内容不正确: %q (%v)", data, err)
+	}
+
+	// 未到重试时间时不提交
+	fake.SetSubmitError(nil)
+	m.RetryDue()
+	if len(fake.Submitted()) != 0 {
+		t.Fatal("未到重试时间不应提交")
+	}
+
+	var retried []*Job
+	m.opts.OnRetry = func(job *Job, err error) {
+		retried = append(retried, job)
+	}
+	makeDue(m, job.ID)
+	m.RetryDue()
+	got, _ := m.Get(job.ID)
+	if got.State != backend.JobSpooling || got.Attempts != 2 || got.LastError != "" || got.NextRetryAt != nil {
+		t.Fatalf("重试成功后的作业状态不正确: %+v", got)
+	}
+	if len(retried) != 1 || retried[0].ID != job.ID || retried[0].State != backend.JobSpooling {
+		t.Errorf("自动重试后应报告重试结果: %+v", retried)
+	}
+	if docs := fake.Submitted(); len(docs) != 1 || string(docs[0].Data) != "hello" || docs[0].DataType != "RAW" {
+		t.Fatalf("重新提交的文档不正确: %+v", docs)
+	}
+
+	// 作业完成后删除假脱机文件
+	fake.RemoveJob(got.BackendRef)
+	m.Poll()
+	if _, err := os.Stat(spoolFile); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("作业完成后假脱机文件应被删除: %v", err)
+	}
+}
+
+// TestRetryWindowExpires 测试超过重试窗口后作业失败，并可手动重试
+func TestRetryWindowExpires(t *testing.T) {
+	fake := backend.NewFake("P1")
+	fake.SetSubmitError(errors.New("offline"))
+	m := newSpoolManager(t, fake, t.TempDir(), time.Minute)
+
+	job, _ := m.Submit(&backend.Document{Printer: "P1", Name: "doc", Data: []byte("x")})
+	m.mu.Lock()
+	past := time.Now().Add(-time.Second)
+	m.jobs[job.ID].RetryDeadline = &past
+	m.mu.Unlock()
+	makeDue(m, job.ID)
+	m.RetryDue()
+
+	got, _ := m.Get(job.ID)
+	if got.State != backend.JobFailed || !strings.Contains(got.Message, "重试 2 次") || !got.Spooled {
+		t.Fatalf("超过重试窗口后的作业状态不正确: %+v", got)
+	}
+
+	fake.SetSubmitError(nil)
+	retried, err := m.Retry(job.ID)
+	if err != nil {
+		t.Fatalf("手动重试失败: %v", err)
+	}
+	if retried.State != backend.JobSpooling || retried.Attempts != 1 || retried.FinishedAt != nil {
+		t.Errorf("手动重试后的作业状态不正确: %+v", retried)
+	}
+	if _, err := m.Retry(job.ID); err == nil {
+		t.Error("进行中的作业不应能重试")
+	}
+}
+
+// blockingBackend 在提交时通知 started 并等待 release，用于在提交期间检查作业
+type blockingBackend struct {
+	*backend.Fake
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingBackend) Submit(doc *backend.Document) (string, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return b.Fake.Submit(doc)
+}
+
+// TestRetryDueInFlight 测试自动重新提交期间作业不能被手动重试或清除
+func TestRetryDueInFlight(t *testing.T) {
+	fake := backend.NewFake("P1")
+	fake.SetSubmitError(errors.New("offline"))
+	m := newSpoolManager(t, fake, t.TempDir(), time.Minute)
+	job, _ := m.Submit(&backend.Document{Printer: "P1", Name: "doc", Data: []byte("hello")})
+
+	fake.SetSubmitError(nil)
+	blocking := &blockingBackend{Fake: fake, started: make(chan struct{}), release: make(chan struct{})}
+	m.backend = blocking
+	makeDue(m, job.ID)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.RetryDue()
+	}()
+	<-blocking.started
+
+	if got, _ := m.Get(job.ID); got.State != backend.JobQueued {
+		t.Errorf("提交期间作业应处于 queued 状态: %+v", got)
+	}
+	if _, err := m.Retry(job.ID); err == nil {
+		t.Error("提交期间的作业不应能手动重试")
+	}
+	if result, err := m.PurgeSpool("", true); err != nil || len(result.Jobs) != 0 {
+		t.Errorf("提交期间的作业不应被清除: %+v (%v)", result, err)
+	}
+	close(blocking.release)
+	<-done
+
+	if docs := fake.Submitted(); len(docs) != 1 {
+		t.Errorf("作业应只提交一次，实际 %d 次", len(docs))
+	}
+	if got, _ := m.Get(job.ID); got.State != backend.JobSpooling || !got.Spooled {
+		t.Errorf("重新提交后的作业状态不正确: %+v", got)
+	}
+}
+
+// TestUnsupportedDocumentNotRetried 测试打印机不支持文档格式时作业直接失败
+func TestUnsupportedDocumentNotRetried(t *testing.T) {
+	fake := backend.NewFake("P1")
+	fake.SetSubmitError(fmt.Errorf("%w: 'P1'", backend.ErrPDFNotSupported))
+	m := newSpoolManager(t, fake, t.TempDir(), time.Minute)
+
+	job, err := m.Submit(&backend.Document{Printer: "P1", Name: "doc", DataType: backend.DataTypePDF, Data: []byte("%PDF")})
+	if !errors.Is(err, backend.ErrPDFNotSupported) || job.State != backend.JobFailed || job.NextRetryAt != nil {
+		t.Errorf("不支持的文档不应进入重试: %+v (%v)", job, err)
+	}
+}
+
+// TestBackoff 测试重试间隔按指数增长并有上限
+func TestBackoff(t *testing.T) {
+	m := newSpoolManager(t, backend.NewFake(), t.TempDir(), time.Minute)
+	var got []time.Duration
+	for attempts := 1; attempts <= 5; attempts++ {
+		got = append(got, m.backoff(attempts))
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("重试间隔为 %v，期望 %v", got, want)
+		}
+	}
+}
+
+// TestPurgeSpool 测试清理假脱机目录
+func TestPurgeSpool(t *testing.T) {
+	dir := t.TempDir()
+	fake := backend.NewFake("P1")
+	fake.SetSubmitError(errors.New("offline"))
+	m := newSpoolManager(t, fake, dir, time.Minute)
+
+	pending, _ := m.Submit(&backend.Document{Printer: "P1", Name: "pending", Data: []byte("12345")})
+	// 关闭自动重试后提交，得到保留了假脱机内容的失败作业
+	m.opts.RetryWindow = 0
+	failed, err := m.Submit(&backend.Document{Printer: "P1", Name: "failed", Data: []byte("123")})
+	if err == nil || failed.State != backend.JobFailed || !failed.Spooled {
+		t.Fatalf("失败作业应保留假脱机内容: %+v (%v)", failed, err)
+	}
+	os.WriteFile(filepath.Join(dir, "spool", "orphan.prn"), []byte("1"), 0o644)
+
+	if _, err := m.PurgeSpool(pending.ID, false); err == nil {
+		t.Error("不应清除等待重试的作业")
+	}
+	result, err := m.PurgeSpool("", false)
+	if err != nil {
+		t.Fatalf("清理失败: %v", err)
+	}
+	if len(result.Jobs) != 1 || result.Jobs[0] != failed.ID || result.Files != 2 || result.Bytes != 4 {
+		t.Errorf("清理结果不正确: %+v", result)
+	}
+	if _, err := m.Retry(failed.ID); err == nil {
+		t.Error("清除假脱机内容后不应能重试")
+	}
+
+	result, err = m.PurgeSpool("", true)
+	if err != nil || len(result.Jobs) != 1 || result.Bytes != 5 {
+		t.Fatalf("清理等待重试的作业失败: %+v (%v)", result, err)
+	}
+	if got, _ := m.Get(pending.ID); got.State != backend.JobFailed || got.Spooled {
+		t.Errorf("被清除的重试作业应标记为失败: %+v", got)
+	}
+}
+
+// TestResumeRetryAfterRestart 测试重启后继续重试假脱机中的作业
+func TestResumeRetryAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	fake := backend.NewFake("P1")
+	fake.SetSubmitError(errors.New("offline"))
+	m := newSpoolManager(t, fake, dir, time.Minute)
+	job, _ := m.Submit(&backend.Document{Printer: "P1", Name: "doc", Data: []byte("hello")})
+
+	fake.SetSubmitError(nil)
+	restored := newSpoolManager(t, fake, dir, time.Minute)
+	if got, _ := restored.Get(job.ID); got.State != backend.JobRetrying || got.NextRetryAt == nil {
+		t.Fatalf("重启后作业应等待重试: %+v", got)
+	}
+	restored.RetryDue()
+	if got, _ := restored.Get(job.ID); got.State != backend.JobSpooling {
+		t.Errorf("重启后重试应提交成功: %+v", got)
+	}
+
+	// 未启用重试时，重启后未提交的作业标记为失败
+	fake.SetSubmitError(errors.New("offline"))
+	pending, _ := restored.Submit(&backend.Document{Printer: "P1", Name: "doc", Data: []byte("x")})
+	noRetry := newSpoolManager(t, fake, dir, 0)
+	if got, _ := noRetry.Get(pending.ID); got.State != backend.JobFailed || !got.Spooled {
+		t.Errorf("未启用重试时作业应失败并保留假脱机内容: %+v", got)
+	}
+}
@@ -0,0 +1,90 @@
+package labels
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// templateExt 是模板文件的扩展名
+const templateExt = ".zpl"
+
+// ErrTemplateNotFound 表示模板不存在
+var ErrTemplateNotFound = errors.New("标签模板不存在")
+
+// TemplateInfo 是模板的摘要信息
+type TemplateInfo struct {
+	Name       string    `json:"name"`
+	Fields     []string  `json:"fields"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
+
+// Store 从目录中读取 <name>.zpl 模板文件
+type Store struct {
+	dir string
+}
+
+// NewStore 创建模板仓库
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir 返回模板目录
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// List 列出所有模板，目录不存在时返回空列表
+func (s *Store) List() ([]*TemplateInfo, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*TemplateInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取模板目录 '%s': %w", s.dir, err)
+	}
+
+	infos := make([]*TemplateInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != templateExt {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), templateExt)
+		t, err := s.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, &TemplateInfo{
+			Name:       name,
+			Fields:     t.Fields(),
+			Size:       fi.Size(),
+			ModifiedAt: fi.ModTime(),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// Load 读取并解析指定名称的模板
+func (s *Store) Load(name string) (*Template, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, fmt.Errorf("非法的模板名称 '%s'", name)
+	}
+	source, err := os.ReadFile(filepath.Join(s.dir, name+templateExt))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取模板 '%s': %w", name, err)
+	}
+	return Parse(name, string(source))
+}
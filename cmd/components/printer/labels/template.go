@@ -0,0 +1,141 @@
+// Package labels 管理 ZPL 标签模板并根据数据生成标签
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 占位符的转义方式
+const (
+	// EscapeAuto 根据所在字段是否声明了 ^FH 自动选择 hex 或 strip
+	EscapeAuto = ""
+	// EscapeHex 以 ^FH 的十六进制形式转义 ^、~ 与 _
+	EscapeHex = "hex"
+	// EscapeStrip 删除值中的 ^ 与 ~，防止注入 ZPL 指令
+	EscapeStrip = "strip"
+	// EscapeRaw 原样输出，用于插入可信的 ZPL 片段
+	EscapeRaw = "raw"
+)
+
+// placeholderPattern 匹配 {{field}} 或 {{field|escape}}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*(?:\|\s*([a-z]+)\s*)?\}\}`)
+
+// Template 是解析后的 ZPL 模板
+type Template struct {
+	Name     string
+	segments []segment
+	fields   []string
+}
+
+// segment 是模板中的一段字面文本或一个占位符
+type segment struct {
+	literal string
+	field   string
+	escape  string
+}
+
+// Parse 解析模板源码
+func Parse(name, source string) (*Template, error) {
+	t := &Template{Name: name}
+	seen := make(map[string]bool)
+	last := 0
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(source, -1) {
+		field := source[m[2]:m[3]]
+		escape := ""
+		if m[4] >= 0 {
+			escape = source[m[4]:m[5]]
+		}
+		switch escape {
+		case EscapeHex, EscapeStrip, EscapeRaw:
+		case EscapeAuto:
+			escape = autoEscape(source[:m[0]])
+		default:
+			return nil, fmt.Errorf("模板 '%s' 中字段 '%s' 的转义方式 '%s' 不受支持", name, field, escape)
+		}
+
+		t.segments = append(t.segments, segment{literal: source[last:m[0]]}, segment{field: field, escape: escape})
+		if !seen[field] {
+			seen[field] = true
+			t.fields = append(t.fields, field)
+		}
+		last = m[1]
+	}
+	t.segments = append(t.segments, segment{literal: source[last:]})
+	return t, nil
+}
+
+// autoEscape 检查占位符所在字段 (上一个 ^FS 之后) 是否声明了 ^FH
+func autoEscape(before string) string {
+	fieldStart := strings.LastIndex(before, "^FS")
+	if strings.Contains(before[fieldStart+1:], "^FH") {
+		return EscapeHex
+	}
+	return EscapeStrip
+}
+
+// Fields 按首次出现的顺序返回模板中的字段名
+func (t *Template) Fields() []string {
+	return append([]string(nil), t.fields...)
+}
+
+// Missing 返回数据中缺失 (或为 null) 的字段
+func (t *Template) Missing(data map[string]any) []string {
+	var missing []string
+	for _, field := range t.fields {
+		if v, ok := data[field]; !ok || v == nil {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// Render 使用数据填充模板，缺少字段时返回错误
+func (t *Template) Render(data map[string]any) (string, error) {
+	if missing := t.Missing(data); len(missing) > 0 {
+		return "", fmt.Errorf("缺少字段: %s", strings.Join(missing, ", "))
+	}
+	var sb strings.Builder
+	for _, seg := range t.segments {
+		if seg.field == "" {
+			sb.WriteString(seg.literal)
+			continue
+		}
+		sb.WriteString(escapeValue(formatValue(data[seg.field]), seg.escape))
+	}
+	return sb.String(), nil
+}
+
+// formatValue 将 JSON 值转换为字符串
+func formatValue(v any) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func escapeValue(value, escape string) string {
+	switch escape {
+	case EscapeHex:
+		var sb strings.Builder
+		for i := 0; i < len(value); i++ {
+			switch c := value[i]; c {
+			case '^', '~', '_':
+				fmt.Fprintf(&sb, "_%02X", c)
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return sb.String()
+	case EscapeStrip:
+		return strings.NewReplacer("^", "", "~", "").Replace(value)
+	default:
+		return value
+	}
+}
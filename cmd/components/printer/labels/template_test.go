@@ -0,0 +1,88 @@
+package labels
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const shippingTemplate = `^XA
+^FO50,50^A0N,40,40^FD{{name}}^FS
+^FO50,100^FH^FD{{address}}^FS
+^FO50,150^BCN,80^FD{{ tracking }}^FS
+^FO50,250^FD{{name|raw}}^FS
+^XZ
+`
+
+// TestRenderEscaping 测试各字段的转义方式
+func TestRenderEscaping(t *testing.T) {
+	tpl, err := Parse("shipping", shippingTemplate)
+	if err != nil {
+		t.Fatalf("解析模板失败: %v", err)
+	}
+	if got := tpl.Fields(); !reflect.DeepEqual(got, []string{"name", "address", "tracking"}) {
+		t.Errorf("字段列表不正确: %v", got)
+	}
+
+	out, err := tpl.Render(map[string]any{
+		"name":     "A^B~C",
+		"address":  "1_2^3",
+		"tracking": float64(123456),
+	})
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	want := `^XA
+^FO50,50^A0N,40,40^FDABC^FS
+^FO50,100^FH^FD1_5F2_5E3^FS
+^FO50,150^BCN,80^FD123456^FS
+^FO50,250^FDA^B~C^FS
+^XZ
+`
+	if out != want {
+		t.Errorf("渲染结果不正确:\n%s", out)
+	}
+}
+
+// TestMissingFields 测试缺失字段的检测
+func TestMissingFields(t *testing.T) {
+	tpl, _ := Parse("shipping", shippingTemplate)
+	missing := tpl.Missing(map[string]any{"name": "x", "address": nil})
+	if !reflect.DeepEqual(missing, []string{"address", "tracking"}) {
+		t.Errorf("缺失字段不正确: %v", missing)
+	}
+	if _, err := tpl.Render(map[string]any{}); err == nil {
+		t.Error("缺少字段时渲染应当失败")
+	}
+}
+
+// TestParseInvalidEscape 测试不支持的转义方式
+func TestParseInvalidEscape(t *testing.T) {
+	if _, err := Parse("bad", "^FD{{name|upper}}^FS"); err == nil {
+		t.Error("预期解析失败")
+	}
+}
+
+// TestStore 测试模板目录的读取
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "shipping.zpl"), []byte(shippingTemplate), 0o644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644)
+	store := NewStore(dir)
+
+	infos, err := store.List()
+	if err != nil {
+		t.Fatalf("列出模板失败: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Name != "shipping" || len(infos[0].Fields) != 3 {
+		t.Errorf("模板列表不正确: %+v", infos)
+	}
+	if _, err := store.Load("missing"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("预期 ErrTemplateNotFound，实际为 %v", err)
+	}
+	if _, err := store.Load("../shipping"); err == nil {
+		t.Error("应当拒绝包含路径分隔符的模板名称")
+	}
+}
@@ -5,17 +5,32 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
 	"os"
+	"path/filepath"
+	"sync/atomic"
 	"time"
 
+	"cse-go/cmd/components/printer/audit"
+	"cse-go/cmd/components/printer/backend"
 	"cse-go/cmd/components/printer/commands"
+	"cse-go/cmd/components/printer/config"
+	"cse-go/cmd/components/printer/document"
+	"cse-go/cmd/components/printer/jobs"
+	"cse-go/cmd/components/printer/labels"
+	"cse-go/cmd/components/printer/monitor"
+	"cse-go/cmd/components/printer/policy"
+	"cse-go/cmd/components/printer/pools"
 
 	"cse-go/internal/commandbus"
+	"cse-go/internal/mtls"
+	"cse-go/internal/tracing"
+	"cse-go/internal/transport"
 	pb "cse-go/pkg/api/v1"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials"
 )
 
 // printerServer 实现了 ComponentService
@@ -24,52 +39,139 @@ type printerServer struct {
 	grpcServer *grpc.Server
 	// [已更新] map 的 value 类型现在是新的共享接口
 	commandMap map[string]commandbus.Command
+	// options 为命令行参数与配置文件中的配置，settings 为 Supervisor 推送的设置
+	options  serviceOptions
+	settings *commandbus.Settings[config.Settings]
+	// services 在首次收到有效的设置后创建
+	services atomic.Pointer[commands.Services]
 }
 
-// NewPrinterServer 创建一个新的 printerServer 实例并注册所有命令
-func NewPrinterServer(grpcServer *grpc.Server) *printerServer {
+// serviceOptions 是创建服务所需的命令行参数与配置文件内容
+type serviceOptions struct {
+	backendName string
+	dataDir     string
+	fontPath    string
+	cfg         *config.Config
+	policy      *policy.Policy
+}
+
+// NewPrinterServer 创建一个新的 printerServer 实例并注册所有命令。
+// 服务在 Supervisor 首次推送设置 (Configure) 后创建，之前收到的命令返回错误
+func NewPrinterServer(grpcServer *grpc.Server, options serviceOptions) *printerServer {
 	s := &printerServer{
 		grpcServer: grpcServer,
 		commandMap: make(map[string]commandbus.Command),
+		options:    options,
 	}
+	s.settings = commandbus.NewSettings(s.applySettings)
 	s.registerCommands()
 	return s
 }
 
+// Configure 应用 Supervisor 推送的设置
+func (s *printerServer) Configure(ctx context.Context, req *pb.ConfigureRequest) (*pb.ConfigureResponse, error) {
+	return s.settings.Configure(ctx, req)
+}
+
+// applySettings 首次收到设置时创建服务，之后重新加载设置时更新逻辑打印机定义
+func (s *printerServer) applySettings(settings *config.Settings) error {
+	printers := settings.Printers
+	if printers == nil {
+		printers = s.options.cfg.Printers
+	}
+	backendName := settings.Backend
+	if backendName == "" {
+		backendName = s.options.backendName
+	}
+
+	if services := s.services.Load(); services != nil {
+		if backendName != "" && backendName != services.Backend.Name() {
+			slog.Warn("打印后端的修改需要重启组件才能生效", "backend", services.Backend.Name(), "configured", backendName)
+		}
+		if err := services.Printers.Update(printers); err != nil {
+			return fmt.Errorf("逻辑打印机配置错误: %w", err)
+		}
+		slog.Info("已重新加载设置", "printers", len(printers))
+		return nil
+	}
+
+	b, err := backend.New(backendName, backend.Options{PDFPrinters: s.options.cfg.PDFPrinters})
+	if err != nil {
+		return fmt.Errorf("无法创建打印后端: %w", err)
+	}
+	cfg := *s.options.cfg
+	cfg.Printers = printers
+	services, err := setupServices(b, s.options.dataDir, s.options.fontPath, &cfg, s.options.policy)
+	if err != nil {
+		return err
+	}
+	registerJobMetrics(services.Jobs)
+	s.services.Store(services)
+	return nil
+}
+
 // registerCommands 初始化并注册所有支持的命令
 func (s *printerServer) registerCommands() {
 	// [自动注册] 从全局注册器获取所有已注册的命令
 	autoCommands := commands.GlobalRegistry.GetCommands()
 	for name, cmd := range autoCommands {
 		s.commandMap[name] = cmd
-		log.Printf("[Printer Component] 命令 '%s' 已自动加载到组件中", name)
+		slog.Debug("命令已自动加载到组件中", "command", name)
 	}
 
-	log.Printf("[Printer Component] 共加载了 %d 个命令", len(s.commandMap))
+	slog.Info("命令加载完成", "commands", len(s.commandMap))
 }
 
 // ExecuteCommand 从注册表中查找并执行命令
 func (s *printerServer) ExecuteCommand(ctx context.Context, req *pb.ExecuteCommandRequest) (*pb.ExecuteCommandResponse, error) {
 	commandName := req.GetCommandName()
-	log.Printf("[Printer Component] 收到命令执行请求: '%s'", commandName)
+	// 沿用 Supervisor 传递的请求 ID 与 span，命令通过 ctx 读取
+	ctx, span := tracing.Start(tracing.Extract(ctx), commandName, tracing.KindServer)
+	defer span.End()
+	span.SetAttribute("cse.command", commandName)
+	slog.DebugContext(ctx, "收到命令执行请求", "command", commandName, "caller", commandbus.CallerFromContext(ctx).User)
+
+	if s.services.Load() == nil {
+		errMsg := "组件尚未完成配置"
+		span.SetError(errMsg)
+		return &pb.ExecuteCommandResponse{Success: false, ErrorMessage: errMsg}, nil
+	}
 
 	cmd, ok := s.commandMap[commandName]
 	if !ok {
 		errMsg := fmt.Sprintf("命令 '%s' 未找到或不受支持。", commandName)
+		span.SetError(errMsg)
 		return &pb.ExecuteCommandResponse{Success: false, ErrorMessage: errMsg}, nil
 	}
 
-	result, err := cmd.Execute(req.GetParams())
+	if denied := commands.Authorize(ctx, commandName, req.GetParams()); denied != nil {
+		commandsTotal.Inc(commandName, "denied")
+		span.SetError("denied")
+		slog.WarnContext(ctx, "命令被访问策略拒绝", "command", commandName)
+		return &pb.ExecuteCommandResponse{Success: true, Result: denied}, nil
+	}
+
+	var result *pb.CommandResult
+	var err error
+	if contextCmd, ok := cmd.(commandbus.ContextCommand); ok {
+		result, err = contextCmd.ExecuteContext(ctx, req.GetParams())
+	} else {
+		result, err = cmd.Execute(req.GetParams())
+	}
 	if err != nil {
+		commandsTotal.Inc(commandName, "error")
+		span.SetError(err.Error())
+		slog.WarnContext(ctx, "命令执行失败", "command", commandName, "error", err)
 		return &pb.ExecuteCommandResponse{Success: false, ErrorMessage: err.Error()}, nil
 	}
 
+	commandsTotal.Inc(commandName, "ok")
 	return &pb.ExecuteCommandResponse{Success: true, Result: result}, nil
 }
 
 // GetMetadata 从注册表中动态生成命令列表
 func (s *printerServer) GetMetadata(ctx context.Context, req *pb.GetMetadataRequest) (*pb.ComponentMetadata, error) {
-	log.Println("[Printer Component] Supervisor 调用了 GetMetadata 方法")
+	slog.Debug("Supervisor 调用了 GetMetadata 方法")
 
 	providedCmds := make([]*pb.CommandInfo, 0, len(s.commandMap))
 	for _, cmd := range s.commandMap {
@@ -88,34 +190,129 @@ func (s *printerServer) GetMetadata(ctx context.Context, req *pb.GetMetadataRequ
 // ... GetStatus, Shutdown, startMyService, registerToSupervisor, 和 main 函数保持不变 ...
 
 func (s *printerServer) GetStatus(ctx context.Context, req *pb.GetStatusRequest) (*pb.GetStatusResponse, error) {
-	return &pb.GetStatusResponse{CurrentState: pb.ComponentState_RUNNING, Message: "打印组件正在运行"}, nil
+	state, message := s.settings.Status()
+	if state == pb.ComponentState_RUNNING {
+		message = "打印组件正在运行"
+	}
+	return &pb.GetStatusResponse{CurrentState: state, Message: message}, nil
 }
 
 func (s *printerServer) Shutdown(ctx context.Context, req *pb.ShutdownRequest) (*pb.ShutdownResponse, error) {
-	log.Println("[Printer Component] Supervisor 调用了 Shutdown 方法...")
-	
+	slog.Info("Supervisor 调用了 Shutdown 方法")
+
 	// 先返回响应，确保supervisor收到确认
 	response := &pb.ShutdownResponse{Acknowledged: true, Message: "Shutdown request received."}
-	
+
 	// 延迟关闭，给响应足够时间发送
 	go func() {
 		time.Sleep(100 * time.Millisecond) // 缩短延迟，确保响应能发送
-		log.Println("[Printer Component] 正在优雅关闭gRPC服务器...")
+		slog.Info("正在优雅关闭 gRPC 服务器")
 		s.grpcServer.GracefulStop()
-		log.Println("[Printer Component] 组件已关闭")
+		if services := s.services.Load(); services != nil {
+			services.Monitor.Stop()
+			services.Jobs.Stop()
+			services.Audit.Close()
+		}
+		slog.Info("组件已关闭")
 		os.Exit(0)
 	}()
-	
+
 	return response, nil
 }
 
-func startMyService() (net.Listener, *grpc.Server) {
-	lis, err := net.Listen("tcp", ":0")
+// setupServices 在打印后端上创建作业管理器与打印机监视器，并注入到命令包中。
+// 所有服务创建成功后才启动后台协程，失败时不留下运行中的服务
+func setupServices(b backend.Backend, dataDir, fontPath string, cfg *config.Config, accessPolicy *policy.Policy) (*commands.Services, error) {
+	slog.Info("使用打印后端", "backend", b.Name())
+
+	resolver, err := pools.NewResolver(b, cfg.Printers)
+	if err != nil {
+		return nil, fmt.Errorf("逻辑打印机配置错误: %w", err)
+	}
+	for _, pool := range resolver.List() {
+		slog.Info("逻辑打印机", "name", pool.Name, "members", pool.Members, "strategy", pool.Strategy)
+	}
+
+	jobManager, err := jobs.NewManager(b, jobs.Options{
+		Dir:            dataDir,
+		SpoolDir:       filepath.Join(dataDir, "spool"),
+		RetryWindow:    time.Duration(cfg.Spool.RetryWindow),
+		InitialBackoff: time.Duration(cfg.Spool.InitialBackoff),
+		MaxBackoff:     time.Duration(cfg.Spool.MaxBackoff),
+		OnRetry:        commands.AuditRetry,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("无法创建作业管理器: %w", err)
+	}
+
+	auditLog, err := audit.Open(filepath.Join(dataDir, "audit"), audit.Options{
+		MaxSize:  int64(cfg.Audit.MaxSizeMB) << 20,
+		MaxFiles: cfg.Audit.MaxFiles,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("无法打开审计日志: %w", err)
+	}
+
+	jobManager.Start()
+	printerMonitor := monitor.New(b, monitor.Options{Interval: time.Duration(cfg.Monitor.Interval)})
+	printerMonitor.Start()
+	slog.Info("打印机监视器已启动", "interval", time.Duration(cfg.Monitor.Interval))
+
+	services := &commands.Services{
+		Backend:  b,
+		Jobs:     jobManager,
+		Labels:   labels.NewStore(filepath.Join(dataDir, "labels")),
+		Renderer: document.NewRenderer(loadPDFFont(fontPath, filepath.Join(dataDir, "fonts"))),
+		Printers: resolver,
+		Monitor:  printerMonitor,
+		Audit:    auditLog,
+		Policy:   accessPolicy,
+	}
+	commands.SetServices(services)
+	return services, nil
+}
+
+// loadPDFFont 加载渲染 PDF 使用的 TrueType 字体。未指定路径时使用字体目录中的第一个 .ttf 文件，
+// 都没有或加载失败时返回 nil，由渲染器使用内置中文字体
+func loadPDFFont(fontPath, fontDir string) document.Font {
+	if fontPath == "" {
+		matches, _ := filepath.Glob(filepath.Join(fontDir, "*.ttf"))
+		if len(matches) == 0 {
+			slog.Info("未配置 PDF 字体，使用内置字体文泉驿微米黑 (GB2312 子集)")
+			return nil
+		}
+		fontPath = matches[0]
+	}
+	font, err := document.LoadTrueTypeFont(fontPath)
 	if err != nil {
-		log.Fatalf("无法监听动态端口: %v", err)
+		slog.Warn("加载 PDF 字体失败，使用内置字体", "file", fontPath, "error", err)
+		return nil
 	}
-	s := grpc.NewServer()
-	pb.RegisterComponentServiceServer(s, NewPrinterServer(s))
+	slog.Info("PDF 字体", "file", fontPath)
+	return font
+}
+
+// defaultDataDir 返回组件默认的数据目录: <可执行文件目录>/data/<组件名>
+func defaultDataDir(componentName string) string {
+	exePath, err := os.Executable()
+	if err != nil {
+		log.Fatalf("无法获取组件程序路径: %v", err)
+	}
+	return filepath.Join(filepath.Dir(exePath), "data", componentName)
+}
+
+// startMyService 在 listenAddr 上启动组件服务，只接受 Supervisor 的 mTLS 连接
+func startMyService(options serviceOptions, listenAddr string, tlsFiles mtls.Files) (net.Listener, *grpc.Server) {
+	tlsConfig, err := mtls.ComponentServerConfig(tlsFiles)
+	if err != nil {
+		log.Fatalf("加载 mTLS 证书失败: %v", err)
+	}
+	lis, err := transport.Listen(listenAddr)
+	if err != nil {
+		log.Fatalf("无法监听 %s: %v", listenAddr, err)
+	}
+	s := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)))
+	pb.RegisterComponentServiceServer(s, NewPrinterServer(s, options))
 	log.Printf("打印组件的服务启动，正在动态监听 %s", lis.Addr().String())
 	go func() {
 		if err := s.Serve(lis); err != nil {
@@ -125,8 +322,12 @@ func startMyService() (net.Listener, *grpc.Server) {
 	return lis, s
 }
 
-func registerToSupervisor(discoveryAddr, myAddr, componentName string) {
-	conn, err := grpc.NewClient(discoveryAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func registerToSupervisor(discoveryAddr, myAddr, componentName string, tlsFiles mtls.Files) {
+	tlsConfig, err := mtls.ComponentClientConfig(tlsFiles)
+	if err != nil {
+		log.Fatalf("加载 mTLS 证书失败: %v", err)
+	}
+	conn, err := grpc.NewClient(discoveryAddr, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	if err != nil {
 		log.Fatalf("无法连接到发现服务 at %s: %v", discoveryAddr, err)
 	}
@@ -150,12 +351,62 @@ func registerToSupervisor(discoveryAddr, myAddr, componentName string) {
 func main() {
 	discoveryAddr := flag.String("discovery-addr", "", "Supervisor's discovery service address")
 	componentName := flag.String("component-name", "", "This component's name")
+	backendName := flag.String("backend", "", "Printing backend (windows, cups, fake), defaults to the OS backend")
+	dataDir := flag.String("data-dir", "", "Directory for persistent component data")
+	configPath := flag.String("config", "", "Component config file, defaults to <data-dir>/config.json")
+	policyPath := flag.String("policy", "", "Access policy file, defaults to <data-dir>/policy.json")
+	pdfFont := flag.String("pdf-font", "", "TrueType font embedded in rendered PDFs, defaults to the first .ttf in <data-dir>/fonts, then the built-in WenQuanYi Micro Hei subset")
+	logLevel := flag.String(commandbus.FlagLogLevel, "info", "Log level (debug, info, warn, error)")
+	traceFile := flag.String(tracing.FlagTraceFile, "", "Append OTLP JSON spans to this file")
+	listenAddr := flag.String("listen", "127.0.0.1:0", "Address of the component gRPC service, host:port or unix:///path/to/socket")
+	var tlsFiles mtls.Files
+	flag.StringVar(&tlsFiles.CA, mtls.FlagCA, "", "CA certificate issued by the supervisor")
+	flag.StringVar(&tlsFiles.Cert, mtls.FlagCert, "", "Component certificate issued by the supervisor")
+	flag.StringVar(&tlsFiles.Key, mtls.FlagKey, "", "Component private key")
 	flag.Parse()
+	if err := commandbus.SetupLogging(os.Stderr, *logLevel, "text"); err != nil {
+		log.Fatal(err)
+	}
 	if *discoveryAddr == "" || *componentName == "" {
 		log.Fatal("必须提供 --discovery-addr 和 --component-name 参数")
 	}
-	listener, _ := startMyService()
-	myAddress := listener.Addr().String()
-	registerToSupervisor(*discoveryAddr, myAddress, *componentName)
+	if !tlsFiles.Complete() {
+		log.Fatal("必须提供 Supervisor 签发的 --tls-ca、--tls-cert 和 --tls-key 参数")
+	}
+	if *traceFile != "" {
+		exporter, err := tracing.NewFileExporter(*traceFile, *componentName)
+		if err != nil {
+			log.Fatalf("无法启用请求追踪: %v", err)
+		}
+		tracing.SetExporter(exporter)
+	}
+	if *dataDir == "" {
+		*dataDir = defaultDataDir(*componentName)
+	}
+	if *configPath == "" {
+		*configPath = filepath.Join(*dataDir, "config.json")
+	}
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("加载组件配置失败: %v", err)
+	}
+	if *policyPath == "" {
+		*policyPath = filepath.Join(*dataDir, "policy.json")
+	}
+	accessPolicy, err := policy.Load(*policyPath)
+	if err != nil {
+		log.Fatalf("加载访问策略失败: %v", err)
+	}
+	slog.Info("访问策略", "rules", len(accessPolicy.Rules), "default", accessPolicy.Default)
+	options := serviceOptions{
+		backendName: *backendName,
+		dataDir:     *dataDir,
+		fontPath:    *pdfFont,
+		cfg:         cfg,
+		policy:      accessPolicy,
+	}
+	listener, _ := startMyService(options, *listenAddr, tlsFiles)
+	myAddress := transport.Address(listener)
+	registerToSupervisor(*discoveryAddr, myAddress, *componentName, tlsFiles)
 	select {}
 }
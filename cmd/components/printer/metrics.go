@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+
+	"cse-go/cmd/components/printer/backend"
+	"cse-go/cmd/components/printer/jobs"
+	"cse-go/internal/commandbus"
+	"cse-go/internal/metrics"
+	pb "cse-go/pkg/api/v1"
+)
+
+// 打印组件上报给 Supervisor 的指标，在 /metrics 中带有 cse_component_ 前缀
+var (
+	commandsTotal = commandbus.Metrics.NewCounter("printer_commands_total",
+		"Commands executed by the printer component by result (ok, error, denied).", "command", "result")
+)
+
+// jobStates 为按状态统计作业数量时输出的状态
+var jobStates = []backend.JobState{
+	backend.JobQueued, backend.JobRetrying, backend.JobSpooling, backend.JobPrinting,
+	backend.JobCompleted, backend.JobFailed, backend.JobCancelled,
+}
+
+// registerJobMetrics 注册按状态统计的作业数量
+func registerJobMetrics(manager *jobs.Manager) {
+	commandbus.Metrics.RegisterCollector(func() []*metrics.Family {
+		counts := make(map[backend.JobState]int)
+		for _, job := range manager.List(jobs.Filter{}) {
+			counts[job.State]++
+		}
+		f := &metrics.Family{Name: "printer_jobs", Help: "Print jobs in history by state.", Type: metrics.TypeGauge}
+		for _, state := range jobStates {
+			f.Samples = append(f.Samples, metrics.Sample{
+				Labels: []metrics.Label{{Name: "state", Value: string(state)}},
+				Value:  float64(counts[state]),
+			})
+		}
+		return []*metrics.Family{f}
+	})
+}
+
+// GetMetrics 返回组件的自定义指标
+func (s *printerServer) GetMetrics(ctx context.Context, req *pb.GetMetricsRequest) (*pb.GetMetricsResponse, error) {
+	return commandbus.MetricsResponse(commandbus.Metrics), nil
+}
@@ -0,0 +1,235 @@
+// Package monitor 定期采集打印机列表、默认打印机与状态，比较前后两次快照并发布变化事件
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"cse-go/cmd/components/printer/backend"
+)
+
+const (
+	defaultInterval     = 5 * time.Second
+	defaultHistoryLimit = 500
+)
+
+// 事件类型
+const (
+	EventAdded          = "printer.added"
+	EventRemoved        = "printer.removed"
+	EventDefaultChanged = "printer.defaultChanged"
+	EventStatusChanged  = "printer.statusChanged"
+)
+
+// Event 描述一次打印机变化
+type Event struct {
+	// Seq 为单调递增的事件序号，调用方据此增量获取事件
+	Seq     uint64    `json:"seq"`
+	Type    string    `json:"type"`
+	Printer string    `json:"printer"`
+	Time    time.Time `json:"time"`
+	// Status 为变化后的状态，用于 printer.added 与 printer.statusChanged
+	Status        backend.PrinterStatus `json:"status,omitempty"`
+	StatusMessage string                `json:"statusMessage,omitempty"`
+	// Previous 为变化前的值：printer.statusChanged 为原状态，printer.defaultChanged 为原默认打印机
+	Previous string `json:"previous,omitempty"`
+}
+
+// PrinterState 是快照中单台打印机的状态
+type PrinterState struct {
+	Status  backend.PrinterStatus
+	Message string
+}
+
+// Snapshot 是某一时刻的打印机列表、默认打印机与状态
+type Snapshot struct {
+	Printers map[string]PrinterState
+	Default  string
+}
+
+// Take 从后端采集快照。查询单台打印机状态失败时记为 unknown，获取打印机列表失败时返回错误
+func Take(b backend.Backend) (*Snapshot, error) {
+	names, err := b.Printers()
+	if err != nil {
+		return nil, fmt.Errorf("获取打印机列表失败: %w", err)
+	}
+	s := &Snapshot{Printers: make(map[string]PrinterState, len(names))}
+	for _, name := range names {
+		info, err := b.PrinterInfo(name)
+		if err != nil {
+			s.Printers[name] = PrinterState{Status: backend.StatusUnknown, Message: err.Error()}
+			continue
+		}
+		s.Printers[name] = PrinterState{Status: info.Status, Message: info.StatusMessage}
+	}
+	// 默认打印机获取失败时视为无默认打印机，不影响其余比较
+	s.Default, _ = b.DefaultPrinter()
+	return s, nil
+}
+
+// Diff 比较前后两次快照并返回变化事件，事件的 Seq 与 Time 由调用方填写。
+// 事件按 removed、added、statusChanged、defaultChanged 的顺序排列，同类事件按打印机名称排序
+func Diff(prev, next *Snapshot) []Event {
+	var events []Event
+	for _, name := range sortedNames(prev) {
+		if _, ok := next.Printers[name]; !ok {
+			events = append(events, Event{Type: EventRemoved, Printer: name})
+		}
+	}
+	for _, name := range sortedNames(next) {
+		if _, ok := prev.Printers[name]; !ok {
+			state := next.Printers[name]
+			events = append(events, Event{Type: EventAdded, Printer: name, Status: state.Status, StatusMessage: state.Message})
+		}
+	}
+	for _, name := range sortedNames(next) {
+		old, ok := prev.Printers[name]
+		state := next.Printers[name]
+		if ok && old.Status != state.Status {
+			events = append(events, Event{
+				Type:          EventStatusChanged,
+				Printer:       name,
+				Status:        state.Status,
+				StatusMessage: state.Message,
+				Previous:      string(old.Status),
+			})
+		}
+	}
+	if prev.Default != next.Default {
+		events = append(events, Event{Type: EventDefaultChanged, Printer: next.Default, Previous: prev.Default})
+	}
+	return events
+}
+
+func sortedNames(s *Snapshot) []string {
+	names := make([]string, 0, len(s.Printers))
+	for name := range s.Printers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Options 定义了监视器的配置
+type Options struct {
+	// Interval 为采集快照的间隔
+	Interval time.Duration
+	// HistoryLimit 为保留的最近事件数量上限
+	HistoryLimit int
+}
+
+// Monitor 定期采集快照并发布变化事件
+type Monitor struct {
+	backend backend.Backend
+	opts    Options
+
+	mu      sync.Mutex
+	last    *Snapshot
+	seq     uint64
+	history []Event
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New 创建监视器
+func New(b backend.Backend, opts Options) *Monitor {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultInterval
+	}
+	if opts.HistoryLimit <= 0 {
+		opts.HistoryLimit = defaultHistoryLimit
+	}
+	return &Monitor{
+		backend: b,
+		opts:    opts,
+	}
+}
+
+// Start 采集初始快照并启动后台轮询协程
+func (m *Monitor) Start() {
+	m.Check()
+	m.stop = make(chan struct{})
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.Check()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台轮询协程
+func (m *Monitor) Stop() {
+	if m.stop == nil {
+		return
+	}
+	close(m.stop)
+	m.wg.Wait()
+	m.stop = nil
+}
+
+// Check 采集一次快照，与上一次比较后发布变化事件并返回。
+// 第一次采集只作为比较基准，不产生事件；采集失败时保留上一次快照
+func (m *Monitor) Check() []Event {
+	snapshot, err := Take(m.backend)
+	if err != nil {
+		log.Printf("[Printer Monitor] %v", err)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prev := m.last
+	m.last = snapshot
+	if prev == nil {
+		return nil
+	}
+
+	events := Diff(prev, snapshot)
+	now := time.Now()
+	for i := range events {
+		m.seq++
+		events[i].Seq = m.seq
+		events[i].Time = now
+		m.publish(events[i])
+	}
+	return events
+}
+
+// publish 记录事件，调用方需持有锁
+func (m *Monitor) publish(event Event) {
+	log.Printf("[Printer Monitor] %s: %s %s", event.Type, event.Printer, event.Status)
+	m.history = append(m.history, event)
+	if over := len(m.history) - m.opts.HistoryLimit; over > 0 {
+		m.history = append(m.history[:0], m.history[over:]...)
+	}
+}
+
+// Events 返回序号大于 since 的最近事件，limit 大于 0 时最多返回最早的 limit 个，
+// 同时返回下次增量获取时作为 since 的序号：结果被 limit 截断时为最后一个返回的事件的序号，否则为当前最大的事件序号
+func (m *Monitor) Events(since uint64, limit int) ([]Event, uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	events := []Event{}
+	for _, event := range m.history {
+		if event.Seq <= since {
+			continue
+		}
+		if limit > 0 && len(events) >= limit {
+			return events, events[len(events)-1].Seq
+		}
+		events = append(events, event)
+	}
+	return events, m.seq
+}
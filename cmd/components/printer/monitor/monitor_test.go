@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+
+	"cse-go/cmd/components/printer/backend"
+)
+
+// failingBackend 使 Printers 返回错误，模拟后端暂时不可用
+type failingBackend struct {
+	*backend.Fake
+}
+
+func (f failingBackend) Printers() ([]string, error) {
+	return nil, errors.New("spooler unavailable")
+}
+
+func eventKinds(events []Event) []string {
+	var kinds []string
+	for _, e := range events {
+		kinds = append(kinds, e.Type+":"+e.Printer)
+	}
+	return kinds
+}
+
+func assertEvents(t *testing.T, got []Event, want ...string) {
+	t.Helper()
+	kinds := eventKinds(got)
+	if len(kinds) != len(want) {
+		t.Fatalf("事件为 %v，期望 %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("事件为 %v，期望 %v", kinds, want)
+		}
+	}
+}
+
+// TestDiff 测试打印机增减、默认打印机与状态变化的比较
+func TestDiff(t *testing.T) {
+	fake := backend.NewFake("P1", "P2")
+	m := New(fake, Options{})
+	if events := m.Check(); len(events) != 0 {
+		t.Fatalf("首次采集不应产生事件: %v", eventKinds(events))
+	}
+	assertEvents(t, m.Check())
+
+	fake.SetPrinters("P2", "P3")
+	fake.SetStatus("P2", backend.StatusPaperOut)
+	events := m.Check()
+	assertEvents(t, events,
+		EventRemoved+":P1",
+		EventAdded+":P3",
+		EventStatusChanged+":P2",
+		EventDefaultChanged+":P2",
+	)
+	if events[2].Status != backend.StatusPaperOut || events[2].Previous != string(backend.StatusIdle) {
+		t.Errorf("状态变化事件内容不正确: %+v", events[2])
+	}
+	if events[3].Previous != "P1" {
+		t.Errorf("默认打印机变化事件应包含原默认打印机: %+v", events[3])
+	}
+	if events[1].Status != backend.StatusIdle {
+		t.Errorf("新增打印机事件应包含状态: %+v", events[1])
+	}
+
+	fake.SetDefaultPrinter("P3")
+	assertEvents(t, m.Check(), EventDefaultChanged+":P3")
+}
+
+// TestCheckKeepsSnapshotOnError 测试采集失败时不产生事件，并在恢复后与失败前的快照比较
+func TestCheckKeepsSnapshotOnError(t *testing.T) {
+	fake := backend.NewFake("P1")
+	m := New(fake, Options{})
+	m.Check()
+
+	m.backend = failingBackend{fake}
+	if events := m.Check(); len(events) != 0 {
+		t.Fatalf("采集失败时不应产生事件: %v", eventKinds(events))
+	}
+
+	fake.SetStatus("P1", backend.StatusOffline)
+	m.backend = fake
+	assertEvents(t, m.Check(), EventStatusChanged+":P1")
+}
+
+// TestEvents 测试事件序号、历史记录上限与增量获取
+func TestEvents(t *testing.T) {
+	fake := backend.NewFake("P1")
+	m := New(fake, Options{HistoryLimit: 3})
+	m.Check()
+
+	for _, status := range []backend.PrinterStatus{backend.StatusOffline, backend.StatusIdle, backend.StatusJammed, backend.StatusIdle} {
+		fake.SetStatus("P1", status)
+		m.Check()
+	}
+
+	events, last := m.Events(0, 0)
+	if last != 4 || len(events) != 3 || events[0].Seq != 2 {
+		t.Fatalf("历史事件不正确: last=%d %+v", last, events)
+	}
+	// 结果被截断时从最后一个返回的事件继续，不跳过之后的事件
+	events, last = m.Events(2, 1)
+	if len(events) != 1 || events[0].Seq != 3 || last != 3 {
+		t.Errorf("增量获取事件不正确: last=%d %+v", last, events)
+	}
+	if events, last = m.Events(last, 1); len(events) != 1 || events[0].Seq != 4 || last != 4 {
+		t.Errorf("应从上次返回的序号继续获取: last=%d %+v", last, events)
+	}
+	if events, _ := m.Events(4, 0); len(events) != 0 {
+		t.Errorf("没有新事件时应返回空列表: %+v", events)
+	}
+}
@@ -0,0 +1,182 @@
+// Package policy 根据策略文件决定调用方能否对某台打印机执行某个命令
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"cse-go/internal/commandbus"
+)
+
+// 规则的效果
+const (
+	Allow = "allow"
+	Deny  = "deny"
+)
+
+// Rule 是一条访问规则。各条件之间为"且"的关系，条件内的多个取值为"或"的关系，
+// 空条件表示不限制。条件取值中的 * 匹配任意字符，例如 "print.*"、"https://*.example.com"
+type Rule struct {
+	// Name 出现在拒绝信息中，便于定位规则
+	Name   string `json:"name,omitempty"`
+	Effect string `json:"effect"`
+	// Commands 为命令名称
+	Commands []string `json:"commands,omitempty"`
+	// Printers 为调用方传入的打印机名称 (可以是逻辑打印机)。
+	// 设置后规则只匹配指定了打印机的命令，操作作业的命令以作业所属打印机为准。
+	// 对逻辑打印机的调用还需要其每台成员打印机都被允许
+	Printers []string `json:"printers,omitempty"`
+	// Users 与 Origins 匹配调用方身份中的用户与来源
+	Users   []string `json:"users,omitempty"`
+	Origins []string `json:"origins,omitempty"`
+}
+
+// Policy 是访问策略，按顺序使用第一条匹配的规则，没有规则匹配时使用 Default
+type Policy struct {
+	Default string  `json:"default"`
+	Rules   []*Rule `json:"rules"`
+}
+
+// Request 是一次待检查的命令调用
+type Request struct {
+	Command string
+	// Printer 为命令操作的打印机，没有时为空
+	Printer string
+	// Members 为 Printer 是逻辑打印机时可能使用的所有成员打印机
+	Members []string
+	Caller  commandbus.Caller
+}
+
+// Decision 是检查结果
+type Decision struct {
+	Allowed bool
+	// Rule 为作出决定的规则名称，使用默认效果时为空
+	Rule string
+}
+
+// AllowAll 返回允许所有调用的策略，用于没有策略文件的情况
+func AllowAll() *Policy {
+	return &Policy{Default: Allow}
+}
+
+// Load 读取策略文件，文件不存在时返回允许所有调用的策略
+func Load(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return AllowAll(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取策略文件 '%s': %w", file, err)
+	}
+	p := &Policy{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("无法解析策略文件 '%s': %w", file, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("策略文件 '%s' 无效: %w", file, err)
+	}
+	return p, nil
+}
+
+// validate 检查效果取值并为未命名的规则生成名称
+func (p *Policy) validate() error {
+	if p.Default == "" {
+		p.Default = Allow
+	}
+	if p.Default != Allow && p.Default != Deny {
+		return fmt.Errorf("默认效果必须是 %s 或 %s，实际为 '%s'", Allow, Deny, p.Default)
+	}
+	for i, rule := range p.Rules {
+		if rule == nil {
+			return fmt.Errorf("第 %d 条规则为空", i+1)
+		}
+		if rule.Effect != Allow && rule.Effect != Deny {
+			return fmt.Errorf("规则 %s 的效果必须是 %s 或 %s", rule.label(i), Allow, Deny)
+		}
+		if rule.Name == "" {
+			rule.Name = rule.label(i)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) label(i int) string {
+	if r.Name != "" {
+		return "'" + r.Name + "'"
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// Check 检查调用是否被允许。Printer 为逻辑打印机时，Printer 与每台成员打印机都被允许才允许调用，
+// 拒绝时返回第一个拒绝的决定
+func (p *Policy) Check(req Request) Decision {
+	decision := p.check(&req)
+	if !decision.Allowed {
+		return decision
+	}
+	for _, member := range req.Members {
+		memberReq := req
+		memberReq.Printer = member
+		if d := p.check(&memberReq); !d.Allowed {
+			return d
+		}
+	}
+	return decision
+}
+
+// check 使用第一条匹配的规则检查一台打印机上的调用
+func (p *Policy) check(req *Request) Decision {
+	for _, rule := range p.Rules {
+		if rule.matches(req) {
+			return Decision{Allowed: rule.Effect == Allow, Rule: rule.Name}
+		}
+	}
+	return Decision{Allowed: p.Default == Allow}
+}
+
+func (r *Rule) matches(req *Request) bool {
+	if len(r.Printers) > 0 && req.Printer == "" {
+		return false
+	}
+	return matchAny(r.Commands, req.Command) &&
+		matchAny(r.Printers, req.Printer) &&
+		matchAny(r.Users, req.Caller.User) &&
+		matchAny(r.Origins, req.Caller.Origin)
+}
+
+// matchAny 在 patterns 为空或任一模式匹配 value 时返回 true
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if wildcardMatch(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardMatch 判断 value 是否匹配 pattern，pattern 中的 * 匹配任意长度的任意字符
+func wildcardMatch(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, part)
+		if i < 0 {
+			return false
+		}
+		value = value[i+len(part):]
+	}
+	return len(value) >= len(last) && strings.HasSuffix(value, last)
+}
@@ -0,0 +1,124 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cse-go/internal/commandbus"
+)
+
+const testPolicy = `{
+	"default": "allow",
+	"rules": [
+		{"name": "admin-default", "effect": "allow", "commands": ["print.setDefaultPrinter"], "origins": ["https://admin.example.com"]},
+		{"effect": "deny", "commands": ["print.setDefaultPrinter"]},
+		{"effect": "allow", "users": ["kiosk"], "printers": ["receipt"]},
+		{"name": "kiosk-other-printers", "effect": "deny", "users": ["kiosk"], "printers": ["*"]}
+	]
+}`
+
+func loadTestPolicy(t *testing.T, content string) (*Policy, error) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return Load(file)
+}
+
+func TestCheck(t *testing.T) {
+	p, err := loadTestPolicy(t, testPolicy)
+	if err != nil {
+		t.Fatalf("加载策略失败: %v", err)
+	}
+
+	admin := commandbus.Caller{Origin: "https://admin.example.com"}
+	kiosk := commandbus.Caller{User: "kiosk", Origin: "http://localhost:3000"}
+	tests := []struct {
+		req     Request
+		allowed bool
+		rule    string
+	}{
+		{Request{Command: "print.setDefaultPrinter", Printer: "P1", Caller: admin}, true, "admin-default"},
+		{Request{Command: "print.setDefaultPrinter", Printer: "P1", Caller: kiosk}, false, "#2"},
+		{Request{Command: "print.printReceipt", Printer: "receipt", Caller: kiosk}, true, "#3"},
+		{Request{Command: "print.printReceipt", Printer: "office", Caller: kiosk}, false, "kiosk-other-printers"},
+		// 不涉及打印机的命令不匹配设置了 printers 的规则
+		{Request{Command: "print.getPrinters", Caller: kiosk}, true, ""},
+		{Request{Command: "print.testPrint", Printer: "office", Caller: admin}, true, ""},
+	}
+	for _, tt := range tests {
+		got := p.Check(tt.req)
+		if got.Allowed != tt.allowed || got.Rule != tt.rule {
+			t.Errorf("%+v: 结果为 %+v，期望 allowed=%v rule=%q", tt.req, got, tt.allowed, tt.rule)
+		}
+	}
+}
+
+// TestCheckLogicalPrinter 测试对逻辑打印机的调用需要每台成员打印机都被允许
+func TestCheckLogicalPrinter(t *testing.T) {
+	p, err := loadTestPolicy(t, `{
+		"default": "allow",
+		"rules": [{"name": "no-laserjet", "effect": "deny", "users": ["kiosk"], "printers": ["HP LaserJet*"]}]
+	}`)
+	if err != nil {
+		t.Fatalf("加载策略失败: %v", err)
+	}
+	kiosk := commandbus.Caller{User: "kiosk"}
+	req := Request{Command: "print.renderAndPrint", Printer: "a4-office", Members: []string{"Brother HL", "HP LaserJet 400"}, Caller: kiosk}
+	if got := p.Check(req); got.Allowed || got.Rule != "no-laserjet" {
+		t.Errorf("成员打印机被拒绝时不应允许通过逻辑打印机调用: %+v", got)
+	}
+	req.Members = []string{"Brother HL"}
+	if got := p.Check(req); !got.Allowed {
+		t.Errorf("所有成员打印机都被允许时应允许调用: %+v", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	p, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || !p.Check(Request{Command: "print.setDefaultPrinter"}).Allowed {
+		t.Errorf("策略文件不存在时应允许所有调用: %v", err)
+	}
+
+	p, err = loadTestPolicy(t, `{"default": "deny", "rules": [{"effect": "allow", "commands": ["print.get*"]}]}`)
+	if err != nil {
+		t.Fatalf("加载策略失败: %v", err)
+	}
+	if !p.Check(Request{Command: "print.getPrinters"}).Allowed || p.Check(Request{Command: "print.testPrint"}).Allowed {
+		t.Error("默认拒绝的策略判断错误")
+	}
+
+	for _, content := range []string{
+		`{"default": "maybe"}`,
+		`{"rules": [{"effect": "permit"}]}`,
+		`{"rules": [null]}`,
+		`not json`,
+	} {
+		if _, err := loadTestPolicy(t, content); err == nil {
+			t.Errorf("策略 %s 应加载失败", content)
+		}
+	}
+}
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"*", "https://a.example.com", true},
+		{"print.*", "print.testPrint", true},
+		{"print.*", "printer.x", false},
+		{"https://*.example.com", "https://admin.example.com", true},
+		{"https://*.example.com", "https://example.com", false},
+		{"a*b*c", "abbc", true},
+		{"a*a", "a", false},
+		{"exact", "exact", true},
+	}
+	for _, tt := range tests {
+		if got := wildcardMatch(tt.pattern, tt.value); got != tt.want {
+			t.Errorf("wildcardMatch(%q, %q) = %v，期望 %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Package pools 将逻辑打印机名称 (如 "receipt"、"label") 解析为物理打印机，
+// 支持按优先级或轮询选择，并跳过离线或故障的打印机
+package pools
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"cse-go/cmd/components/printer/backend"
+)
+
+// 选择策略
+const (
+	// StrategyPriority 按成员顺序选择第一台可用的打印机
+	StrategyPriority = "priority"
+	// StrategyRoundRobin 在可用的打印机之间轮流选择
+	StrategyRoundRobin = "roundRobin"
+)
+
+// Pool 是一个逻辑打印机的定义
+type Pool struct {
+	// Members 为物理打印机名称，按优先级从高到低排列
+	Members     []string `json:"members"`
+	Strategy    string   `json:"strategy,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// UnmarshalJSON 允许使用字符串定义只有一台打印机的别名
+func (p *Pool) UnmarshalJSON(data []byte) error {
+	var alias string
+	if err := json.Unmarshal(data, &alias); err == nil {
+		*p = Pool{Members: []string{alias}}
+		return nil
+	}
+	type plain Pool
+	return json.Unmarshal(data, (*plain)(p))
+}
+
+// Info 是逻辑打印机的摘要信息
+type Info struct {
+	Name        string   `json:"name"`
+	Members     []string `json:"members"`
+	Strategy    string   `json:"strategy"`
+	Description string   `json:"description,omitempty"`
+}
+
+// unavailable 为不参与选择的打印机状态
+var unavailable = map[backend.PrinterStatus]bool{
+	backend.StatusOffline:  true,
+	backend.StatusPaperOut: true,
+	backend.StatusJammed:   true,
+	backend.StatusError:    true,
+}
+
+// Resolver 解析逻辑打印机名称，可并发使用
+type Resolver struct {
+	backend backend.Backend
+
+	// mu 保护 pools 与 next，Update 时整体替换
+	mu    sync.Mutex
+	pools map[string]*Pool
+	next  map[string]int
+}
+
+// Validate 校验逻辑打印机定义，未指定选择策略的逻辑打印机使用 StrategyPriority
+func Validate(pools map[string]*Pool) error {
+	for name, pool := range pools {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("逻辑打印机名称不能为空")
+		}
+		if pool == nil || len(pool.Members) == 0 {
+			return fmt.Errorf("逻辑打印机 '%s' 至少需要一台物理打印机", name)
+		}
+		for _, member := range pool.Members {
+			if member == "" {
+				return fmt.Errorf("逻辑打印机 '%s' 包含空的打印机名称", name)
+			}
+			if _, ok := pools[member]; ok {
+				return fmt.Errorf("逻辑打印机 '%s' 不能引用另一个逻辑打印机 '%s'", name, member)
+			}
+		}
+		switch pool.Strategy {
+		case "":
+			pool.Strategy = StrategyPriority
+		case StrategyPriority, StrategyRoundRobin:
+		default:
+			return fmt.Errorf("逻辑打印机 '%s' 的选择策略 '%s' 不受支持", name, pool.Strategy)
+		}
+	}
+	return nil
+}
+
+// NewResolver 校验逻辑打印机定义并创建解析器。逻辑名称与物理打印机同名时以逻辑定义为准
+func NewResolver(b backend.Backend, pools map[string]*Pool) (*Resolver, error) {
+	if err := Validate(pools); err != nil {
+		return nil, err
+	}
+	return &Resolver{backend: b, pools: pools, next: make(map[string]int)}, nil
+}
+
+// Update 校验并替换逻辑打印机定义，轮询位置重新开始
+func (r *Resolver) Update(pools map[string]*Pool) error {
+	if err := Validate(pools); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pools = pools
+	r.next = make(map[string]int)
+	return nil
+}
+
+// current 返回当前的逻辑打印机定义，返回值不应被修改
+func (r *Resolver) current() map[string]*Pool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pools
+}
+
+// IsLogical 判断名称是否为逻辑打印机
+func (r *Resolver) IsLogical(name string) bool {
+	_, ok := r.current()[name]
+	return ok
+}
+
+// Members 返回名称对应的物理打印机，非逻辑名称原样返回
+func (r *Resolver) Members(name string) []string {
+	if pool, ok := r.current()[name]; ok {
+		return append([]string(nil), pool.Members...)
+	}
+	return []string{name}
+}
+
+// List 按名称顺序返回所有逻辑打印机
+func (r *Resolver) List() []*Info {
+	pools := r.current()
+	infos := make([]*Info, 0, len(pools))
+	for name, pool := range pools {
+		infos = append(infos, &Info{
+			Name:        name,
+			Members:     append([]string(nil), pool.Members...),
+			Strategy:    pool.Strategy,
+			Description: pool.Description,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Resolve 将名称解析为一台物理打印机。非逻辑名称原样返回；
+// 逻辑名称按策略选择一台状态可用的成员，全部不可用时返回错误
+func (r *Resolver) Resolve(name string) (string, error) {
+	pool, ok := r.current()[name]
+	if !ok {
+		return name, nil
+	}
+
+	candidates := pool.Members
+	if pool.Strategy == StrategyRoundRobin {
+		r.mu.Lock()
+		start := r.next[name] % len(candidates)
+		r.next[name] = start + 1
+		r.mu.Unlock()
+		candidates = append(append([]string(nil), candidates[start:]...), candidates[:start]...)
+	}
+
+	var reasons []string
+	for _, member := range candidates {
+		info, err := r.backend.PrinterInfo(member)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("%s: %v", member, err))
+			continue
+		}
+		if unavailable[info.Status] {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", member, info.Status))
+			continue
+		}
+		return member, nil
+	}
+	return "", fmt.Errorf("逻辑打印机 '%s' 没有可用的打印机 (%s)", name, strings.Join(reasons, "; "))
+}
@@ -0,0 +1,135 @@
+package pools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"cse-go/cmd/components/printer/backend"
+)
+
+func newTestResolver(t *testing.T, b backend.Backend, config string) *Resolver {
+	t.Helper()
+	var pools map[string]*Pool
+	if err := json.Unmarshal([]byte(config), &pools); err != nil {
+		t.Fatalf("解析配置失败: %v", err)
+	}
+	r, err := NewResolver(b, pools)
+	if err != nil {
+		t.Fatalf("创建解析器失败: %v", err)
+	}
+	return r
+}
+
+func TestResolvePriorityWithFailover(t *testing.T) {
+	b := backend.NewFake("P1", "P2", "P3")
+	r := newTestResolver(t, b, `{"receipt": {"members": ["P1", "P2", "P3"]}, "label": "P3"}`)
+
+	if got, err := r.Resolve("receipt"); err != nil || got != "P1" {
+		t.Fatalf("期望选择 P1，实际 %q (%v)", got, err)
+	}
+	b.SetStatus("P1", backend.StatusOffline)
+	b.SetStatus("P2", backend.StatusPaperOut)
+	if got, err := r.Resolve("receipt"); err != nil || got != "P3" {
+		t.Fatalf("P1、P2 不可用时期望选择 P3，实际 %q (%v)", got, err)
+	}
+	if got, _ := r.Resolve("label"); got != "P3" {
+		t.Errorf("别名应解析为 P3，实际 %q", got)
+	}
+	if got, _ := r.Resolve("Physical"); got != "Physical" {
+		t.Errorf("非逻辑名称应原样返回，实际 %q", got)
+	}
+
+	b.SetStatus("P3", backend.StatusError)
+	if _, err := r.Resolve("receipt"); err == nil || !strings.Contains(err.Error(), "P2: paperOut") {
+		t.Errorf("全部不可用时应返回包含原因的错误，实际 %v", err)
+	}
+}
+
+func TestResolveRoundRobin(t *testing.T) {
+	b := backend.NewFake("P1", "P2", "P3")
+	r := newTestResolver(t, b, `{"office": {"members": ["P1", "P2", "P3"], "strategy": "roundRobin"}}`)
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		name, err := r.Resolve("office")
+		if err != nil {
+			t.Fatalf("解析失败: %v", err)
+		}
+		got = append(got, name)
+	}
+	if strings.Join(got, ",") != "P1,P2,P3,P1" {
+		t.Errorf("轮询顺序错误: %v", got)
+	}
+
+	// 不可用的成员被跳过，轮到它时选择下一台
+	b.SetStatus("P3", backend.StatusOffline)
+	got = got[:0]
+	for i := 0; i < 3; i++ {
+		name, _ := r.Resolve("office")
+		got = append(got, name)
+	}
+	if strings.Join(got, ",") != "P2,P1,P1" {
+		t.Errorf("跳过离线打印机后的轮询顺序错误: %v", got)
+	}
+}
+
+func TestResolveMissingPrinterFailsOver(t *testing.T) {
+	b := backend.NewFake("P2")
+	r := newTestResolver(t, b, `{"receipt": {"members": ["Gone", "P2"]}}`)
+	if got, err := r.Resolve("receipt"); err != nil || got != "P2" {
+		t.Errorf("不存在的打印机应被跳过，实际 %q (%v)", got, err)
+	}
+}
+
+func TestNewResolverValidation(t *testing.T) {
+	b := backend.NewFake()
+	for _, config := range []string{
+		`{"a": {"members": []}}`,
+		`{"a": {"members": ["P1"], "strategy": "random"}}`,
+		`{"a": {"members": ["b"]}, "b": "P1"}`,
+		`{"a": {"members": [""]}}`,
+	} {
+		var pools map[string]*Pool
+		if err := json.Unmarshal([]byte(config), &pools); err != nil {
+			t.Fatalf("解析配置失败: %v", err)
+		}
+		if _, err := NewResolver(b, pools); err == nil {
+			t.Errorf("配置 %s 应校验失败", config)
+		}
+	}
+
+	r := newTestResolver(t, b, `{"b": "P2", "a": {"members": ["P1"]}}`)
+	list := r.List()
+	if len(list) != 2 || list[0].Name != "a" || list[0].Strategy != StrategyPriority || list[1].Members[0] != "P2" {
+		t.Errorf("逻辑打印机列表错误: %+v", list)
+	}
+	if !r.IsLogical("a") || r.IsLogical("P1") {
+		t.Errorf("IsLogical 判断错误")
+	}
+	if members := r.Members("P1"); len(members) != 1 || members[0] != "P1" {
+		t.Errorf("非逻辑名称的成员应为自身，实际 %v", members)
+	}
+}
+
+func TestResolverUpdate(t *testing.T) {
+	b := backend.NewFake("P1", "P2")
+	r := newTestResolver(t, b, `{"receipt": "P1"}`)
+
+	if err := r.Update(map[string]*Pool{"receipt": {Members: []string{"P1"}, Strategy: "random"}}); err == nil {
+		t.Error("无效的定义应校验失败")
+	}
+	if got, _ := r.Resolve("receipt"); got != "P1" {
+		t.Errorf("校验失败时应保留之前的定义，实际 %q", got)
+	}
+
+	if err := r.Update(map[string]*Pool{"receipt": {Members: []string{"P2"}}, "label": {Members: []string{"P1"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := r.Resolve("receipt"); got != "P2" {
+		t.Errorf("应使用新的定义，实际 %q", got)
+	}
+	if list := r.List(); len(list) != 2 || list[0].Name != "label" || list[0].Strategy != StrategyPriority {
+		t.Errorf("逻辑打印机列表错误: %+v", list)
+	}
+}
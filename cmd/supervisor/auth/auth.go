@@ -0,0 +1,210 @@
+// Package auth 管理 HTTP API 的访问密钥。密钥以 SHA-256 摘要保存，明文只在创建时返回一次
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tokenPrefix = "cse_"
+	hashPrefix  = "sha256:"
+	// shownPrefixLen 为用于识别密钥的明文前缀长度
+	shownPrefixLen = 10
+)
+
+// ErrKeyNotFound 表示指定名称的密钥不存在
+var ErrKeyNotFound = errors.New("密钥不存在")
+
+// Key 是一个访问密钥。Components 与 Commands 为空时不限制，取值支持 path.Match 通配符，例如 "print.get*"
+type Key struct {
+	Name       string    `json:"name"`
+	Hash       string    `json:"hash,omitempty"`
+	Prefix     string    `json:"prefix,omitempty"`
+	Admin      bool      `json:"admin,omitempty"`
+	Components []string  `json:"components,omitempty"`
+	Commands   []string  `json:"commands,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Allows 判断密钥是否允许调用组件的命令
+func (k *Key) Allows(component, command string) bool {
+	if k.Admin {
+		return true
+	}
+	return matchAny(k.Components, component) && matchAny(k.Commands, command)
+}
+
+// AllowsComponent 判断密钥是否可以访问组件的至少一部分命令
+func (k *Key) AllowsComponent(component string) bool {
+	return k.Admin || matchAny(k.Components, component)
+}
+
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// HashToken 计算令牌的摘要
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hashPrefix + hex.EncodeToString(sum[:])
+}
+
+// Store 保存所有访问密钥，修改后通过 persist 写回配置
+type Store struct {
+	enabled bool
+	persist func([]*Key) error
+
+	mu     sync.RWMutex
+	keys   map[string]*Key
+	byHash map[string]*Key
+}
+
+// NewStore 创建密钥存储。enabled 为 false 时不进行认证；persist 为 nil 时修改只保存在内存中
+func NewStore(enabled bool, keys []*Key, persist func([]*Key) error) (*Store, error) {
+	s := &Store{
+		enabled: enabled,
+		persist: persist,
+		keys:    make(map[string]*Key),
+		byHash:  make(map[string]*Key),
+	}
+	for _, key := range keys {
+		if key == nil || key.Name == "" {
+			return nil, errors.New("密钥必须有名称")
+		}
+		if !strings.HasPrefix(key.Hash, hashPrefix) {
+			return nil, fmt.Errorf("密钥 '%s' 的摘要格式无效，应为 %s<hex>", key.Name, hashPrefix)
+		}
+		if _, ok := s.keys[key.Name]; ok {
+			return nil, fmt.Errorf("密钥名称 '%s' 重复", key.Name)
+		}
+		s.keys[key.Name] = key
+		s.byHash[key.Hash] = key
+	}
+	return s, nil
+}
+
+// Enabled 返回是否启用认证
+func (s *Store) Enabled() bool {
+	return s.enabled
+}
+
+// Authenticate 返回令牌对应的密钥，令牌无效时返回 nil
+func (s *Store) Authenticate(token string) *Key {
+	if token == "" {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.byHash[HashToken(token)]
+	if !ok {
+		return nil
+	}
+	copied := *key
+	return &copied
+}
+
+// Create 生成新的令牌并保存其摘要，返回只出现这一次的令牌明文
+func (s *Store) Create(spec Key) (string, *Key, error) {
+	if spec.Name == "" {
+		return "", nil, errors.New("密钥名称不能为空")
+	}
+	for _, pattern := range append(append([]string{}, spec.Components...), spec.Commands...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return "", nil, fmt.Errorf("通配符 '%s' 无效: %w", pattern, err)
+		}
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("生成令牌失败: %w", err)
+	}
+	token := tokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[spec.Name]; ok {
+		return "", nil, fmt.Errorf("密钥名称 '%s' 已存在", spec.Name)
+	}
+	key := &Key{
+		Name:       spec.Name,
+		Hash:       HashToken(token),
+		Prefix:     token[:shownPrefixLen],
+		Admin:      spec.Admin,
+		Components: spec.Components,
+		Commands:   spec.Commands,
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+	}
+	s.keys[key.Name] = key
+	s.byHash[key.Hash] = key
+	if err := s.save(); err != nil {
+		delete(s.keys, key.Name)
+		delete(s.byHash, key.Hash)
+		return "", nil, err
+	}
+	copied := *key
+	return token, &copied, nil
+}
+
+// Delete 删除指定名称的密钥
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[name]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	delete(s.keys, name)
+	delete(s.byHash, key.Hash)
+	if err := s.save(); err != nil {
+		s.keys[name] = key
+		s.byHash[key.Hash] = key
+		return err
+	}
+	return nil
+}
+
+// List 按名称顺序返回所有密钥的副本
+func (s *Store) List() []*Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sorted()
+}
+
+func (s *Store) sorted() []*Key {
+	keys := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		copied := *key
+		keys = append(keys, &copied)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys
+}
+
+// save 将密钥写回配置，调用方需持有写锁
+func (s *Store) save() error {
+	if s.persist == nil {
+		return nil
+	}
+	if err := s.persist(s.sorted()); err != nil {
+		return fmt.Errorf("保存密钥失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCreateAndAuthenticate(t *testing.T) {
+	var saved []*Key
+	s, err := NewStore(true, nil, func(keys []*Key) error {
+		saved = keys
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("创建密钥存储失败: %v", err)
+	}
+
+	token, key, err := s.Create(Key{Name: "kiosk", Components: []string{"printer"}, Commands: []string{"print.get*"}})
+	if err != nil {
+		t.Fatalf("创建密钥失败: %v", err)
+	}
+	if !strings.HasPrefix(token, tokenPrefix) || key.Hash != HashToken(token) || !strings.HasPrefix(token, key.Prefix) {
+		t.Fatalf("令牌或摘要格式不正确: %s %+v", token, key)
+	}
+	if len(saved) != 1 || strings.Contains(saved[0].Hash, token) {
+		t.Fatalf("保存的密钥不应包含明文令牌: %+v", saved)
+	}
+
+	got := s.Authenticate(token)
+	if got == nil || got.Name != "kiosk" {
+		t.Fatalf("令牌认证失败: %+v", got)
+	}
+	if s.Authenticate(token+"x") != nil || s.Authenticate("") != nil {
+		t.Error("无效令牌不应通过认证")
+	}
+	if !got.Allows("printer", "print.getPrinters") || got.Allows("printer", "print.testPrint") || got.Allows("scanner", "print.getPrinters") {
+		t.Error("密钥权限判断错误")
+	}
+
+	if _, _, err := s.Create(Key{Name: "kiosk"}); err == nil {
+		t.Error("重复的密钥名称应创建失败")
+	}
+	if err := s.Delete("kiosk"); err != nil {
+		t.Fatalf("删除密钥失败: %v", err)
+	}
+	if s.Authenticate(token) != nil || len(saved) != 0 {
+		t.Error("删除后令牌不应再通过认证")
+	}
+	if err := s.Delete("kiosk"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("删除不存在的密钥应返回 ErrKeyNotFound，实际 %v", err)
+	}
+}
+
+func TestNewStoreLoadsHashedKeys(t *testing.T) {
+	s, err := NewStore(true, []*Key{{Name: "admin", Hash: HashToken("secret"), Admin: true}}, nil)
+	if err != nil {
+		t.Fatalf("加载密钥失败: %v", err)
+	}
+	if key := s.Authenticate("secret"); key == nil || !key.Allows("any", "thing") {
+		t.Errorf("管理员密钥应允许所有命令: %+v", key)
+	}
+
+	for _, keys := range [][]*Key{
+		{{Name: "", Hash: HashToken("a")}},
+		{{Name: "a", Hash: "plaintext"}},
+		{{Name: "a", Hash: HashToken("a")}, {Name: "a", Hash: HashToken("b")}},
+	} {
+		if _, err := NewStore(true, keys, nil); err == nil {
+			t.Errorf("无效的密钥配置应加载失败: %+v", keys[0])
+		}
+	}
+}
+
+func TestPersistFailureRollsBack(t *testing.T) {
+	s, _ := NewStore(true, nil, func([]*Key) error { return errors.New("disk full") })
+	if _, _, err := s.Create(Key{Name: "a"}); err == nil {
+		t.Fatal("保存失败时创建应返回错误")
+	}
+	if len(s.List()) != 0 {
+		t.Error("保存失败时不应保留新密钥")
+	}
+}
@@ -0,0 +1,319 @@
+// Package certs 生成并维护本地 CA 以及由其签发的 localhost 服务器证书，
+// 用户将 CA 证书安装到系统或浏览器的信任列表后，网页即可通过 HTTPS 调用本地 API
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	caCertFile     = "ca.pem"
+	caKeyFile      = "ca-key.pem"
+	serverCertFile = "server.pem"
+	serverKeyFile  = "server-key.pem"
+
+	caValidity     = 10 * 365 * 24 * time.Hour
+	serverValidity = 365 * 24 * time.Hour
+	// renewBefore 为证书到期前重新签发的提前量
+	renewBefore = 30 * 24 * time.Hour
+)
+
+// CA 是本地证书颁发机构
+type CA struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+	// PEM 为 CA 证书的 PEM 编码，用于导出安装
+	PEM []byte
+}
+
+// LoadOrCreateCA 从 dir 读取本地 CA，不存在时生成新的 CA 并保存。
+// 新生成的 CA 带有名称约束，只能为 hosts 中的主机名签发证书
+func LoadOrCreateCA(dir, commonName string, hosts []string) (*CA, error) {
+	certPath := filepath.Join(dir, caCertFile)
+	keyPath := filepath.Join(dir, caKeyFile)
+
+	cert, key, certPEM, err := loadPair(certPath, keyPath)
+	if err == nil {
+		if time.Now().After(cert.NotAfter) {
+			return nil, fmt.Errorf("本地 CA 证书已于 %s 过期，请删除 %s 后重新生成并安装", cert.NotAfter.Format(time.DateOnly), dir)
+		}
+		if !cert.PermittedDNSDomainsCritical {
+			slog.Warn("本地 CA 没有名称约束，可以为任意域名签发证书，建议删除后重新生成并安装", "dir", dir)
+		}
+		return &CA{Cert: cert, Key: key, PEM: certPEM}, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	ca, der, err := newCA(commonName, hosts)
+	if err != nil {
+		return nil, err
+	}
+	if err := savePair(certPath, keyPath, der, ca.Key); err != nil {
+		return nil, err
+	}
+	slog.Info("已生成本地 CA", "file", certPath)
+	return ca, nil
+}
+
+// NewCA 在内存中生成只能为 hosts 签发证书的 CA，不保存到磁盘，用于只在本次运行中有效的内部证书
+func NewCA(commonName string, hosts []string) (*CA, error) {
+	ca, _, err := newCA(commonName, hosts)
+	return ca, err
+}
+
+// nameConstraints 返回只允许 hosts 与回环地址的名称约束：域名允许其自身及子域名，
+// IP 地址只允许其自身，127.0.0.0/8 与 ::1 始终允许
+func nameConstraints(hosts []string) (dnsDomains []string, ipRanges []*net.IPNet) {
+	ipRanges = []*net.IPNet{
+		{IP: net.IPv4(127, 0, 0, 0).To4(), Mask: net.CIDRMask(8, 32)},
+		{IP: net.IPv6loopback, Mask: net.CIDRMask(128, 128)},
+	}
+	dnsDomains = []string{"localhost"}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			if !ip.IsLoopback() {
+				if v4 := ip.To4(); v4 != nil {
+					ip = v4
+				}
+				ipRanges = append(ipRanges, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			}
+		} else if !slices.Contains(dnsDomains, h) {
+			dnsDomains = append(dnsDomains, h)
+		}
+	}
+	return dnsDomains, ipRanges
+}
+
+// permits 判断 CA 的名称约束是否允许为 host 签发证书，没有名称约束的 CA 允许任意主机名
+func (ca *CA) permits(host string) bool {
+	if !ca.Cert.PermittedDNSDomainsCritical {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return slices.ContainsFunc(ca.Cert.PermittedIPRanges, func(r *net.IPNet) bool { return r.Contains(ip) })
+	}
+	host = strings.ToLower(host)
+	return slices.ContainsFunc(ca.Cert.PermittedDNSDomains, func(d string) bool {
+		d = strings.ToLower(d)
+		return host == d || strings.HasSuffix(host, "."+d)
+	})
+}
+
+func newCA(commonName string, hosts []string) (*CA, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成 CA 私钥失败: %w", err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          randomSerial(),
+		Subject:               pkix.Name{CommonName: commonName, Organization: []string{"CSE Local"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+		// 即使 CA 私钥泄露，签发的证书也只对本机服务的主机名有效
+		PermittedDNSDomainsCritical: true,
+	}
+	template.PermittedDNSDomains, template.PermittedIPRanges = nameConstraints(hosts)
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成 CA 证书失败: %w", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &CA{Cert: cert, Key: key, PEM: pemCert(der)}, der, nil
+}
+
+// Issue 使用 CA 签发证书。hosts 中的 IP 地址与域名分别写入证书的 IP 与 DNS 备用名称
+func (ca *CA) Issue(commonName string, hosts []string, usage []x509.ExtKeyUsage, validity time.Duration) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("生成私钥失败: %w", err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: randomSerial(),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"CSE Local"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  usage,
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, key.Public(), ca.Key)
+	if err != nil {
+		return nil, fmt.Errorf("签发证书失败: %w", err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	return &tls.Certificate{
+		Certificate: [][]byte{der, ca.Cert.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// LoadOrIssueServerCert 从 dir 读取服务器证书。证书不存在、即将到期、不是由 ca 签发
+// 或未覆盖所有 hosts 时重新签发并保存。CA 的名称约束不允许的主机名不写入证书
+func LoadOrIssueServerCert(dir string, ca *CA, hosts []string) (*tls.Certificate, error) {
+	hosts = slices.DeleteFunc(slices.Clone(hosts), func(h string) bool {
+		if ca.permits(h) {
+			return false
+		}
+		slog.Warn("本地 CA 的名称约束不包含该主机名，服务器证书不覆盖它；删除本地 CA 后重新生成并安装即可包含新的主机名", "host", h)
+		return true
+	})
+	certPath := filepath.Join(dir, serverCertFile)
+	keyPath := filepath.Join(dir, serverKeyFile)
+
+	cert, key, _, err := loadPair(certPath, keyPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("读取服务器证书失败，将重新签发", "error", err)
+	}
+	if err == nil && serverCertValid(cert, ca, hosts) {
+		return &tls.Certificate{Certificate: [][]byte{cert.Raw, ca.Cert.Raw}, PrivateKey: key, Leaf: cert}, nil
+	}
+
+	issued, err := ca.Issue("CSE Local Server", hosts, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, serverValidity)
+	if err != nil {
+		return nil, err
+	}
+	if err := savePair(certPath, keyPath, issued.Certificate[0], issued.PrivateKey.(crypto.Signer)); err != nil {
+		return nil, err
+	}
+	slog.Info("已签发服务器证书", "hosts", hosts, "not_after", issued.Leaf.NotAfter.Format(time.DateOnly))
+	return issued, nil
+}
+
+// serverCertValid 判断已有的服务器证书是否可以继续使用
+func serverCertValid(cert *x509.Certificate, ca *CA, hosts []string) bool {
+	if time.Until(cert.NotAfter) < renewBefore {
+		return false
+	}
+	if err := cert.CheckSignatureFrom(ca.Cert); err != nil {
+		return false
+	}
+	for _, h := range hosts {
+		if err := cert.VerifyHostname(h); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadKeyPair 读取用户配置的证书与私钥
+func LoadKeyPair(certFile, keyFile string) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("无法加载证书 '%s': %w", certFile, err)
+	}
+	return &cert, nil
+}
+
+// loadPair 读取 PEM 格式的证书与私钥，证书文件不存在时返回 os.ErrNotExist
+func loadPair(certPath, keyPath string) (*x509.Certificate, crypto.Signer, []byte, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, nil, nil, fmt.Errorf("'%s' 不是 PEM 格式的证书", certPath)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("解析证书 '%s' 失败: %w", certPath, err)
+	}
+	block, _ = pem.Decode(keyPEM)
+	if block == nil {
+		return nil, nil, nil, fmt.Errorf("'%s' 不是 PEM 格式的私钥", keyPath)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("解析私钥 '%s' 失败: %w", keyPath, err)
+	}
+	key, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("不支持私钥 '%s' 的类型", keyPath)
+	}
+	return cert, key, certPEM, nil
+}
+
+// savePair 保存证书与私钥，私钥文件只有当前用户可读
+func savePair(certPath, keyPath string, der []byte, key crypto.Signer) error {
+	if err := os.MkdirAll(filepath.Dir(certPath), 0o700); err != nil {
+		return fmt.Errorf("无法创建证书目录: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("编码私钥失败: %w", err)
+	}
+	if err := os.WriteFile(keyPath, pemKey(keyDER), 0o600); err != nil {
+		return fmt.Errorf("保存私钥失败: %w", err)
+	}
+	if err := os.WriteFile(certPath, pemCert(der), 0o644); err != nil {
+		return fmt.Errorf("保存证书失败: %w", err)
+	}
+	return nil
+}
+
+func pemCert(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func pemKey(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func randomSerial() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		panic(err)
+	}
+	return serial
+}
+
+// ServerHosts 返回服务器证书需要覆盖的主机名：回环地址以及额外允许的主机名 (去掉端口)
+func ServerHosts(extra []string) []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	for _, h := range extra {
+		if host, _, err := net.SplitHostPort(h); err == nil {
+			h = host
+		}
+		h = strings.Trim(h, "[]")
+		if h != "" && !slices.Contains(hosts, h) {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
@@ -0,0 +1,68 @@
+package certs
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestCAAndServerCert(t *testing.T) {
+	dir := t.TempDir()
+	hosts := ServerHosts([]string{"printer.local:18848", "[::1]", "192.168.1.10"})
+	if len(hosts) != 5 || hosts[3] != "printer.local" {
+		t.Fatalf("证书主机名不正确: %v", hosts)
+	}
+	ca, err := LoadOrCreateCA(dir, "Test CA", hosts)
+	if err != nil {
+		t.Fatalf("生成 CA 失败: %v", err)
+	}
+	reloaded, err := LoadOrCreateCA(dir, "Test CA", hosts)
+	if err != nil || !reloaded.Cert.Equal(ca.Cert) {
+		t.Fatalf("再次加载应得到同一个 CA: %v", err)
+	}
+	if !ca.Cert.PermittedDNSDomainsCritical || len(ca.Cert.PermittedDNSDomains) != 2 || len(ca.Cert.PermittedIPRanges) != 3 {
+		t.Errorf("CA 的名称约束不正确: %v %v", ca.Cert.PermittedDNSDomains, ca.Cert.PermittedIPRanges)
+	}
+	cert, err := LoadOrIssueServerCert(dir, ca, hosts)
+	if err != nil {
+		t.Fatalf("签发服务器证书失败: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.PEM)
+	for _, host := range hosts {
+		if _, err := cert.Leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: roots}); err != nil {
+			t.Errorf("证书对 %s 校验失败: %v", host, err)
+		}
+	}
+
+	// 主机名不变时复用已有证书，新增主机名时重新签发
+	same, _ := LoadOrIssueServerCert(dir, ca, hosts)
+	if !same.Leaf.Equal(cert.Leaf) {
+		t.Error("主机名未变化时应复用已有证书")
+	}
+	renewed, err := LoadOrIssueServerCert(dir, ca, append(hosts, "kiosk.printer.local"))
+	if err != nil || renewed.Leaf.Equal(cert.Leaf) || renewed.Leaf.VerifyHostname("kiosk.printer.local") != nil {
+		t.Errorf("新增主机名后应重新签发证书: %v", err)
+	}
+
+	// CA 的名称约束之外的主机名不写入证书，写入了也无法通过校验
+	outside, err := LoadOrIssueServerCert(dir, ca, append(hosts, "kiosk.local", "10.0.0.1"))
+	if err != nil || outside.Leaf.VerifyHostname("kiosk.local") == nil || outside.Leaf.VerifyHostname("10.0.0.1") == nil {
+		t.Errorf("不应为名称约束之外的主机名签发证书: %v", err)
+	}
+	forged, err := ca.Issue("Forged", []string{"example.com"}, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := forged.Leaf.Verify(x509.VerifyOptions{DNSName: "example.com", Roots: roots}); err == nil {
+		t.Error("名称约束之外的证书应校验失败")
+	}
+
+	// 更换 CA 后重新签发
+	other, _ := LoadOrCreateCA(t.TempDir(), "Other CA", hosts)
+	reissued, _ := LoadOrIssueServerCert(dir, other, hosts)
+	if reissued.Leaf.CheckSignatureFrom(other.Cert) != nil {
+		t.Error("CA 变化后应使用新 CA 重新签发证书")
+	}
+}
@@ -0,0 +1,240 @@
+package certs
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"cse-go/internal/mtls"
+)
+
+// componentHosts 为组件证书覆盖的主机名，组件只在回环地址上提供服务
+var componentHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+var bothUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+
+// Internal 是 Supervisor 与组件之间 mTLS 使用的内部 PKI。CA 只存在于内存中，
+// Supervisor 每次启动都会重新生成；组件证书写入 dir 下以组件命名的子目录，
+// 有效期过半后自动续签，组件在下次握手时加载新证书
+type Internal struct {
+	ca       *CA
+	pool     *x509.CertPool
+	dir      string
+	validity time.Duration
+
+	mu     sync.Mutex
+	self   *tls.Certificate
+	issued map[string]time.Time // 组件名称 -> 证书到期时间
+	owners map[string]owner     // 组件名称 -> 证书文件的所有者
+	stop   chan struct{}
+}
+
+// owner 是以其他用户运行的组件的证书文件所有者
+type owner struct {
+	uid, gid int
+}
+
+// NewInternal 创建内部 PKI 并清除 dir 中上次运行遗留的组件证书
+func NewInternal(dir string, validity time.Duration) (*Internal, error) {
+	ca, err := NewCA("CSE Internal CA", componentHosts)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("无法清理组件证书目录: %w", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	p := &Internal{ca: ca, pool: pool, dir: dir, validity: validity, issued: make(map[string]time.Time), owners: make(map[string]owner)}
+	if _, err := p.selfCert(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// selfCert 返回 Supervisor 自身的证书，有效期过半时重新签发
+func (p *Internal) selfCert() (*tls.Certificate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.self != nil && !p.needsRenewal(p.self.Leaf.NotAfter) {
+		return p.self, nil
+	}
+	cert, err := p.ca.Issue(mtls.SupervisorName, componentHosts, bothUsages, p.validity)
+	if err != nil {
+		return nil, err
+	}
+	p.self = cert
+	return cert, nil
+}
+
+func (p *Internal) needsRenewal(notAfter time.Time) bool {
+	return time.Until(notAfter) < p.validity/2
+}
+
+// SetOwner 使之后为组件签发 (包括续签) 的证书文件属于 uid 与 gid，供以其他用户运行的组件读取。
+// 证书目录改为只允许其他用户进入，各组件的子目录仍只有所有者可以访问；证书目录的上级目录需要对该用户可进入
+func (p *Internal) SetOwner(name string, uid, gid int) error {
+	if err := os.MkdirAll(p.dir, 0o711); err != nil {
+		return fmt.Errorf("无法创建组件证书目录: %w", err)
+	}
+	if err := os.Chmod(p.dir, 0o711); err != nil {
+		return fmt.Errorf("无法设置组件证书目录权限: %w", err)
+	}
+	p.mu.Lock()
+	p.owners[name] = owner{uid, gid}
+	p.mu.Unlock()
+	return nil
+}
+
+// IssueComponent 为组件签发证书并写入文件，返回传递给组件的文件路径
+func (p *Internal) IssueComponent(name string) (mtls.Files, error) {
+	if name == "" || name != filepath.Base(name) {
+		return mtls.Files{}, fmt.Errorf("组件名称 '%s' 无效", name)
+	}
+	dir := filepath.Join(p.dir, name)
+	files := mtls.Files{
+		CA:   filepath.Join(dir, "ca.pem"),
+		Cert: filepath.Join(dir, "cert.pem"),
+		Key:  filepath.Join(dir, "key.pem"),
+	}
+	cert, err := p.ca.Issue(name, componentHosts, bothUsages, p.validity)
+	if err != nil {
+		return mtls.Files{}, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return mtls.Files{}, fmt.Errorf("无法创建组件证书目录: %w", err)
+	}
+	var o *owner
+	p.mu.Lock()
+	if v, ok := p.owners[name]; ok {
+		o = &v
+	}
+	p.mu.Unlock()
+	if o != nil {
+		if err := os.Chown(dir, o.uid, o.gid); err != nil {
+			return mtls.Files{}, fmt.Errorf("无法设置组件证书目录的所有者: %w", err)
+		}
+	}
+	if err := writeFileAtomic(files.CA, p.ca.PEM, 0o644, o); err != nil {
+		return mtls.Files{}, err
+	}
+	// 先替换私钥再替换证书，组件以证书文件的修改时间判断是否需要重新加载
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey.(crypto.Signer))
+	if err != nil {
+		return mtls.Files{}, fmt.Errorf("编码私钥失败: %w", err)
+	}
+	if err := writeFileAtomic(files.Key, pemKey(keyDER), 0o600, o); err != nil {
+		return mtls.Files{}, err
+	}
+	if err := writeFileAtomic(files.Cert, pemCert(cert.Certificate[0]), 0o644, o); err != nil {
+		return mtls.Files{}, err
+	}
+
+	p.mu.Lock()
+	p.issued[name] = cert.Leaf.NotAfter
+	p.mu.Unlock()
+	return files, nil
+}
+
+// Renew 续签有效期已过半的 Supervisor 证书与组件证书
+func (p *Internal) Renew() {
+	if _, err := p.selfCert(); err != nil {
+		slog.Error("续签 Supervisor 内部证书失败", "error", err)
+	}
+	p.mu.Lock()
+	var names []string
+	for name, notAfter := range p.issued {
+		if p.needsRenewal(notAfter) {
+			names = append(names, name)
+		}
+	}
+	p.mu.Unlock()
+	for _, name := range names {
+		if _, err := p.IssueComponent(name); err != nil {
+			slog.Error("续签组件证书失败", "component", name, "error", err)
+			continue
+		}
+		slog.Info("已续签组件证书", "component", name)
+	}
+}
+
+// Start 启动后台续签
+func (p *Internal) Start(interval time.Duration) {
+	p.mu.Lock()
+	if p.stop != nil {
+		p.mu.Unlock()
+		return
+	}
+	p.stop = make(chan struct{})
+	stop := p.stop
+	p.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.Renew()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台续签
+func (p *Internal) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+}
+
+// ServerConfig 返回发现服务使用的配置：要求组件提供由内部 CA 签发的证书。
+// 证书名称与注册的组件名称是否一致由发现服务检查
+func (p *Internal) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS13,
+		ClientAuth:     tls.RequireAndVerifyClientCert,
+		ClientCAs:      p.pool,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return p.selfCert() },
+	}
+}
+
+// ClientConfig 返回连接组件时使用的配置，只接受名称为 component 的组件证书
+func (p *Internal) ClientConfig(component string) *tls.Config {
+	return &tls.Config{
+		MinVersion:           tls.VersionTLS13,
+		RootCAs:              p.pool,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) { return p.selfCert() },
+		VerifyConnection:     mtls.VerifyPeerName(component),
+	}
+}
+
+// writeFileAtomic 先写入临时文件再重命名，避免读取方看到写了一半的文件。o 不为 nil 时在重命名前设置文件所有者
+func writeFileAtomic(path string, data []byte, perm os.FileMode, o *owner) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return fmt.Errorf("写入 '%s' 失败: %w", path, err)
+	}
+	if o != nil {
+		if err := os.Chown(tmp, o.uid, o.gid); err != nil {
+			os.Remove(tmp)
+			return fmt.Errorf("设置 '%s' 的所有者失败: %w", path, err)
+		}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入 '%s' 失败: %w", path, err)
+	}
+	return nil
+}
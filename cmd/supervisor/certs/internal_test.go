@@ -0,0 +1,141 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cse-go/internal/mtls"
+)
+
+// handshake 在内存连接上完成一次 TLS 握手，返回客户端与服务端的错误以及服务端看到的连接状态
+func handshake(client, server *tls.Config) (clientErr, serverErr error, state tls.ConnectionState) {
+	c, s := net.Pipe()
+	defer c.Close()
+	defer s.Close()
+	if client.ServerName == "" {
+		client.ServerName = "localhost"
+	}
+	tc, ts := tls.Client(c, client), tls.Server(s, server)
+	done := make(chan struct{})
+	go func() {
+		serverErr = ts.Handshake()
+		state = ts.ConnectionState()
+		// 握手失败的一方关闭连接，避免另一方一直等待
+		if serverErr != nil {
+			s.Close()
+		}
+		close(done)
+	}()
+	clientErr = tc.Handshake()
+	if clientErr != nil {
+		c.Close()
+	}
+	<-done
+	return clientErr, serverErr, state
+}
+
+func TestInternalMutualTLS(t *testing.T) {
+	pki, err := NewInternal(filepath.Join(t.TempDir(), "components"), time.Hour)
+	if err != nil {
+		t.Fatalf("初始化内部 PKI 失败: %v", err)
+	}
+	printer, err := pki.IssueComponent("printer")
+	if err != nil {
+		t.Fatalf("签发组件证书失败: %v", err)
+	}
+	other, _ := pki.IssueComponent("other")
+	if fi, err := os.Stat(printer.Key); err != nil || fi.Mode().Perm()&0o077 != 0 {
+		t.Errorf("组件私钥应只有当前用户可读: %v", err)
+	}
+	if _, err := pki.IssueComponent("../escape"); err == nil {
+		t.Error("包含路径的组件名称应被拒绝")
+	}
+
+	componentServer, err := mtls.ComponentServerConfig(printer)
+	if err != nil {
+		t.Fatalf("加载组件服务端配置失败: %v", err)
+	}
+	componentClient, _ := mtls.ComponentClientConfig(printer)
+
+	// Supervisor 连接组件
+	if cErr, sErr, _ := handshake(pki.ClientConfig("printer"), componentServer); cErr != nil || sErr != nil {
+		t.Fatalf("Supervisor 连接组件失败: %v / %v", cErr, sErr)
+	}
+	// 组件向发现服务注册，服务端能取得组件名称
+	cErr, sErr, state := handshake(componentClient, pki.ServerConfig())
+	if cErr != nil || sErr != nil || mtls.PeerName(state) != "printer" {
+		t.Fatalf("组件连接发现服务失败: %v / %v (%s)", cErr, sErr, mtls.PeerName(state))
+	}
+
+	// 其他组件的证书不能冒充 printer，也不能调用 printer 的服务
+	otherServer, _ := mtls.ComponentServerConfig(other)
+	if cErr, _, _ := handshake(pki.ClientConfig("printer"), otherServer); cErr == nil {
+		t.Error("Supervisor 应拒绝名称不符的组件证书")
+	}
+	otherClient, _ := mtls.ComponentClientConfig(other)
+	if _, sErr, _ := handshake(otherClient, componentServer); sErr == nil {
+		t.Error("组件应拒绝 Supervisor 以外的客户端")
+	}
+
+	// 其他 CA 签发的证书不被接受
+	foreign, _ := NewInternal(filepath.Join(t.TempDir(), "components"), time.Hour)
+	if _, sErr, _ := handshake(foreign.ClientConfig("printer"), componentServer); sErr == nil {
+		t.Error("组件应拒绝其他 CA 签发的证书")
+	}
+}
+
+func TestInternalRenew(t *testing.T) {
+	pki, err := NewInternal(filepath.Join(t.TempDir(), "components"), time.Hour)
+	if err != nil {
+		t.Fatalf("初始化内部 PKI 失败: %v", err)
+	}
+	files, _ := pki.IssueComponent("printer")
+	before := readLeaf(t, files.Cert)
+	server, _ := mtls.ComponentServerConfig(files)
+
+	// 有效期未过半时不续签
+	pki.Renew()
+	if !readLeaf(t, files.Cert).Equal(before) {
+		t.Fatal("有效期未过半时不应续签")
+	}
+
+	pki.mu.Lock()
+	pki.issued["printer"] = time.Now().Add(10 * time.Minute)
+	pki.mu.Unlock()
+	pki.Renew()
+	after := readLeaf(t, files.Cert)
+	if after.Equal(before) || after.Subject.CommonName != "printer" {
+		t.Fatal("有效期过半后应续签组件证书")
+	}
+
+	// 已运行的组件在下次握手时加载续签后的证书
+	client := pki.ClientConfig("printer")
+	var served *x509.Certificate
+	client.VerifyConnection = func(cs tls.ConnectionState) error {
+		served = cs.PeerCertificates[0]
+		return nil
+	}
+	if cErr, sErr, _ := handshake(client, server); cErr != nil || sErr != nil || !served.Equal(after) {
+		t.Errorf("组件应使用续签后的证书: %v / %v", cErr, sErr)
+	}
+}
+
+func readLeaf(t *testing.T, file string) *x509.Certificate {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
@@ -0,0 +1,49 @@
+//go:build unix
+
+package certs
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestInternalSetOwner(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "components")
+	pki, err := NewInternal(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("初始化内部 PKI 失败: %v", err)
+	}
+	// 非 root 运行时只能将文件交给自己
+	uid, gid := os.Getuid(), os.Getgid()
+	if uid == 0 {
+		uid, gid = 65534, 65534
+	}
+	if err := pki.SetOwner("printer", uid, gid); err != nil {
+		t.Fatal(err)
+	}
+	files, err := pki.IssueComponent("printer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 续签后的文件同样属于该用户
+	pki.mu.Lock()
+	pki.issued["printer"] = time.Now()
+	pki.mu.Unlock()
+	pki.Renew()
+
+	for _, path := range []string{filepath.Dir(files.Key), files.CA, files.Cert, files.Key} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st := info.Sys().(*syscall.Stat_t); int(st.Uid) != uid || int(st.Gid) != gid {
+			t.Errorf("%s 的所有者为 %d:%d，期望 %d:%d", path, st.Uid, st.Gid, uid, gid)
+		}
+	}
+	if info, _ := os.Stat(dir); info.Mode().Perm() != 0o711 {
+		t.Errorf("证书目录的权限为 %v，期望只允许其他用户进入", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,249 @@
+// Package config 读取 Supervisor 自身的配置文件
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+
+	"cse-go/cmd/supervisor/auth"
+)
+
+// Config 是 Supervisor 的配置
+type Config struct {
+	Auth       Auth       `json:"auth"`
+	HTTP       HTTP       `json:"http"`
+	Components Components `json:"components"`
+	Logging    Logging    `json:"logging"`
+	Tracing    Tracing    `json:"tracing"`
+	Shutdown   Shutdown   `json:"shutdown"`
+	Secrets    Secrets    `json:"secrets"`
+}
+
+// Secrets 是组件 settings 中以 {"$secret": "<名称>"} 引用的 secret 的存储配置。
+// secret 加密保存在 File 中，主密钥 (base64 编码的 32 字节，可用 --generate-secrets-key 生成)
+// 从环境变量 CSE_SECRETS_KEY 或 KeyFile 读取，都未配置时不能使用 secret
+type Secrets struct {
+	// File 为加密的 secret 文件，默认为 <data-dir>/secrets.enc
+	File string `json:"file"`
+	// KeyFile 为保存主密钥的文件，应只有运行 Supervisor 的用户可读
+	KeyFile string `json:"key_file"`
+}
+
+// Shutdown 是收到 SIGINT 或 SIGTERM 后的关闭配置
+type Shutdown struct {
+	// DrainTimeoutSeconds 为等待进行中的 HTTP 请求 (包括组件命令) 完成的时间 (秒)
+	DrainTimeoutSeconds int `json:"drain_timeout_seconds"`
+	// ComponentTimeoutSeconds 为每个组件收到关闭请求后退出的时间 (秒)，超时后强制终止
+	ComponentTimeoutSeconds int `json:"component_timeout_seconds"`
+}
+
+// Tracing 是请求调用链的记录配置。请求 ID 与 traceparent 总是会传递，
+// 启用后 Supervisor 与各组件的 span 以 OTLP JSON 格式写入 <Dir>/<进程名>.jsonl
+type Tracing struct {
+	Enabled bool `json:"enabled"`
+	// Dir 为 span 文件的目录，默认为 <data-dir>/traces
+	Dir string `json:"dir"`
+}
+
+// Logging 是 Supervisor 与组件的日志配置
+type Logging struct {
+	// Level 为 Supervisor 的日志级别: debug、info、warn 或 error
+	Level string `json:"level"`
+	// Format 为 Supervisor 的日志格式: text 或 json
+	Format string `json:"format"`
+	// Dir 为组件日志文件的目录，默认为 <data-dir>/logs
+	Dir string `json:"dir"`
+	// MaxSizeMB 为单个组件日志文件的大小上限 (MB)，MaxFiles 为每个组件保留的文件数量
+	MaxSizeMB int `json:"max_size_mb"`
+	MaxFiles  int `json:"max_files"`
+	// MirrorComponents 为 true 时组件输出同时以 "[组件名]" 为前缀写入 Supervisor 的标准错误
+	MirrorComponents bool `json:"mirror_components"`
+	// Components 为各组件的日志级别，通过 --log-level 参数传递给组件
+	Components map[string]string `json:"components"`
+}
+
+// 组件 gRPC 连接的传输方式
+const (
+	TransportTCP  = "tcp"
+	TransportUnix = "unix"
+)
+
+// Components 是 Supervisor 与组件之间 gRPC 连接的配置
+type Components struct {
+	// Transport 为 tcp (回环地址上的 TCP 端口) 或 unix (Unix 域套接字)
+	Transport string `json:"transport"`
+	// RuntimeDir 为存放套接字文件的目录，默认为每次启动新建的临时目录。
+	// 多个 Supervisor 同时运行时必须使用不同的目录
+	RuntimeDir string `json:"runtime_dir"`
+	// ReadyTimeoutSeconds 为启动时等待被依赖的组件注册并进入运行状态的时间 (秒)
+	ReadyTimeoutSeconds int `json:"ready_timeout_seconds"`
+	// CgroupDir 为配置了 cgroup 的组件的父 cgroup 目录 (cgroup v2，如 /sys/fs/cgroup/cse)，
+	// 其上级 cgroup 需要已对子 cgroup 启用组件使用的 memory、cpu 与 pids 控制器
+	CgroupDir string `json:"cgroup_dir"`
+}
+
+// HTTP 是 HTTP API 的浏览器访问配置
+type HTTP struct {
+	// AllowedOrigins 为允许跨域调用的网页来源，例如 "https://pos.example.com"，
+	// 支持 path.Match 通配符 (如 "https://*.example.com")，不允许使用 "*" 放行所有来源
+	AllowedOrigins []string `json:"allowed_origins"`
+	// AllowedHosts 为监听地址之外允许的 Host 请求头，未指定端口时使用监听端口
+	AllowedHosts []string `json:"allowed_hosts"`
+	// AllowPrivateNetwork 为 true 时在预检响应中允许公网页面访问本地网络 (Private Network Access)
+	AllowPrivateNetwork bool `json:"allow_private_network"`
+	// CORSMaxAge 为预检结果的缓存时间 (秒)
+	CORSMaxAge int `json:"cors_max_age"`
+	// DisablePlain 为 true 时只提供 HTTPS 服务
+	DisablePlain bool `json:"disable_plain"`
+	TLS          TLS  `json:"tls"`
+}
+
+// TLS 是 HTTPS 监听配置。未配置证书时使用本地 CA 签发的 localhost 证书
+type TLS struct {
+	Enabled  bool   `json:"enabled"`
+	Addr     string `json:"addr"`
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+}
+
+// Auth 是 HTTP API 的认证配置
+type Auth struct {
+	// Enabled 为 false 时不进行认证，默认启用
+	Enabled bool        `json:"enabled"`
+	Keys    []*auth.Key `json:"keys"`
+}
+
+// defaults 返回未配置时使用的默认值
+func defaults() *Config {
+	return &Config{
+		Auth: Auth{Enabled: true},
+		HTTP: HTTP{
+			CORSMaxAge: 600,
+			TLS:        TLS{Addr: "localhost:18849"},
+		},
+		Components: Components{Transport: TransportTCP, ReadyTimeoutSeconds: 30},
+		Logging: Logging{
+			Level:            "info",
+			Format:           "text",
+			MaxSizeMB:        10,
+			MaxFiles:         5,
+			MirrorComponents: true,
+		},
+		Shutdown: Shutdown{
+			DrainTimeoutSeconds:     30,
+			ComponentTimeoutSeconds: 10,
+		},
+	}
+}
+
+// Load 读取配置文件，文件不存在时返回默认配置
+func Load(path string) (*Config, error) {
+	cfg := defaults()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取配置文件 '%s': %w", path, err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("无法解析配置文件 '%s': %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 '%s' 无效: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// validate 检查配置取值
+func (c *Config) validate() error {
+	if (c.HTTP.TLS.CertFile == "") != (c.HTTP.TLS.KeyFile == "") {
+		return errors.New("tls.cert_file 与 tls.key_file 必须同时配置")
+	}
+	if c.HTTP.DisablePlain && !c.HTTP.TLS.Enabled {
+		return errors.New("disable_plain 需要同时启用 tls")
+	}
+	if c.Components.Transport != TransportTCP && c.Components.Transport != TransportUnix {
+		return fmt.Errorf("components.transport 必须是 %s 或 %s", TransportTCP, TransportUnix)
+	}
+	if err := validLevel(c.Logging.Level); err != nil {
+		return fmt.Errorf("logging.level: %w", err)
+	}
+	for name, level := range c.Logging.Components {
+		if err := validLevel(level); err != nil {
+			return fmt.Errorf("logging.components.%s: %w", name, err)
+		}
+	}
+	if c.Logging.Format != "text" && c.Logging.Format != "json" {
+		return errors.New("logging.format 必须是 text 或 json")
+	}
+	if c.Components.ReadyTimeoutSeconds <= 0 {
+		return errors.New("components.ready_timeout_seconds 必须大于 0")
+	}
+	if c.Shutdown.DrainTimeoutSeconds <= 0 || c.Shutdown.ComponentTimeoutSeconds <= 0 {
+		return errors.New("shutdown 的超时时间必须大于 0")
+	}
+	for _, origin := range c.HTTP.AllowedOrigins {
+		if origin == "*" {
+			return errors.New(`allowed_origins 不允许使用 "*"，请列出具体的来源`)
+		}
+		if _, err := path.Match(origin, ""); err != nil {
+			return fmt.Errorf("allowed_origins 中的 '%s' 无效: %w", origin, err)
+		}
+	}
+	return nil
+}
+
+// validLevel 检查日志级别名称
+func validLevel(level string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("无效的日志级别 '%s'", level)
+	}
+	return nil
+}
+
+// SaveSection 将配置文件中的一个顶层字段替换为 v，保留其余字段原样，文件不存在时创建
+func SaveSection(path, section string, v any) error {
+	sections := map[string]json.RawMessage{}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("无法读取配置文件 '%s': %w", path, err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &sections); err != nil {
+			return fmt.Errorf("无法解析配置文件 '%s': %w", path, err)
+		}
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sections[section] = value
+
+	out, err := json.MarshalIndent(sections, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("无法写入配置文件: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(out, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("无法写入配置文件: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("无法写入配置文件: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		return fmt.Errorf("无法设置配置文件权限: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
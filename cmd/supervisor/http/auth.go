@@ -0,0 +1,156 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"cse-go/cmd/supervisor/auth"
+)
+
+// keyContextKey 是请求上下文中保存已认证密钥的键
+type keyContextKey struct{}
+
+// keyFromContext 返回请求使用的密钥，未启用认证时返回 nil
+func keyFromContext(ctx context.Context) *auth.Key {
+	key, _ := ctx.Value(keyContextKey{}).(*auth.Key)
+	return key
+}
+
+// bearerToken 从 Authorization 请求头中读取 Bearer 令牌
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// publicPaths 是不需要认证即可访问的路径
+var publicPaths = map[string]bool{
+	"/api/v1/tls/ca.pem": true,
+	// 健康检查供看门狗与安装程序探测，不需要访问密钥
+	"/healthz": true,
+	"/readyz":  true,
+}
+
+// authenticate 要求请求携带有效的 Bearer 令牌，并将对应的密钥放入请求上下文
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.keys.Enabled() || publicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := s.keys.Authenticate(bearerToken(r))
+		if key == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="cse"`)
+			writeError(w, http.StatusUnauthorized, "缺少或无效的访问令牌")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), keyContextKey{}, key)))
+	})
+}
+
+// allowed 判断当前请求的密钥能否调用组件的命令，未启用认证时总是允许
+func allowed(r *http.Request, component, command string) bool {
+	key := keyFromContext(r.Context())
+	return key == nil || key.Allows(component, command)
+}
+
+// keysAuthDisabled 为未启用认证时管理访问密钥的错误信息
+const keysAuthDisabled = "未启用认证，无法管理密钥"
+
+// requireAdmin 在启用认证且密钥不是管理员密钥时返回 403，返回值表示是否可以继续处理。
+// 未启用认证时，disabled 为空则允许访问，否则以 disabled 为错误信息返回 403
+func (s *Server) requireAdmin(w http.ResponseWriter, r *http.Request, disabled string) bool {
+	if !s.keys.Enabled() {
+		if disabled == "" {
+			return true
+		}
+		writeError(w, http.StatusForbidden, disabled)
+		return false
+	}
+	if key := keyFromContext(r.Context()); key == nil || !key.Admin {
+		writeError(w, http.StatusForbidden, "需要管理员密钥")
+		return false
+	}
+	return true
+}
+
+// createKeyRequest 定义了 POST /api/v1/keys 的请求体结构
+type createKeyRequest struct {
+	Name       string   `json:"name"`
+	Admin      bool     `json:"admin"`
+	Components []string `json:"components"`
+	Commands   []string `json:"commands"`
+}
+
+// keysHandler 列出 (GET) 或创建 (POST) 访问密钥，需要管理员密钥
+func (s *Server) keysHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.requireAdmin(w, r, keysAuthDisabled) {
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			keys := s.keys.List()
+			for _, key := range keys {
+				key.Hash = ""
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"keys": keys})
+		case http.MethodPost:
+			var req createKeyRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid request body")
+				return
+			}
+			token, key, err := s.keys.Create(auth.Key{
+				Name:       req.Name,
+				Admin:      req.Admin,
+				Components: req.Components,
+				Commands:   req.Commands,
+			})
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			slog.InfoContext(r.Context(), "已创建访问密钥", "key", key.Name, "by", keyFromContext(r.Context()).Name)
+			key.Hash = ""
+			writeJSON(w, http.StatusCreated, map[string]any{"key": key, "token": token})
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// keyHandler 删除 (DELETE) 指定名称的访问密钥，需要管理员密钥
+func (s *Server) keyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !s.requireAdmin(w, r, keysAuthDisabled) {
+			return
+		}
+		name := r.PathValue("name")
+		current := keyFromContext(r.Context())
+		if name == current.Name {
+			writeError(w, http.StatusBadRequest, "不能删除当前请求使用的密钥")
+			return
+		}
+		if err := s.keys.Delete(name); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, auth.ErrKeyNotFound) {
+				status = http.StatusNotFound
+			}
+			writeError(w, status, err.Error())
+			return
+		}
+		slog.InfoContext(r.Context(), "已删除访问密钥", "key", name, "by", current.Name)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
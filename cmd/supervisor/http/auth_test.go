@@ -0,0 +1,117 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cse-go/cmd/supervisor/auth"
+	"cse-go/cmd/supervisor/config"
+	"cse-go/cmd/supervisor/manager"
+)
+
+func newTestServer(t *testing.T) (http.Handler, *auth.Store) {
+	t.Helper()
+	s := newServerForTest(t)
+	return s.authenticate(s.setupRoutes()), s.keys
+}
+
+// newServerForTest 创建带有管理员密钥 admin-token 与受限密钥 kiosk-token 的服务器
+func newServerForTest(t *testing.T) *Server {
+	t.Helper()
+	keys, err := auth.NewStore(true, []*auth.Key{
+		{Name: "admin", Hash: auth.HashToken("admin-token"), Admin: true},
+		{Name: "kiosk", Hash: auth.HashToken("kiosk-token"), Components: []string{"printer"}, Commands: []string{"print.printReceipt"}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewServer("", manager.NewComponentManager(nil), keys, config.HTTP{})
+}
+
+func do(h http.Handler, method, path, token string, body any) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthentication(t *testing.T) {
+	h, _ := newTestServer(t)
+
+	rec := do(h, http.MethodGet, "/api/v1/components", "", nil)
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("缺少令牌时应返回 401，实际 %d", rec.Code)
+	}
+	if rec := do(h, http.MethodGet, "/api/v1/components", "wrong", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("无效令牌应返回 401，实际 %d", rec.Code)
+	}
+	if rec := do(h, http.MethodGet, "/api/v1/components", "kiosk-token", nil); rec.Code != http.StatusOK {
+		t.Errorf("有效令牌应返回 200，实际 %d", rec.Code)
+	}
+
+	execute := map[string]any{"component_name": "printer", "command_name": "print.setDefaultPrinter"}
+	if rec := do(h, http.MethodPost, "/api/v1/execute", "kiosk-token", execute); rec.Code != http.StatusForbidden {
+		t.Errorf("未授权的命令应返回 403，实际 %d", rec.Code)
+	}
+	// 已授权的命令通过认证后才检查组件是否存在
+	execute["command_name"] = "print.printReceipt"
+	if rec := do(h, http.MethodPost, "/api/v1/execute", "kiosk-token", execute); rec.Code != http.StatusNotFound {
+		t.Errorf("已授权的命令应继续处理，实际 %d", rec.Code)
+	}
+}
+
+func TestKeyManagement(t *testing.T) {
+	h, _ := newTestServer(t)
+
+	if rec := do(h, http.MethodGet, "/api/v1/keys", "kiosk-token", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("非管理员密钥应返回 403，实际 %d", rec.Code)
+	}
+
+	rec := do(h, http.MethodPost, "/api/v1/keys", "admin-token", map[string]any{"name": "pos", "components": []string{"printer"}})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("创建密钥应返回 201，实际 %d: %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		Token string   `json:"token"`
+		Key   auth.Key `json:"key"`
+	}
+	json.NewDecoder(rec.Body).Decode(&created)
+	if created.Token == "" || created.Key.Hash != "" {
+		t.Fatalf("创建结果应包含令牌且不包含摘要: %+v", created)
+	}
+	if rec := do(h, http.MethodGet, "/api/v1/components", created.Token, nil); rec.Code != http.StatusOK {
+		t.Errorf("新密钥应可以使用，实际 %d", rec.Code)
+	}
+
+	rec = do(h, http.MethodGet, "/api/v1/keys", "admin-token", nil)
+	var list struct {
+		Keys []auth.Key `json:"keys"`
+	}
+	json.NewDecoder(rec.Body).Decode(&list)
+	if len(list.Keys) != 3 || list.Keys[0].Hash != "" {
+		t.Errorf("密钥列表不正确: %+v", list.Keys)
+	}
+
+	if rec := do(h, http.MethodDelete, "/api/v1/keys/admin", "admin-token", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("不应能删除当前使用的密钥，实际 %d", rec.Code)
+	}
+	if rec := do(h, http.MethodDelete, "/api/v1/keys/pos", "admin-token", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("删除密钥应返回 204，实际 %d", rec.Code)
+	}
+	if rec := do(h, http.MethodDelete, "/api/v1/keys/pos", "admin-token", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("删除不存在的密钥应返回 404，实际 %d", rec.Code)
+	}
+	if rec := do(h, http.MethodGet, "/api/v1/components", created.Token, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("已删除的密钥应返回 401，实际 %d", rec.Code)
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	corsAllowMethods = "GET, POST, DELETE, OPTIONS"
+	corsAllowHeaders = "Authorization, Content-Type, X-CSE-User, X-Request-ID, traceparent"
+	// corsExposeHeaders 为网页可以读取的响应头，用于关联请求与日志
+	corsExposeHeaders = "X-Request-ID, traceparent"
+)
+
+// allowedHosts 返回可以接受的 Host 请求头。监听回环地址时 localhost、127.0.0.1 与 [::1] 都可以使用，
+// 其余主机名必须在配置中显式列出，以防御 DNS 重绑定攻击
+func allowedHosts(addr string, extra []string) map[string]bool {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = addr, ""
+	}
+	withPort := func(h string) string {
+		if port == "" {
+			return strings.ToLower(h)
+		}
+		return strings.ToLower(net.JoinHostPort(h, port))
+	}
+
+	hosts := map[string]bool{}
+	switch host {
+	case "", "localhost", "127.0.0.1", "::1":
+		for _, h := range []string{"localhost", "127.0.0.1", "::1"} {
+			hosts[withPort(h)] = true
+		}
+	default:
+		hosts[withPort(host)] = true
+	}
+	for _, h := range extra {
+		if _, _, err := net.SplitHostPort(h); err == nil {
+			hosts[strings.ToLower(h)] = true
+		} else {
+			hosts[withPort(strings.Trim(h, "[]"))] = true
+		}
+	}
+	return hosts
+}
+
+// validateHost 拒绝 Host 请求头不是本服务地址的请求
+func (s *Server) validateHost(next http.Handler) http.Handler {
+	hosts := allowedHosts(s.addr, s.http.AllowedHosts)
+	if s.tlsConfig != nil {
+		for h := range allowedHosts(s.http.TLS.Addr, s.http.AllowedHosts) {
+			hosts[h] = true
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hosts[strings.ToLower(r.Host)] {
+			slog.WarnContext(r.Context(), "拒绝 Host 不允许的请求", "host", r.Host, "remote_addr", r.RemoteAddr)
+			writeError(w, http.StatusMisdirectedRequest, "Invalid Host header")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed 判断网页来源是否在允许列表中
+func (s *Server) originAllowed(origin string) bool {
+	for _, pattern := range s.http.AllowedOrigins {
+		if ok, _ := path.Match(pattern, origin); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// cors 处理浏览器的跨域请求：只有允许列表中的来源可以调用 API，并在认证之前应答预检请求。
+// 不带 Origin 的请求来自非浏览器客户端，不受影响
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		if !s.originAllowed(origin) {
+			slog.WarnContext(r.Context(), "拒绝跨域请求", "origin", origin)
+			writeError(w, http.StatusForbidden, "Origin not allowed")
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+
+		// 预检请求
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Add("Vary", "Access-Control-Request-Method, Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+			if s.http.CORSMaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(s.http.CORSMaxAge))
+			}
+			if s.http.AllowPrivateNetwork && r.Header.Get("Access-Control-Request-Private-Network") == "true" {
+				w.Header().Set("Access-Control-Allow-Private-Network", "true")
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cse-go/cmd/supervisor/auth"
+	"cse-go/cmd/supervisor/config"
+	"cse-go/cmd/supervisor/manager"
+)
+
+func newCORSTestServer(t *testing.T) http.Handler {
+	t.Helper()
+	keys, err := auth.NewStore(true, []*auth.Key{{Name: "app", Hash: auth.HashToken("token")}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer("localhost:18848", manager.NewComponentManager(nil), keys, config.HTTP{
+		AllowedOrigins:      []string{"https://pos.example.com", "https://*.shop.example.com"},
+		AllowedHosts:        []string{"printer.local"},
+		AllowPrivateNetwork: true,
+		CORSMaxAge:          600,
+	})
+	return s.handler(s.setupRoutes())
+}
+
+func TestHostValidation(t *testing.T) {
+	h := newCORSTestServer(t)
+	for host, want := range map[string]int{
+		"localhost:18848":     http.StatusUnauthorized,
+		"127.0.0.1:18848":     http.StatusUnauthorized,
+		"[::1]:18848":         http.StatusUnauthorized,
+		"LOCALHOST:18848":     http.StatusUnauthorized,
+		"printer.local:18848": http.StatusUnauthorized,
+		"localhost:80":        http.StatusMisdirectedRequest,
+		"attacker.com:18848":  http.StatusMisdirectedRequest,
+		"printer.local":       http.StatusMisdirectedRequest,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/components", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("Host %s: 状态码为 %d，期望 %d", host, rec.Code, want)
+		}
+	}
+}
+
+func TestCORS(t *testing.T) {
+	h := newCORSTestServer(t)
+	request := func(method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/components", nil)
+		req.Host = "localhost:18848"
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 预检请求不需要令牌
+	rec := request(http.MethodOptions, "https://pos.example.com", map[string]string{
+		"Access-Control-Request-Method":          "POST",
+		"Access-Control-Request-Headers":         "authorization, content-type",
+		"Access-Control-Request-Private-Network": "true",
+	})
+	if rec.Code != http.StatusNoContent ||
+		rec.Header().Get("Access-Control-Allow-Origin") != "https://pos.example.com" ||
+		rec.Header().Get("Access-Control-Allow-Headers") == "" ||
+		rec.Header().Get("Access-Control-Allow-Private-Network") != "true" ||
+		rec.Header().Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("预检响应不正确: %d %v", rec.Code, rec.Header())
+	}
+
+	rec = request(http.MethodGet, "https://a.shop.example.com", map[string]string{"Authorization": "Bearer token"})
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "https://a.shop.example.com" {
+		t.Errorf("允许的来源应能访问: %d %v", rec.Code, rec.Header())
+	}
+
+	for _, origin := range []string{"https://evil.example.com", "null", "https://pos.example.com.evil.com"} {
+		rec = request(http.MethodOptions, origin, map[string]string{"Access-Control-Request-Method": "POST"})
+		if rec.Code != http.StatusForbidden || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("来源 %s 的预检应被拒绝: %d", origin, rec.Code)
+		}
+		rec = request(http.MethodGet, origin, map[string]string{"Authorization": "Bearer token"})
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("来源 %s 的请求应被拒绝，即使令牌有效: %d", origin, rec.Code)
+		}
+	}
+
+	// 非浏览器客户端不带 Origin
+	if rec := request(http.MethodGet, "", map[string]string{"Authorization": "Bearer token"}); rec.Code != http.StatusOK {
+		t.Errorf("不带 Origin 的请求应正常处理: %d", rec.Code)
+	}
+}
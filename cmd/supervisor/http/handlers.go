@@ -3,11 +3,16 @@ package http
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"cse-go/cmd/supervisor/manager"
+	"cse-go/internal/commandbus"
+	"cse-go/internal/tracing"
 	pb "cse-go/pkg/api/v1"
+
+	"google.golang.org/grpc/status"
 )
 
 // --- DTO (Data Transfer Objects) ---
@@ -22,6 +27,10 @@ type componentInfo struct {
 	Version          string            `json:"version"`
 	Description      string            `json:"description"`
 	ProvidedCommands []*pb.CommandInfo `json:"provided_commands"`
+	// Launch 为组件的启动状态，尚未注册的组件 (如等待依赖或依赖启动失败) 同样列出
+	Launch *manager.LaunchStatus `json:"launch,omitempty"`
+	// Usage 为组件进程的资源使用情况，进程未运行或当前平台不支持时省略
+	Usage *manager.ResourceUsage `json:"usage,omitempty"`
 }
 
 // executeRequest 定义了 /api/v1/execute 的请求体结构
@@ -41,22 +50,72 @@ func (s *Server) listComponentsHandler() http.HandlerFunc {
 			return
 		}
 
-		s.manager.Lock()
-		defer s.manager.Unlock()
+		configured := s.manager.Configured()
+		launch := s.manager.LaunchStatuses()
+		s.manager.RLock()
 
 		resp := listComponentsResponse{
 			Components: make([]componentInfo, 0, len(s.manager.Components)),
 		}
+		// 读取资源使用情况需要访问 /proc 或 cgroup 文件，复制组件的进程信息后在释放锁之后读取
+		processes := make(map[int]manager.ComponentInfo)
+
+		key := keyFromContext(r.Context())
+		for name, comp := range s.manager.Components {
+			if comp.Metadata == nil { // 尚未注册的组件在下面与启动失败的组件一起列出
+				continue
+			}
+			if key != nil && !key.AllowsComponent(name) {
+				continue
+			}
+			// 只列出当前密钥可以调用的命令
+			commands := make([]*pb.CommandInfo, 0, len(comp.Metadata.ProvidedCommands))
+			for _, cmd := range comp.Metadata.ProvidedCommands {
+				if key == nil || key.Allows(name, cmd.CommandName) {
+					commands = append(commands, cmd)
+				}
+			}
+			info := componentInfo{
+				Name:             comp.Metadata.Name,
+				Version:          comp.Metadata.Version,
+				Description:      comp.Metadata.Description,
+				ProvidedCommands: commands,
+			}
+			if status, ok := launch[name]; ok {
+				info.Launch = &status
+			}
+			processes[len(resp.Components)] = *comp
+			resp.Components = append(resp.Components, info)
+		}
 
-		for _, comp := range s.manager.Components {
-			if comp.Metadata != nil { // 确保组件已成功注册并返回了元数据
-				resp.Components = append(resp.Components, componentInfo{
-					Name:             comp.Metadata.Name,
-					Version:          comp.Metadata.Version,
-					Description:      comp.Metadata.Description,
-					ProvidedCommands: comp.Metadata.ProvidedCommands,
-				})
+		// 尚未注册、启动失败或被依赖阻塞的组件
+		for _, config := range configured {
+			if comp, ok := s.manager.Components[config.Name]; ok && comp.Metadata != nil {
+				continue
+			}
+			if key != nil && !key.AllowsComponent(config.Name) {
+				continue
 			}
+			status, ok := launch[config.Name]
+			if !ok {
+				continue
+			}
+			info := componentInfo{
+				Name:             config.Name,
+				Version:          config.Version,
+				Description:      config.Description,
+				ProvidedCommands: []*pb.CommandInfo{},
+				Launch:           &status,
+			}
+			if comp, ok := s.manager.Components[config.Name]; ok {
+				processes[len(resp.Components)] = *comp
+			}
+			resp.Components = append(resp.Components, info)
+		}
+		s.manager.RUnlock()
+
+		for i, comp := range processes {
+			resp.Components[i].Usage = comp.Usage()
 		}
 
 		writeJSON(w, http.StatusOK, resp)
@@ -78,9 +137,18 @@ func (s *Server) executeCommandHandler() http.HandlerFunc {
 			return
 		}
 
+		if !allowed(r, req.ComponentName, req.CommandName) {
+			writeError(w, http.StatusForbidden, "当前密钥无权调用该命令")
+			return
+		}
+
 		// 查找组件
 		s.manager.RLock()
 		comp, ok := s.manager.Components[req.ComponentName]
+		var command string
+		if ok {
+			command = commandLabel(comp, req.CommandName)
+		}
 		s.manager.RUnlock()
 
 		if !ok || comp.Client == nil {
@@ -88,9 +156,21 @@ func (s *Server) executeCommandHandler() http.HandlerFunc {
 			return
 		}
 
+		// 关闭服务器时等待进行中的命令完成
+		s.inflight.Add(1)
+		defer s.inflight.Add(-1)
+
+		// 记录调用次数、延迟与错误码
+		start := time.Now()
+		var errorCode string
+		defer func() {
+			s.metrics.observe(req.ComponentName, command, time.Since(start), errorCode)
+		}()
+
 		// 序列化参数
 		paramsPayload, err := json.Marshal(req.Params)
 		if err != nil {
+			errorCode = "INVALID_PARAMS"
 			http.Error(w, "Invalid params format", http.StatusBadRequest)
 			return
 		}
@@ -103,23 +183,35 @@ func (s *Server) executeCommandHandler() http.HandlerFunc {
 			},
 		}
 
-		// 执行 gRPC 调用
-		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		// 执行 gRPC 调用。客户端断开时命令仍继续执行，只沿用请求上下文中的请求 ID 与 span
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 15*time.Second)
 		defer cancel()
+		ctx, span := tracing.Start(ctx, "v1.ComponentService/ExecuteCommand", tracing.KindClient)
+		defer span.End()
+		span.SetAttribute("rpc.system", "grpc")
+		span.SetAttribute("cse.component", req.ComponentName)
+		span.SetAttribute("cse.command", req.CommandName)
+		ctx = tracing.Inject(commandbus.NewOutgoingContext(ctx, callerFromRequest(r)))
+		requestID := tracing.RequestID(ctx)
 
-		log.Printf("Executing command '%s' on component '%s'", req.CommandName, req.ComponentName)
+		slog.InfoContext(ctx, "执行组件命令", "component", req.ComponentName, "command", req.CommandName)
 		grpcResp, err := comp.Client.ExecuteCommand(ctx, grpcReq)
 		if err != nil {
-			log.Printf("gRPC call failed: %v", err)
+			errorCode = status.Code(err).String()
+			span.SetError(err.Error())
+			slog.WarnContext(ctx, "gRPC 调用失败", "component", req.ComponentName, "command", req.CommandName, "error", err)
 			http.Error(w, "Failed to execute command: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
 		// 处理 gRPC 响应
 		if !grpcResp.Success {
+			errorCode = "COMMAND_ERROR"
+			span.SetError(grpcResp.ErrorMessage)
 			writeJSON(w, http.StatusOK, map[string]any{
-				"success": false,
-				"error":   grpcResp.ErrorMessage,
+				"success":    false,
+				"error":      grpcResp.ErrorMessage,
+				"request_id": requestID,
 			})
 			return
 		}
@@ -127,22 +219,66 @@ func (s *Server) executeCommandHandler() http.HandlerFunc {
 		// 反序列化结果并返回
 		var resultData any
 		if err := json.Unmarshal([]byte(grpcResp.GetResult().GetJsonPayload()), &resultData); err != nil {
+			errorCode = "INVALID_RESULT"
+			span.SetError(err.Error())
 			http.Error(w, "Failed to parse command result", http.StatusInternalServerError)
 			return
 		}
+		errorCode = resultErrorCode(resultData)
+		if errorCode != "" {
+			span.SetError(errorCode)
+		}
 
 		writeJSON(w, http.StatusOK, map[string]any{
-			"success": true,
-			"data":    resultData,
+			"success":    true,
+			"data":       resultData,
+			"request_id": requestID,
 		})
 	}
 }
 
+// caCertHandler 导出本地 CA 证书，用户将其安装到信任列表后浏览器即可信任 HTTPS 服务
+func (s *Server) caCertHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if s.caPEM == nil {
+			writeError(w, http.StatusNotFound, "未使用本地 CA")
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-pem-file")
+		w.Header().Set("Content-Disposition", `attachment; filename="cse-local-ca.pem"`)
+		w.Write(s.caPEM)
+	}
+}
+
+// callerFromRequest 根据 HTTP 请求确定调用方身份，传递给组件用于审计与访问控制。
+// 启用认证时用户为密钥名称，否则由调用应用通过 X-CSE-User 请求头提供
+func callerFromRequest(r *http.Request) commandbus.Caller {
+	user := r.Header.Get("X-CSE-User")
+	if key := keyFromContext(r.Context()); key != nil {
+		user = key.Name
+	}
+	return commandbus.Caller{
+		User:       user,
+		RemoteAddr: r.RemoteAddr,
+		UserAgent:  r.UserAgent(),
+		Origin:     r.Header.Get("Origin"),
+	}
+}
+
+// writeError 以 JSON 格式写入错误响应
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"success": false, "error": message})
+}
+
 // writeJSON 是一个辅助函数，用于统一写入 JSON 响应
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(v); err != nil {
-		log.Printf("Failed to write JSON response: %v", err)
+		slog.Warn("写入 JSON 响应失败", "error", err)
 	}
 }
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	pb "cse-go/pkg/api/v1"
+)
+
+// healthCheckTimeout 为单项存活检查与查询单个组件状态的超时时间
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck 检查 Supervisor 的一个内部服务，返回 nil 表示正常
+type HealthCheck func(ctx context.Context) error
+
+// AddHealthCheck 添加 /healthz 与 /readyz 执行的存活检查
+func (s *Server) AddHealthCheck(name string, check HealthCheck) {
+	s.healthChecks = append(s.healthChecks, namedCheck{name: name, check: check})
+}
+
+type namedCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// healthzResponse 定义了 /healthz 的响应结构，Checks 为各项检查的结果 ("ok" 或错误信息)
+type healthzResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// readyzResponse 定义了 /readyz 的响应结构
+type readyzResponse struct {
+	Status     string            `json:"status"`
+	Checks     map[string]string `json:"checks"`
+	Components []componentHealth `json:"components"`
+}
+
+type componentHealth struct {
+	Name       string `json:"name"`
+	Required   bool   `json:"required"`
+	Registered bool   `json:"registered"`
+	Healthy    bool   `json:"healthy"`
+	State      string `json:"state,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// healthzHandler 返回存活检查结果: Supervisor 进程在运行且组件发现服务正在提供服务
+func (s *Server) healthzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		checks, ok := s.runHealthChecks(r.Context())
+		resp := healthzResponse{Status: "ok", Checks: checks}
+		status := http.StatusOK
+		if !ok {
+			resp.Status = "failed"
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, resp)
+	}
+}
+
+// readyzHandler 返回就绪检查结果: 存活检查通过，且所有必需的组件都已注册并处于运行状态。
+// 可选组件的状态同样列出，但不影响结果
+func (s *Server) readyzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		checks, ready := s.runHealthChecks(r.Context())
+		components := s.componentHealth(r.Context())
+		for _, c := range components {
+			if c.Required && !c.Healthy {
+				ready = false
+			}
+		}
+
+		resp := readyzResponse{Status: "ready", Checks: checks, Components: components}
+		status := http.StatusOK
+		if !ready {
+			resp.Status = "not_ready"
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, resp)
+	}
+}
+
+// runHealthChecks 执行所有存活检查，返回各项结果以及是否全部通过
+func (s *Server) runHealthChecks(ctx context.Context) (map[string]string, bool) {
+	results := make(map[string]string, len(s.healthChecks))
+	ok := true
+	for _, c := range s.healthChecks {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		err := c.check(checkCtx)
+		cancel()
+		if err != nil {
+			results[c.name] = err.Error()
+			ok = false
+		} else {
+			results[c.name] = "ok"
+		}
+	}
+	return results, ok
+}
+
+// componentHealth 查询配置目录中每个组件的注册情况与运行状态
+func (s *Server) componentHealth(ctx context.Context) []componentHealth {
+	configured := s.manager.Configured()
+	clients := make([]pb.ComponentServiceClient, len(configured))
+	s.manager.RLock()
+	for i, config := range configured {
+		if comp, ok := s.manager.Components[config.Name]; ok {
+			clients[i] = comp.Client
+		}
+	}
+	s.manager.RUnlock()
+
+	results := make([]componentHealth, 0, len(configured))
+	for i, config := range configured {
+		h := componentHealth{Name: config.Name, Required: config.Required}
+		if clients[i] == nil {
+			h.Message = "组件未注册"
+			if status, ok := s.manager.LaunchStatus(config.Name); ok && status.Error != "" {
+				h.Message = status.Error
+			}
+			results = append(results, h)
+			continue
+		}
+		h.Registered = true
+		statusCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		resp, err := clients[i].GetStatus(statusCtx, &pb.GetStatusRequest{})
+		cancel()
+		if err != nil {
+			h.Message = err.Error()
+		} else {
+			h.State = resp.CurrentState.String()
+			h.Message = resp.Message
+			h.Healthy = resp.CurrentState == pb.ComponentState_RUNNING
+		}
+		results = append(results, h)
+	}
+	return results
+}
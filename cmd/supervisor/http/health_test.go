@@ -0,0 +1,134 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cse-go/cmd/supervisor/certs"
+	"cse-go/cmd/supervisor/manager"
+)
+
+// launchForTest 使用不存在的组件程序启动 configs 中的组件 (文件名到配置内容)，
+// 组件启动失败，但配置与日志文件仍被记录
+func launchForTest(t *testing.T, s *Server, configs map[string]string) {
+	t.Helper()
+	pki, err := certs.NewInternal(filepath.Join(t.TempDir(), "run"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.manager = manager.NewComponentManager(pki)
+	s.manager.SetLogging(manager.LogOptions{Dir: t.TempDir()})
+	configDir := t.TempDir()
+	for name, config := range configs {
+		os.WriteFile(filepath.Join(configDir, name), []byte(config), 0o644)
+	}
+	s.manager.LaunchComponents(configDir, "localhost:0")
+}
+
+func TestHealthz(t *testing.T) {
+	s := newServerForTest(t)
+	var discoveryErr error
+	s.AddHealthCheck("discovery", func(ctx context.Context) error { return discoveryErr })
+	h := s.authenticate(s.setupRoutes())
+
+	rec := do(h, http.MethodGet, "/healthz", "", nil)
+	var resp healthzResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if rec.Code != http.StatusOK || resp.Status != "ok" || resp.Checks["discovery"] != "ok" {
+		t.Errorf("存活检查应通过且不需要令牌: %d %+v", rec.Code, resp)
+	}
+
+	discoveryErr = errors.New("connection refused")
+	rec = do(h, http.MethodGet, "/healthz", "", nil)
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if rec.Code != http.StatusServiceUnavailable || resp.Checks["discovery"] != "connection refused" {
+		t.Errorf("发现服务不可用时应返回 503: %d %+v", rec.Code, resp)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	s := newServerForTest(t)
+	launchForTest(t, s, map[string]string{
+		"printer.json": `{"name": "printer", "cmd": "missing-printer"}`,
+		"scanner.json": `{"name": "scanner", "cmd": "missing-scanner", "required": false}`,
+	})
+	h := s.authenticate(s.setupRoutes())
+	readyz := func() (int, readyzResponse) {
+		rec := do(h, http.MethodGet, "/readyz", "", nil)
+		var resp readyzResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp
+	}
+
+	// 必需的组件未注册
+	code, resp := readyz()
+	if code != http.StatusServiceUnavailable || resp.Status != "not_ready" || len(resp.Components) != 2 {
+		t.Fatalf("必需组件未注册时应返回 503: %d %+v", code, resp)
+	}
+
+	// 只有可选组件未注册
+	component := &fakeComponent{}
+	s.manager.Lock()
+	s.manager.Components["printer"] = &manager.ComponentInfo{Client: component}
+	s.manager.Unlock()
+	code, resp = readyz()
+	if code != http.StatusOK || resp.Status != "ready" {
+		t.Errorf("可选组件不应影响就绪状态: %d %+v", code, resp)
+	}
+	for _, c := range resp.Components {
+		want := componentHealth{Name: "printer", Required: true, Registered: true, Healthy: true, State: "RUNNING"}
+		if c.Name == "scanner" {
+			// 未注册的组件报告启动失败的原因
+			want = componentHealth{Name: "scanner", Message: c.Message}
+			if !strings.Contains(c.Message, "missing-scanner") {
+				t.Errorf("应报告组件启动失败的原因: %q", c.Message)
+			}
+		}
+		if c != want {
+			t.Errorf("组件状态不正确: %+v", c)
+		}
+	}
+
+	// 必需的组件不在运行状态
+	component.down = true
+	if code, resp = readyz(); code != http.StatusServiceUnavailable || resp.Components[0].Healthy {
+		t.Errorf("必需组件不健康时应返回 503: %d %+v", code, resp)
+	}
+}
+
+func TestListComponentsShowsBlocked(t *testing.T) {
+	s := newServerForTest(t)
+	launchForTest(t, s, map[string]string{
+		"printer.json": `{"name": "printer", "cmd": "missing-printer"}`,
+		"kiosk.json":   `{"name": "kiosk", "cmd": "missing-kiosk", "depends_on": ["printer"]}`,
+	})
+	h := s.authenticate(s.setupRoutes())
+
+	rec := do(h, http.MethodGet, "/api/v1/components", "admin-token", nil)
+	var resp listComponentsResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	launch := map[string]*manager.LaunchStatus{}
+	for _, c := range resp.Components {
+		launch[c.Name] = c.Launch
+	}
+	if s := launch["printer"]; s == nil || s.State != manager.LaunchFailed || s.Phase != 1 {
+		t.Errorf("启动失败的组件应列出: %+v", s)
+	}
+	if s := launch["kiosk"]; s == nil || s.State != manager.LaunchBlocked || s.Phase != 2 || len(s.BlockedBy) != 1 || s.BlockedBy[0] != "printer" {
+		t.Errorf("被依赖阻塞的组件应列出: %+v", s)
+	}
+
+	// 受限密钥只能看到允许的组件
+	rec = do(h, http.MethodGet, "/api/v1/components", "kiosk-token", nil)
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Components) != 1 || resp.Components[0].Name != "printer" {
+		t.Errorf("受限密钥的组件列表不正确: %+v", resp.Components)
+	}
+}
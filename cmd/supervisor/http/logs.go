@@ -0,0 +1,89 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultLogTail = 100
+	maxLogTail     = 10000
+)
+
+// componentLogsHandler 返回组件日志的最后若干行 (GET /api/v1/components/{name}/logs?tail=N&follow=true)。
+// follow=true 时保持连接并持续输出新写入的行，直到客户端断开或服务器关闭。
+// 日志可能包含打印内容等敏感信息，启用认证时需要管理员密钥
+func (s *Server) componentLogsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !s.requireAdmin(w, r, "") {
+			return
+		}
+
+		tail := defaultLogTail
+		if v := r.URL.Query().Get("tail"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "tail 必须是非负整数")
+				return
+			}
+			tail = min(n, maxLogTail)
+		}
+		follow := r.URL.Query().Get("follow") == "true"
+
+		name := r.PathValue("name")
+		logFile, ok := s.manager.ComponentLog(name)
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("组件 '%s' 没有日志", name))
+			return
+		}
+
+		// follow 时订阅与读取已有内容同时确定分界，两者之间写入的行既不丢失也不重复
+		var existing []string
+		var lines <-chan string
+		var err error
+		if follow {
+			var cancel func()
+			existing, lines, cancel, err = logFile.Follow(tail)
+			if err == nil {
+				defer cancel()
+			}
+		} else {
+			existing, err = logFile.Tail(tail)
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if len(existing) > 0 {
+			fmt.Fprint(w, strings.Join(existing, "\n")+"\n")
+		}
+		if !follow {
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		rc.Flush()
+		for {
+			select {
+			case line := <-lines:
+				if _, err := fmt.Fprintln(w, line); err != nil {
+					return
+				}
+				rc.Flush()
+			case <-r.Context().Done():
+				return
+			case <-s.stopping:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestComponentLogs(t *testing.T) {
+	s := newServerForTest(t)
+	launchForTest(t, s, map[string]string{"printer.json": `{"name": "printer", "cmd": "missing-printer"}`})
+	logFile, ok := s.manager.ComponentLog("printer")
+	if !ok {
+		t.Fatal("应为组件打开日志文件")
+	}
+	for i := 1; i <= 3; i++ {
+		logFile.WriteLine(fmt.Sprintf("line %d", i))
+	}
+
+	h := s.authenticate(s.setupRoutes())
+	if rec := do(h, http.MethodGet, "/api/v1/components/printer/logs", "kiosk-token", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("非管理员密钥应返回 403，实际 %d", rec.Code)
+	}
+	if rec := do(h, http.MethodGet, "/api/v1/components/other/logs", "admin-token", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("没有日志的组件应返回 404，实际 %d", rec.Code)
+	}
+	if rec := do(h, http.MethodGet, "/api/v1/components/printer/logs?tail=x", "admin-token", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("无效的 tail 应返回 400，实际 %d", rec.Code)
+	}
+	rec := do(h, http.MethodGet, "/api/v1/components/printer/logs?tail=2", "admin-token", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "line 2\nline 3\n" {
+		t.Errorf("tail 结果不正确: %d %q", rec.Code, rec.Body.String())
+	}
+
+	// follow 持续输出新写入的行
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/v1/components/printer/logs?tail=1&follow=true", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("请求日志失败: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); line != "line 3\n" {
+		t.Fatalf("follow 应先输出已有的行，实际 %q", line)
+	}
+	logFile.WriteLine("line 4")
+	if line, _ := reader.ReadString('\n'); line != "line 4\n" {
+		t.Errorf("follow 应输出新写入的行，实际 %q", line)
+	}
+}
@@ -0,0 +1,230 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"cse-go/cmd/supervisor/manager"
+	"cse-go/internal/commandbus"
+	"cse-go/internal/metrics"
+	pb "cse-go/pkg/api/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// componentMetricsTimeout 为输出指标时查询所有组件状态与指标的总超时时间，各组件并行查询
+const componentMetricsTimeout = 2 * time.Second
+
+// componentMetricPrefix 为组件上报的指标在 Supervisor 中的名称前缀
+const componentMetricPrefix = "cse_component_"
+
+// commandBuckets 为命令延迟直方图的桶上限 (秒)，最大值与命令调用超时一致
+var commandBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 15}
+
+var startTime = time.Now()
+
+// serverMetrics 是 Supervisor 的指标
+type serverMetrics struct {
+	registry *metrics.Registry
+	requests *metrics.Counter
+	errors   *metrics.Counter
+	latency  *metrics.Histogram
+}
+
+func newServerMetrics(m *manager.ComponentManager) *serverMetrics {
+	r := metrics.NewRegistry()
+	sm := &serverMetrics{
+		registry: r,
+		requests: r.NewCounter("cse_command_requests_total", "Commands executed through the HTTP API.", "component", "command"),
+		errors:   r.NewCounter("cse_command_errors_total", "Failed commands by error code.", "component", "command", "code"),
+		latency:  r.NewHistogram("cse_command_duration_seconds", "Command execution latency in seconds.", commandBuckets, "component", "command"),
+	}
+	r.RegisterCollector(collectRuntime)
+	r.RegisterCollector(func() []*metrics.Family { return collectComponents(m) })
+	return sm
+}
+
+// observe 记录一次命令调用，code 为空表示成功
+func (sm *serverMetrics) observe(component, command string, elapsed time.Duration, code string) {
+	sm.requests.Inc(component, command)
+	sm.latency.Observe(elapsed.Seconds(), component, command)
+	if code != "" {
+		sm.errors.Inc(component, command, code)
+	}
+}
+
+// commandLabel 返回命令在指标中的名称。组件未声明的命令统一记为 unknown，避免任意输入产生大量序列
+func commandLabel(comp *manager.ComponentInfo, command string) string {
+	if comp.Metadata != nil {
+		for _, info := range comp.Metadata.ProvidedCommands {
+			if info.CommandName == command {
+				return command
+			}
+		}
+	}
+	return "unknown"
+}
+
+// resultErrorCode 返回命令结果中的错误码。结果为 success=false 的对象时使用其 code 字段，
+// 没有 code 时为 FAILED；其他结果视为成功
+func resultErrorCode(result any) string {
+	m, ok := result.(map[string]any)
+	if !ok {
+		return ""
+	}
+	if success, ok := m["success"].(bool); !ok || success {
+		return ""
+	}
+	if code, ok := m["code"].(string); ok && code != "" {
+		return code
+	}
+	return "FAILED"
+}
+
+// collectRuntime 生成运行时间、协程与内存指标
+func collectRuntime() []*metrics.Family {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	gauge := func(name, help string, value float64) *metrics.Family {
+		return &metrics.Family{Name: name, Help: help, Type: metrics.TypeGauge, Samples: []metrics.Sample{{Value: value}}}
+	}
+	return []*metrics.Family{
+		gauge("cse_uptime_seconds", "Seconds since the supervisor started.", time.Since(startTime).Seconds()),
+		gauge("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", float64(startTime.Unix())),
+		gauge("go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine())),
+		gauge("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", float64(mem.Alloc)),
+		gauge("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", float64(mem.HeapInuse)),
+		gauge("go_memstats_sys_bytes", "Number of bytes obtained from the system.", float64(mem.Sys)),
+		{Name: "go_gc_cycles_total", Help: "Number of completed GC cycles.", Type: metrics.TypeCounter,
+			Samples: []metrics.Sample{{Value: float64(mem.NumGC)}}},
+	}
+}
+
+// collectComponents 并行查询每个组件的状态与自定义指标，未在超时前应答的组件记为 ERROR
+func collectComponents(m *manager.ComponentManager) []*metrics.Family {
+	type target struct {
+		name     string
+		client   pb.ComponentServiceClient
+		restarts int
+	}
+	m.RLock()
+	targets := make([]target, 0, len(m.Components))
+	for name, comp := range m.Components {
+		targets = append(targets, target{name: name, client: comp.Client, restarts: comp.Restarts})
+	}
+	m.RUnlock()
+	sort.Slice(targets, func(i, j int) bool { return targets[i].name < targets[j].name })
+
+	states := make([]int32, 0, len(pb.ComponentState_name))
+	for v := range pb.ComponentState_name {
+		states = append(states, v)
+	}
+	slices.Sort(states)
+
+	state := &metrics.Family{Name: "cse_component_state", Type: metrics.TypeGauge,
+		Help: "Current component state, 1 for the state the component is in."}
+	restarts := &metrics.Family{Name: "cse_component_restarts_total", Type: metrics.TypeCounter,
+		Help: "Times a component registered again after a restart."}
+
+	// 进程已启动但尚未注册的组件处于 LOADED 状态
+	ctx, cancel := context.WithTimeout(context.Background(), componentMetricsTimeout)
+	defer cancel()
+	current := make([]pb.ComponentState, len(targets))
+	componentFamilies := make([][]*metrics.Family, len(targets))
+	var wg sync.WaitGroup
+	registered := 0
+	for i, t := range targets {
+		current[i] = pb.ComponentState_LOADED
+		if t.client == nil {
+			continue
+		}
+		registered++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			current[i], componentFamilies[i] = queryComponent(ctx, t.name, t.client)
+		}()
+	}
+	wg.Wait()
+
+	var reported []*metrics.Family
+	for i, t := range targets {
+		reported = append(reported, componentFamilies[i]...)
+		for _, v := range states {
+			value := 0.0
+			if v == int32(current[i]) {
+				value = 1
+			}
+			state.Samples = append(state.Samples, metrics.Sample{
+				Labels: []metrics.Label{{Name: "component", Value: t.name}, {Name: "state", Value: pb.ComponentState_name[v]}},
+				Value:  value,
+			})
+		}
+		restarts.Samples = append(restarts.Samples, metrics.Sample{
+			Labels: []metrics.Label{{Name: "component", Value: t.name}},
+			Value:  float64(t.restarts),
+		})
+	}
+
+	families := []*metrics.Family{state, restarts, {
+		Name: "cse_components_registered", Help: "Number of registered components.", Type: metrics.TypeGauge,
+		Samples: []metrics.Sample{{Value: float64(registered)}},
+	}}
+	return append(families, reported...)
+}
+
+// queryComponent 查询组件状态，并返回加上前缀与 component 标签的组件上报的指标
+func queryComponent(ctx context.Context, name string, client pb.ComponentServiceClient) (pb.ComponentState, []*metrics.Family) {
+	current := pb.ComponentState_ERROR
+	if resp, err := client.GetStatus(ctx, &pb.GetStatusRequest{}); err == nil {
+		current = resp.CurrentState
+	}
+
+	resp, err := client.GetMetrics(ctx, &pb.GetMetricsRequest{})
+	if err != nil {
+		if status.Code(err) != codes.Unimplemented {
+			slog.Warn("获取组件指标失败", "component", name, "error", err)
+		}
+		return current, nil
+	}
+	var reported []*metrics.Family
+	for _, f := range commandbus.MetricsFromResponse(resp) {
+		f.Name = componentMetricPrefix + f.Name
+		// 不允许覆盖 Supervisor 自身的组件指标
+		if f.Name == "cse_component_state" || f.Name == "cse_component_restarts_total" {
+			continue
+		}
+		for i := range f.Samples {
+			labels := []metrics.Label{{Name: "component", Value: name}}
+			for _, l := range f.Samples[i].Labels {
+				if l.Name != "component" {
+					labels = append(labels, l)
+				}
+			}
+			f.Samples[i].Labels = labels
+		}
+		reported = append(reported, f)
+	}
+	return current, reported
+}
+
+// metricsHandler 以 Prometheus 文本格式输出指标
+func (s *Server) metricsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", metrics.ContentType)
+		if err := metrics.WriteText(w, s.metrics.registry.Gather()); err != nil {
+			slog.Warn("写入指标失败", "error", err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"cse-go/cmd/supervisor/manager"
+	"cse-go/internal/commandbus"
+	"cse-go/internal/metrics"
+	pb "cse-go/pkg/api/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeComponent 是返回固定结果的组件客户端，outgoing 为最近一次调用发出的 gRPC 元数据，
+// down 为 true 时 GetStatus 返回 Unavailable，block 不为 nil 时命令等到 block 关闭才返回
+type fakeComponent struct {
+	pb.ComponentServiceClient
+	payload  string
+	outgoing metadata.MD
+	down     bool
+	block    chan struct{}
+}
+
+func (f *fakeComponent) ExecuteCommand(ctx context.Context, req *pb.ExecuteCommandRequest, opts ...grpc.CallOption) (*pb.ExecuteCommandResponse, error) {
+	f.outgoing, _ = metadata.FromOutgoingContext(ctx)
+	if f.block != nil {
+		<-f.block
+	}
+	return &pb.ExecuteCommandResponse{Success: true, Result: &pb.CommandResult{JsonPayload: f.payload}}, nil
+}
+
+func (f *fakeComponent) GetStatus(ctx context.Context, req *pb.GetStatusRequest, opts ...grpc.CallOption) (*pb.GetStatusResponse, error) {
+	if f.down {
+		return nil, status.Error(codes.Unavailable, "connection refused")
+	}
+	return &pb.GetStatusResponse{CurrentState: pb.ComponentState_RUNNING}, nil
+}
+
+func (f *fakeComponent) GetMetrics(ctx context.Context, req *pb.GetMetricsRequest, opts ...grpc.CallOption) (*pb.GetMetricsResponse, error) {
+	r := metrics.NewRegistry()
+	r.NewCounter("jobs_total", "Jobs.", "component").Add(3, "spoofed")
+	r.NewGauge("state", "Must not override the supervisor metric.").Set(5)
+	return commandbus.MetricsResponse(r), nil
+}
+
+func TestMetrics(t *testing.T) {
+	s := newServerForTest(t)
+	h := s.authenticate(s.setupRoutes())
+	component := &fakeComponent{payload: `{"success": false, "code": "PERMISSION_DENIED"}`}
+	s.manager.Lock()
+	s.manager.Components["printer"] = &manager.ComponentInfo{
+		Client:   component,
+		Restarts: 2,
+		Metadata: &pb.ComponentMetadata{ProvidedCommands: []*pb.CommandInfo{{CommandName: "print.testPrint"}}},
+	}
+	s.manager.Unlock()
+
+	execute := func(command string) {
+		body := map[string]any{"component_name": "printer", "command_name": command}
+		if rec := do(h, http.MethodPost, "/api/v1/execute", "admin-token", body); rec.Code != http.StatusOK {
+			t.Fatalf("执行命令失败: %d", rec.Code)
+		}
+	}
+	execute("print.testPrint")
+	component.payload = `{"success": true}`
+	execute("print.testPrint")
+	execute("print.whatever")
+
+	if rec := do(h, http.MethodGet, "/metrics", "", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("/metrics 需要访问令牌，实际 %d", rec.Code)
+	}
+	rec := do(h, http.MethodGet, "/metrics", "kiosk-token", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != metrics.ContentType {
+		t.Fatalf("获取指标失败: %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	for _, line := range []string{
+		`cse_command_requests_total{component="printer",command="print.testPrint"} 2`,
+		`cse_command_requests_total{component="printer",command="unknown"} 1`,
+		`cse_command_errors_total{component="printer",command="print.testPrint",code="PERMISSION_DENIED"} 1`,
+		`cse_command_duration_seconds_count{component="printer",command="print.testPrint"} 2`,
+		`cse_component_state{component="printer",state="RUNNING"} 1`,
+		`cse_component_state{component="printer",state="ERROR"} 0`,
+		`cse_component_restarts_total{component="printer"} 2`,
+		`cse_components_registered 1`,
+		`cse_component_jobs_total{component="printer"} 3`,
+		`# TYPE go_goroutines gauge`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("指标中缺少 %s", line)
+		}
+	}
+	if strings.Contains(body, "cse_component_state 5") {
+		t.Error("组件上报的指标不应覆盖 Supervisor 的指标")
+	}
+}
+
+// hungComponent 的 GetStatus 与 GetMetrics 直到调用超时才返回
+type hungComponent struct {
+	pb.ComponentServiceClient
+}
+
+func (hungComponent) GetStatus(ctx context.Context, req *pb.GetStatusRequest, opts ...grpc.CallOption) (*pb.GetStatusResponse, error) {
+	<-ctx.Done()
+	return nil, status.FromContextError(ctx.Err()).Err()
+}
+
+func (hungComponent) GetMetrics(ctx context.Context, req *pb.GetMetricsRequest, opts ...grpc.CallOption) (*pb.GetMetricsResponse, error) {
+	<-ctx.Done()
+	return nil, status.FromContextError(ctx.Err()).Err()
+}
+
+// TestCollectComponentsParallel 测试各组件并行查询，无应答的组件共用一个总超时
+func TestCollectComponentsParallel(t *testing.T) {
+	m := manager.NewComponentManager(nil)
+	for _, name := range []string{"a", "b", "c"} {
+		m.Components[name] = &manager.ComponentInfo{Client: hungComponent{}}
+	}
+	m.Components["ok"] = &manager.ComponentInfo{Client: &fakeComponent{}}
+
+	start := time.Now()
+	families := collectComponents(m)
+	if elapsed := time.Since(start); elapsed > componentMetricsTimeout+time.Second {
+		t.Errorf("查询组件耗时 %v，超过总超时", elapsed)
+	}
+	var out strings.Builder
+	metrics.WriteText(&out, families)
+	for _, line := range []string{
+		`cse_component_state{component="a",state="ERROR"} 1`,
+		`cse_component_state{component="c",state="ERROR"} 1`,
+		`cse_component_state{component="ok",state="RUNNING"} 1`,
+		`cse_component_jobs_total{component="ok"} 3`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("指标中缺少 %s", line)
+		}
+	}
+}
+
+func TestResultErrorCode(t *testing.T) {
+	tests := []struct {
+		result any
+		want   string
+	}{
+		{[]any{"P1"}, ""},
+		{map[string]any{"success": true}, ""},
+		{map[string]any{"printer": "P1"}, ""},
+		{map[string]any{"success": false}, "FAILED"},
+		{map[string]any{"success": false, "code": "PRINTER_OFFLINE"}, "PRINTER_OFFLINE"},
+	}
+	for _, tt := range tests {
+		if got := resultErrorCode(tt.result); got != tt.want {
+			t.Errorf("resultErrorCode(%v) = %q，期望 %q", tt.result, got, tt.want)
+		}
+	}
+}
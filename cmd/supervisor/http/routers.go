@@ -7,7 +7,21 @@ func (s *Server) setupRoutes() *http.ServeMux {
 
 	// API V1 路由组
 	mux.HandleFunc("/api/v1/components", s.listComponentsHandler())
+	mux.HandleFunc("/api/v1/components/{name}/logs", s.componentLogsHandler())
 	mux.HandleFunc("/api/v1/execute", s.executeCommandHandler())
+	mux.HandleFunc("/api/v1/settings/reload", s.reloadSettingsHandler())
+	mux.HandleFunc("/api/v1/secrets", s.secretsHandler())
+	mux.HandleFunc("/api/v1/secrets/{name}", s.secretHandler())
+	mux.HandleFunc("/api/v1/keys", s.keysHandler())
+	mux.HandleFunc("/api/v1/keys/{name}", s.keyHandler())
+	mux.HandleFunc("/api/v1/tls/ca.pem", s.caCertHandler())
+
+	// 存活与就绪检查，不需要认证
+	mux.HandleFunc("/healthz", s.healthzHandler())
+	mux.HandleFunc("/readyz", s.readyzHandler())
+
+	// Prometheus 指标，需要任意有效的访问密钥
+	mux.HandleFunc("/metrics", s.metricsHandler())
 
 	// 未来可以添加 /api/v2/... 等
 
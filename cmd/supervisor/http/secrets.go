@@ -0,0 +1,131 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"cse-go/cmd/supervisor/secrets"
+)
+
+// SetSecrets 启用 secret 管理接口，并将响应中出现的 secret 值替换为 "[REDACTED]"
+func (s *Server) SetSecrets(store *secrets.Store) {
+	s.secrets = store
+}
+
+// secretsAuthDisabled 为未启用认证时管理 secret 的错误信息。secret 可以被读取到组件的 settings 中，
+// 未启用认证时任何本机程序都能调用接口，因此不允许管理
+const secretsAuthDisabled = "未启用认证，无法管理 secret：管理 secret 需要在 auth 中启用认证并使用管理员密钥"
+
+// requireSecrets 在未配置 secret 主密钥时返回 403，返回值表示是否可以继续处理
+func (s *Server) requireSecrets(w http.ResponseWriter) bool {
+	if s.secrets == nil {
+		writeError(w, http.StatusForbidden, "未配置 secret 主密钥，无法管理 secret")
+		return false
+	}
+	return true
+}
+
+// setSecretRequest 定义了 PUT /api/v1/secrets/{name} 的请求体结构
+type setSecretRequest struct {
+	Value string `json:"value"`
+}
+
+// secretsHandler 列出 secret 的名称与修改时间 (GET /api/v1/secrets)，不返回值。需要管理员密钥
+func (s *Server) secretsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !s.requireAdmin(w, r, secretsAuthDisabled) || !s.requireSecrets(w) {
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"secrets": s.secrets.List()})
+	}
+}
+
+// secretHandler 创建或替换 (PUT) 与删除 (DELETE) secret，需要管理员密钥。
+// 修改后 settings 引用了该 secret 的已注册组件立即收到新的 settings，响应中包含推送结果
+func (s *Server) secretHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !s.requireAdmin(w, r, secretsAuthDisabled) || !s.requireSecrets(w) {
+			return
+		}
+		name := r.PathValue("name")
+		by := keyFromContext(r.Context()).Name
+
+		if r.Method == http.MethodDelete {
+			if err := s.secrets.Delete(name); err != nil {
+				status := http.StatusInternalServerError
+				if errors.Is(err, secrets.ErrNotFound) {
+					status = http.StatusNotFound
+				}
+				writeError(w, status, err.Error())
+				return
+			}
+			if refs := s.manager.SecretReferences(name); len(refs) > 0 {
+				slog.WarnContext(r.Context(), "已删除的 secret 仍被组件引用，组件下次收到 settings 时将报告错误", "secret", name, "components", refs)
+			}
+			slog.InfoContext(r.Context(), "已删除 secret", "secret", name, "by", by)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		var req setSecretRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		info, err := s.secrets.Set(name, req.Value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.InfoContext(r.Context(), "已设置 secret", "secret", name, "by", by)
+
+		components := map[string]settingsResult{}
+		for component, err := range s.manager.ApplySecret(name) {
+			if err != nil {
+				components[component] = settingsResult{Error: err.Error()}
+				continue
+			}
+			components[component] = settingsResult{Applied: true}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"secret": info, "components": components})
+	}
+}
+
+// redact 将响应中出现的 secret 值替换为 "[REDACTED]"。响应按每次写入的内容处理，
+// API 的 JSON 响应与日志的每一行都在一次写入中完成
+func (s *Server) redact(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.secrets == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(&redactWriter{ResponseWriter: w, store: s.secrets}, r)
+	})
+}
+
+// redactWriter 在写入前脱敏响应内容，Unwrap 使 http.ResponseController 仍可刷新流式响应
+type redactWriter struct {
+	http.ResponseWriter
+	store *secrets.Store
+}
+
+func (w *redactWriter) Write(p []byte) (int, error) {
+	if _, err := w.ResponseWriter.Write([]byte(w.store.Redact(string(p)))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *redactWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cse-go/cmd/supervisor/auth"
+	"cse-go/cmd/supervisor/config"
+	"cse-go/cmd/supervisor/manager"
+	"cse-go/cmd/supervisor/secrets"
+)
+
+func TestSecrets(t *testing.T) {
+	s := newServerForTest(t)
+	h := s.authenticate(s.setupRoutes())
+	if rec := do(h, http.MethodGet, "/api/v1/secrets", "admin-token", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("未配置主密钥时应返回 403，实际 %d", rec.Code)
+	}
+
+	encoded, _ := secrets.GenerateKey()
+	key, _ := base64.StdEncoding.DecodeString(encoded)
+	store, err := secrets.Open(filepath.Join(t.TempDir(), "secrets.enc"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetSecrets(store)
+
+	if rec := do(h, http.MethodPut, "/api/v1/secrets/cloud-token", "kiosk-token", setSecretRequest{Value: "tok-1234"}); rec.Code != http.StatusForbidden {
+		t.Errorf("非管理员密钥应返回 403，实际 %d", rec.Code)
+	}
+	if rec := do(h, http.MethodPut, "/api/v1/secrets/bad%20name", "admin-token", setSecretRequest{Value: "tok-1234"}); rec.Code != http.StatusBadRequest {
+		t.Errorf("无效的名称应返回 400，实际 %d", rec.Code)
+	}
+	rec := do(h, http.MethodPut, "/api/v1/secrets/cloud-token", "admin-token", setSecretRequest{Value: "tok-1234"})
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "tok-1234") {
+		t.Errorf("设置 secret 应返回 200 且不包含值: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = do(h, http.MethodGet, "/api/v1/secrets", "admin-token", nil)
+	var list struct {
+		Secrets []*secrets.Info `json:"secrets"`
+	}
+	json.NewDecoder(rec.Body).Decode(&list)
+	if rec.Code != http.StatusOK || len(list.Secrets) != 1 || list.Secrets[0].Name != "cloud-token" {
+		t.Errorf("应列出 secret 的名称: %d %+v", rec.Code, list)
+	}
+	if rec := do(h, http.MethodGet, "/api/v1/secrets/cloud-token", "admin-token", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("不能读取 secret 的值，应返回 405，实际 %d", rec.Code)
+	}
+
+	// 响应中出现的 secret 值被替换
+	redacted := s.redact(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusBadGateway, "组件返回错误: token tok-1234 无效")
+	}))
+	if rec := do(redacted, http.MethodGet, "/", "", nil); !strings.Contains(rec.Body.String(), "token [REDACTED] 无效") {
+		t.Errorf("响应中的 secret 值应被替换: %s", rec.Body.String())
+	}
+
+	if rec := do(h, http.MethodDelete, "/api/v1/secrets/cloud-token", "admin-token", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("删除 secret 应返回 204，实际 %d", rec.Code)
+	}
+	if rec := do(h, http.MethodDelete, "/api/v1/secrets/cloud-token", "admin-token", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("删除不存在的 secret 应返回 404，实际 %d", rec.Code)
+	}
+}
+
+func TestSecretsRequireAuth(t *testing.T) {
+	keys, err := auth.NewStore(false, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer("", manager.NewComponentManager(nil), keys, config.HTTP{})
+	h := s.authenticate(s.setupRoutes())
+	rec := do(h, http.MethodGet, "/api/v1/secrets", "", nil)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "管理 secret 需要") {
+		t.Errorf("未启用认证时应说明管理 secret 需要启用认证: %d %s", rec.Code, rec.Body.String())
+	}
+}
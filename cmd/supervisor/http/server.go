@@ -1,35 +1,152 @@
 package http
 
 import (
-	"log"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"sync"
+	"sync/atomic"
 
+	"cse-go/cmd/supervisor/auth"
+	"cse-go/cmd/supervisor/config"
 	"cse-go/cmd/supervisor/manager"
+	"cse-go/cmd/supervisor/secrets"
 )
 
 // Server 是我们的 HTTP 服务器结构体
 type Server struct {
 	addr    string
 	manager *manager.ComponentManager
+	keys    *auth.Store
+	http    config.HTTP
+	metrics *serverMetrics
+	// healthChecks 为 /healthz 与 /readyz 执行的存活检查
+	healthChecks []namedCheck
+
+	// tlsConfig 不为 nil 时同时在 http.TLS.Addr 上提供 HTTPS 服务
+	tlsConfig *tls.Config
+	// caPEM 为本地 CA 证书，供 /api/v1/tls/ca.pem 导出
+	caPEM []byte
+	// secrets 不为 nil 时提供 secret 管理接口并对响应脱敏
+	secrets *secrets.Store
+
+	servers []*http.Server
+	addrs   []string
+	errs    chan error
+	// stopping 在开始关闭时关闭，inflight 为正在执行的组件命令数量
+	stopping chan struct{}
+	stopOnce sync.Once
+	inflight atomic.Int64
 }
 
 // NewServer 创建一个新的 HTTP 服务器实例
-func NewServer(addr string, manager *manager.ComponentManager) *Server {
+func NewServer(addr string, manager *manager.ComponentManager, keys *auth.Store, httpConfig config.HTTP) *Server {
 	return &Server{
-		addr:    addr,
-		manager: manager,
+		addr:     addr,
+		manager:  manager,
+		keys:     keys,
+		http:     httpConfig,
+		metrics:  newServerMetrics(manager),
+		errs:     make(chan error, 2),
+		stopping: make(chan struct{}),
 	}
 }
 
-// Start 启动 HTTP 服务器并开始监听
-func (s *Server) Start() {
-	log.Printf("HTTP 服务启动，正在监听 %s", s.addr)
+// EnableTLS 使用证书提供 HTTPS 服务。caPEM 为签发该证书的本地 CA，使用用户自己的证书时为 nil
+func (s *Server) EnableTLS(cert *tls.Certificate, caPEM []byte) {
+	s.tlsConfig = &tls.Config{
+		Certificates: []tls.Certificate{*cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	s.caPEM = caPEM
+}
 
-	// 设置路由
-	mux := s.setupRoutes()
+// Start 在 HTTP 与 HTTPS 地址上开始监听并在后台提供服务，任一监听失败时返回错误。
+// 服务运行中出现的错误写入 Err 返回的通道
+func (s *Server) Start() error {
+	handler := s.handler(s.setupRoutes())
 
-	// 启动服务器
-	if err := http.ListenAndServe(s.addr, mux); err != nil {
-		log.Fatalf("启动 HTTP 服务失败: %v", err)
+	type listener struct {
+		server *http.Server
+		tls    bool
+	}
+	var listeners []listener
+	if s.tlsConfig != nil {
+		listeners = append(listeners, listener{&http.Server{Addr: s.http.TLS.Addr, Handler: handler, TLSConfig: s.tlsConfig}, true})
+	}
+	if !s.http.DisablePlain {
+		listeners = append(listeners, listener{&http.Server{Addr: s.addr, Handler: handler}, false})
 	}
+
+	for _, l := range listeners {
+		lis, err := net.Listen("tcp", l.server.Addr)
+		if err != nil {
+			for _, server := range s.servers {
+				server.Close()
+			}
+			return fmt.Errorf("无法监听 %s: %w", l.server.Addr, err)
+		}
+		s.servers = append(s.servers, l.server)
+		s.addrs = append(s.addrs, lis.Addr().String())
+		go func(server *http.Server, tls bool) {
+			var err error
+			if tls {
+				slog.Info("HTTPS 服务启动", "addr", lis.Addr())
+				err = server.ServeTLS(lis, "", "")
+			} else {
+				slog.Info("HTTP 服务启动", "addr", lis.Addr())
+				err = server.Serve(lis)
+			}
+			if !errors.Is(err, http.ErrServerClosed) {
+				s.errs <- err
+			}
+		}(l.server, l.tls)
+	}
+	return nil
+}
+
+// Addrs 返回正在监听的地址
+func (s *Server) Addrs() []string {
+	return s.addrs
+}
+
+// Err 返回服务运行中出错时写入错误的通道
+func (s *Server) Err() <-chan error {
+	return s.errs
+}
+
+// Shutdown 停止接受新的连接，等待进行中的请求 (包括正在执行的组件命令) 完成，
+// 持续输出日志的请求会被立即结束。ctx 到期时强制关闭剩余连接并返回错误
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stopping) })
+	if n := s.inflight.Load(); n > 0 {
+		slog.Info("等待进行中的命令完成", "count", n)
+	}
+
+	errs := make(chan error, len(s.servers))
+	for _, server := range s.servers {
+		go func(server *http.Server) {
+			errs <- server.Shutdown(ctx)
+		}(server)
+	}
+	var err error
+	for range s.servers {
+		err = errors.Join(err, <-errs)
+	}
+	if err != nil {
+		for _, server := range s.servers {
+			server.Close()
+		}
+		return fmt.Errorf("等待进行中的请求超时，剩余 %d 个命令被中断: %w", s.inflight.Load(), err)
+	}
+	return nil
+}
+
+// handler 按顺序组合请求追踪、Host 校验、跨域处理与认证中间件
+func (s *Server) handler(mux *http.ServeMux) http.Handler {
+	return s.trace(s.validateHost(s.cors(s.authenticate(s.redact(mux)))))
 }
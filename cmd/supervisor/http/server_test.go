@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"cse-go/cmd/supervisor/manager"
+)
+
+// startServerForTest 在随机端口上启动服务器，返回其地址
+func startServerForTest(t *testing.T, s *Server) string {
+	t.Helper()
+	s.addr = "127.0.0.1:0"
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, server := range s.servers {
+			server.Close()
+		}
+	})
+	return s.Addrs()[0]
+}
+
+// send 发送请求，Host 使用配置的监听地址以通过 Host 校验
+func send(addr, method, path, body string) (*http.Response, error) {
+	req, _ := http.NewRequest(method, "http://"+addr+path, strings.NewReader(body))
+	req.Host = "127.0.0.1:0"
+	req.Header.Set("Authorization", "Bearer admin-token")
+	return http.DefaultClient.Do(req)
+}
+
+// executeInBackground 在后台调用一个会阻塞的命令，等到命令开始执行后返回结果通道
+func executeInBackground(t *testing.T, s *Server, addr string) <-chan error {
+	t.Helper()
+	result := make(chan error, 1)
+	go func() {
+		resp, err := send(addr, http.MethodPost, "/api/v1/execute", `{"component_name": "printer", "command_name": "print.testPrint"}`)
+		if err == nil {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"success":true`) {
+				err = io.ErrUnexpectedEOF
+			}
+		}
+		result <- err
+	}()
+	for deadline := time.Now().Add(5 * time.Second); s.inflight.Load() == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("命令未开始执行")
+		}
+	}
+	return result
+}
+
+func newBlockingServer(t *testing.T) (*Server, *fakeComponent) {
+	t.Helper()
+	s := newServerForTest(t)
+	component := &fakeComponent{payload: `{"success": true}`, block: make(chan struct{})}
+	s.manager.Lock()
+	s.manager.Components["printer"] = &manager.ComponentInfo{Client: component}
+	s.manager.Unlock()
+	return s, component
+}
+
+func TestShutdownDrainsCommands(t *testing.T) {
+	s, component := newBlockingServer(t)
+	addr := startServerForTest(t, s)
+	result := executeInBackground(t, s, addr)
+
+	shutdownDone := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownDone <- s.Shutdown(ctx)
+	}()
+
+	// 关闭开始后不再接受新的连接
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			break
+		}
+		conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("关闭后仍在接受新的连接")
+		}
+	}
+	select {
+	case err := <-shutdownDone:
+		t.Fatalf("命令完成前 Shutdown 不应返回: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(component.block)
+	if err := <-result; err != nil {
+		t.Errorf("进行中的命令应正常完成: %v", err)
+	}
+	if err := <-shutdownDone; err != nil {
+		t.Errorf("Shutdown 应正常完成: %v", err)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	s, component := newBlockingServer(t)
+	defer close(component.block)
+	addr := startServerForTest(t, s)
+	result := executeInBackground(t, s, addr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); err == nil {
+		t.Error("超过期限时 Shutdown 应返回错误")
+	}
+	if err := <-result; err == nil {
+		t.Error("超过期限时进行中的请求应被中断")
+	}
+}
+
+func TestShutdownEndsLogFollow(t *testing.T) {
+	s := newServerForTest(t)
+	launchForTest(t, s, map[string]string{"printer.json": `{"name": "printer", "cmd": "missing-printer"}`})
+	addr := startServerForTest(t, s)
+
+	resp, err := send(addr, http.MethodGet, "/api/v1/components/printer/logs?follow=true", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Errorf("持续输出日志的请求应在关闭时结束: %v", err)
+	}
+}
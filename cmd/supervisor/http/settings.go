@@ -0,0 +1,42 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// settingsResult 是单个组件重新加载 settings 的结果
+type settingsResult struct {
+	Applied bool   `json:"applied"`
+	Error   string `json:"error,omitempty"`
+}
+
+// reloadSettingsHandler 重新读取组件配置并将有变化的 settings 推送给组件 (POST /api/v1/settings/reload)，
+// 与向 Supervisor 发送 SIGHUP 相同。启用认证时需要管理员密钥
+func (s *Server) reloadSettingsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !s.requireAdmin(w, r, "") {
+			return
+		}
+
+		results, err := s.manager.ReloadSettings()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		components := make(map[string]settingsResult, len(results))
+		for name, err := range results {
+			if err != nil {
+				components[name] = settingsResult{Error: err.Error()}
+				continue
+			}
+			components[name] = settingsResult{Applied: true}
+		}
+		slog.InfoContext(r.Context(), "已重新加载组件 settings", "components", len(components))
+		writeJSON(w, http.StatusOK, map[string]any{"components": components})
+	}
+}
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestReloadSettings(t *testing.T) {
+	s := newServerForTest(t)
+	launchForTest(t, s, map[string]string{"printer.json": `{"name": "printer", "cmd": "missing-printer"}`})
+	h := s.authenticate(s.setupRoutes())
+
+	if rec := do(h, http.MethodPost, "/api/v1/settings/reload", "kiosk-token", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("非管理员密钥应返回 403，实际 %d", rec.Code)
+	}
+	if rec := do(h, http.MethodGet, "/api/v1/settings/reload", "admin-token", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET 应返回 405，实际 %d", rec.Code)
+	}
+	rec := do(h, http.MethodPost, "/api/v1/settings/reload", "admin-token", nil)
+	var resp struct {
+		Components map[string]settingsResult `json:"components"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if rec.Code != http.StatusOK || resp.Components == nil || len(resp.Components) != 0 {
+		t.Errorf("没有组件需要推送时应返回空结果: %d %+v", rec.Code, resp)
+	}
+}
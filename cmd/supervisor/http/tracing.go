@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+
+	"cse-go/internal/tracing"
+)
+
+// trace 为每个请求确定请求 ID 并记录服务端 span。调用方提供的 X-Request-ID 请求头有效时沿用，
+// 否则生成新的请求 ID；traceparent 请求头中的上游 span 作为父 span。
+// 请求 ID 与当前 span 写入响应头，并经上下文传递给处理器与组件
+func (s *Server) trace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(tracing.HeaderRequestID)
+		if !tracing.ValidRequestID(id) {
+			id = tracing.NewRequestID()
+		}
+		ctx := tracing.ContextWithRequestID(r.Context(), id)
+		if parent, ok := tracing.ParseTraceparent(r.Header.Get(tracing.HeaderTraceparent)); ok {
+			ctx = tracing.ContextWithRemoteSpanContext(ctx, parent)
+		}
+		ctx, span := tracing.Start(ctx, r.Method+" "+r.URL.Path, tracing.KindServer)
+		defer span.End()
+		span.SetAttribute("http.request.method", r.Method)
+		span.SetAttribute("url.path", r.URL.Path)
+		span.SetAttribute("cse.request_id", id)
+
+		w.Header().Set(tracing.HeaderRequestID, id)
+		w.Header().Set(tracing.HeaderTraceparent, span.SpanContext().Traceparent())
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		span.SetAttribute("http.response.status_code", rec.status)
+		if rec.status >= http.StatusInternalServerError {
+			span.SetError(http.StatusText(rec.status))
+		}
+	})
+}
+
+// statusRecorder 记录响应的状态码，Unwrap 使 http.ResponseController 仍可刷新流式响应
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cse-go/cmd/supervisor/manager"
+	"cse-go/internal/tracing"
+	pb "cse-go/pkg/api/v1"
+)
+
+func TestRequestTracing(t *testing.T) {
+	h := newCORSTestServer(t)
+
+	// 被拒绝的请求同样带有请求 ID
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/components", nil)
+	req.Host = "attacker.com"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if !tracing.ValidRequestID(rec.Header().Get(tracing.HeaderRequestID)) {
+		t.Errorf("应生成请求 ID: %q", rec.Header().Get(tracing.HeaderRequestID))
+	}
+	if _, ok := tracing.ParseTraceparent(rec.Header().Get(tracing.HeaderTraceparent)); !ok {
+		t.Errorf("应返回 traceparent: %q", rec.Header().Get(tracing.HeaderTraceparent))
+	}
+
+	// 沿用调用方的请求 ID 与调用链
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/components", nil)
+	req.Host = "localhost:18848"
+	req.Header.Set(tracing.HeaderRequestID, "pos-42")
+	req.Header.Set(tracing.HeaderTraceparent, parent)
+	req.Header.Set("Origin", "https://pos.example.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got := rec.Header().Get(tracing.HeaderRequestID); got != "pos-42" {
+		t.Errorf("应沿用调用方的请求 ID，实际 %q", got)
+	}
+	sc, _ := tracing.ParseTraceparent(rec.Header().Get(tracing.HeaderTraceparent))
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() == "00f067aa0ba902b7" {
+		t.Errorf("应在调用方的调用链中创建新的 span: %s", rec.Header().Get(tracing.HeaderTraceparent))
+	}
+	if rec.Header().Get("Access-Control-Expose-Headers") != corsExposeHeaders {
+		t.Errorf("跨域请求应可读取请求 ID 响应头")
+	}
+}
+
+func TestExecutePropagatesRequestID(t *testing.T) {
+	s := newServerForTest(t)
+	h := s.trace(s.authenticate(s.setupRoutes()))
+	component := &fakeComponent{payload: `{"success": true}`}
+	s.manager.Lock()
+	s.manager.Components["printer"] = &manager.ComponentInfo{Client: component, Metadata: &pb.ComponentMetadata{}}
+	s.manager.Unlock()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/execute", strings.NewReader(`{"component_name": "printer", "command_name": "print.testPrint"}`))
+	req.Header.Set("Authorization", "Bearer admin-token")
+	req.Header.Set(tracing.HeaderRequestID, "pos-42")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var resp struct {
+		RequestID string `json:"request_id"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.RequestID != "pos-42" {
+		t.Errorf("响应体应包含请求 ID，实际 %q", resp.RequestID)
+	}
+	if got := component.outgoing.Get("x-request-id"); len(got) != 1 || got[0] != "pos-42" {
+		t.Errorf("请求 ID 应通过 gRPC 元数据传递: %v", got)
+	}
+	// 发往组件的 span 是 HTTP 请求 span 的子 span
+	server, _ := tracing.ParseTraceparent(rec.Header().Get(tracing.HeaderTraceparent))
+	client, ok := tracing.ParseTraceparent(component.outgoing.Get("traceparent")[0])
+	if !ok || client.TraceID != server.TraceID || client.SpanID == server.SpanID {
+		t.Errorf("traceparent 传递不正确: server %+v client %+v", server, client)
+	}
+}
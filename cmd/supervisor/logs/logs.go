@@ -0,0 +1,317 @@
+// Package logs 将组件的标准输出与标准错误逐行写入按大小轮转的日志文件，
+// 每行带有时间与输出流前缀，并支持读取最后若干行与订阅新写入的行
+package logs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxSize  = 10 << 20
+	defaultMaxFiles = 5
+	// maxLineSize 为单行的最大长度，超过时强制换行
+	maxLineSize = 64 << 10
+	// subscriberBuffer 为订阅者的缓冲行数，订阅者处理不及时时丢弃新行
+	subscriberBuffer = 256
+	// tailChunkSize 为 Tail 从文件末尾向前读取的块大小
+	tailChunkSize = 64 << 10
+	// tailAttempts 为读取期间发生轮转时 Tail 重新读取的次数上限
+	tailAttempts = 3
+)
+
+// Options 定义了日志文件的轮转配置
+type Options struct {
+	// MaxSize 为单个日志文件的字节数上限，超过后轮转
+	MaxSize int64
+	// MaxFiles 为保留的日志文件数量 (包括当前文件)
+	MaxFiles int
+	// Redact 不为 nil 时在写入前处理组件输出的每一行，用于隐藏其中的敏感内容
+	Redact func(string) string
+}
+
+// File 是一个组件的日志文件，当前文件为 <name>.log，轮转后的文件为 <name>.1.log、<name>.2.log ...
+type File struct {
+	dir, name string
+	opts      Options
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	// rotations 为轮转次数，Tail 据此定位读取开始时的当前文件轮转后的位置
+	rotations int
+	subs      map[chan string]struct{}
+}
+
+// Open 打开 dir 中名为 name 的日志文件，目录不存在时创建
+func Open(dir, name string, opts Options) (*File, error) {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = defaultMaxSize
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = defaultMaxFiles
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("无法创建日志目录: %w", err)
+	}
+	f := &File{dir: dir, name: name, opts: opts, subs: make(map[chan string]struct{})}
+	if err := f.openCurrent(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// path 返回第 n 个日志文件的路径，0 为当前文件，n 越大越旧
+func (f *File) path(n int) string {
+	if n == 0 {
+		return filepath.Join(f.dir, f.name+".log")
+	}
+	return filepath.Join(f.dir, fmt.Sprintf("%s.%d.log", f.name, n))
+}
+
+func (f *File) openCurrent() error {
+	file, err := os.OpenFile(f.path(0), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("无法打开日志文件: %w", err)
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("无法读取日志文件信息: %w", err)
+	}
+	f.file = file
+	f.size = fi.Size()
+	return nil
+}
+
+// rotate 将当前文件依次后移并打开新文件，调用方需持有锁
+func (f *File) rotate() error {
+	f.file.Close()
+	os.Remove(f.path(f.opts.MaxFiles - 1))
+	for n := f.opts.MaxFiles - 2; n >= 0; n-- {
+		if err := os.Rename(f.path(n), f.path(n+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("轮转日志文件失败: %w", err)
+		}
+	}
+	f.rotations++
+	return f.openCurrent()
+}
+
+// WriteLine 追加一行日志并通知订阅者
+func (f *File) WriteLine(line string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return errors.New("日志文件已关闭")
+	}
+	n := int64(len(line) + 1)
+	if f.size > 0 && f.size+n > f.opts.MaxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	written, err := f.file.WriteString(line + "\n")
+	f.size += int64(written)
+	for ch := range f.subs {
+		select {
+		case ch <- line:
+		default:
+		}
+	}
+	return err
+}
+
+// Tail 返回最后 n 行日志，按时间正序排列，需要时读取轮转后的文件。
+// 读取时不持有写入锁，只返回调用时已写入的行
+func (f *File) Tail(n int) ([]string, error) {
+	f.mu.Lock()
+	size, rotations := f.size, f.rotations
+	f.mu.Unlock()
+	return f.tail(n, size, rotations)
+}
+
+// tail 返回当前文件为前 size 字节、轮转次数为 rotations 时的最后 n 行。
+// 读取期间发生轮转时按新的文件位置重新读取
+func (f *File) tail(n int, size int64, rotations int) ([]string, error) {
+	for attempt := 1; ; attempt++ {
+		f.mu.Lock()
+		shift := f.rotations - rotations
+		f.mu.Unlock()
+		lines, err := f.readTail(n, size, shift)
+		f.mu.Lock()
+		rotated := f.rotations-rotations != shift
+		f.mu.Unlock()
+		if !rotated || attempt == tailAttempts {
+			return lines, err
+		}
+	}
+}
+
+// readTail 从第 shift 个文件的前 size 字节开始向更旧的文件读取最后 n 行
+func (f *File) readTail(n int, size int64, shift int) ([]string, error) {
+	var lines []string
+	for i := shift; i < f.opts.MaxFiles && len(lines) < n; i++ {
+		limit := int64(-1)
+		if i == shift {
+			limit = size
+		}
+		fileLines, err := readLastLines(f.path(i), n-len(lines), limit)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(fileLines, lines...)
+	}
+	return lines, nil
+}
+
+// readLastLines 从文件的前 limit 字节 (limit 小于 0 时为整个文件) 的末尾向前读取最后 n 行，
+// 文件不存在时返回空结果
+func readLastLines(path string, n int, limit int64) ([]string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取日志文件: %w", err)
+	}
+	defer file.Close()
+	if limit < 0 {
+		fi, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("无法读取日志文件信息: %w", err)
+		}
+		limit = fi.Size()
+	}
+
+	// 读到多于 n 个换行符时，最后一个换行符之前至少有 n 个完整的行
+	var data []byte
+	offset, newlines := limit, 0
+	for offset > 0 && newlines <= n {
+		chunk := make([]byte, min(tailChunkSize, offset))
+		offset -= int64(len(chunk))
+		if _, err := file.ReadAt(chunk, offset); err != nil {
+			return nil, fmt.Errorf("无法读取日志文件: %w", err)
+		}
+		newlines += bytes.Count(chunk, []byte{'\n'})
+		data = append(chunk, data...)
+	}
+	data = bytes.TrimSuffix(data, []byte{'\n'})
+	if len(data) == 0 {
+		return nil, nil
+	}
+	lines := strings.Split(string(data), "\n")
+	if offset > 0 {
+		// 第一行可能不完整
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+// Subscribe 订阅之后写入的行，返回的函数用于取消订阅
+func (f *File) Subscribe() (<-chan string, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.subscribe()
+}
+
+// subscribe 注册一个订阅者，调用方需持有锁
+func (f *File) subscribe() (<-chan string, func()) {
+	ch := make(chan string, subscriberBuffer)
+	f.subs[ch] = struct{}{}
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			f.mu.Lock()
+			delete(f.subs, ch)
+			f.mu.Unlock()
+		})
+	}
+}
+
+// Follow 返回最后 n 行日志并订阅之后写入的行，返回的函数用于取消订阅。
+// 订阅与确定已有内容的范围在同一次加锁中完成，每一行只出现在已有内容或订阅中的一处
+func (f *File) Follow(n int) ([]string, <-chan string, func(), error) {
+	f.mu.Lock()
+	ch, cancel := f.subscribe()
+	size, rotations := f.size, f.rotations
+	f.mu.Unlock()
+	lines, err := f.tail(n, size, rotations)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+	return lines, ch, cancel, nil
+}
+
+// Close 关闭日志文件
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// StreamWriter 返回写入指定输出流 (stdout 或 stderr) 的 Writer，可直接赋给 exec.Cmd 的 Stdout 或 Stderr。
+// 写入的内容按行拆分，每行加上 "<时间> <输出流> " 前缀后写入日志文件；
+// mirror 不为 nil 时同时以 "[<组件名>] " 为前缀写入 mirror
+func (f *File) StreamWriter(stream string, mirror io.Writer) io.Writer {
+	return &lineWriter{file: f, stream: stream, mirror: mirror}
+}
+
+// lineWriter 将写入的内容按行拆分，不完整的行保留到下次写入
+type lineWriter struct {
+	file   *File
+	stream string
+	mirror io.Writer
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			if len(w.buf) < maxLineSize {
+				break
+			}
+			i = maxLineSize
+		}
+		line := string(bytes.TrimRight(w.buf[:i], "\r"))
+		if i < len(w.buf) && w.buf[i] == '\n' {
+			i++
+		}
+		w.buf = w.buf[i:]
+		w.emit(line)
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) emit(line string) {
+	if w.file.opts.Redact != nil {
+		line = w.file.opts.Redact(line)
+	}
+	prefix := time.Now().Format("2006-01-02T15:04:05.000Z07:00") + " " + w.stream + " "
+	// 写入失败不返回错误，避免组件因输出管道出错而阻塞
+	w.file.WriteLine(prefix + line)
+	if w.mirror != nil {
+		fmt.Fprintf(w.mirror, "[%s] %s\n", w.file.name, line)
+	}
+}
@@ -0,0 +1,179 @@
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStreamWriter 测试按行拆分、添加前缀与同步输出
+func TestStreamWriter(t *testing.T) {
+	f, err := Open(t.TempDir(), "printer", Options{})
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	defer f.Close()
+
+	var mirror bytes.Buffer
+	w := f.StreamWriter("stderr", &mirror)
+	fmt.Fprint(w, "first line\nsecond ")
+	fmt.Fprint(w, "line\r\nincomplete")
+
+	lines, err := f.Tail(10)
+	if err != nil || len(lines) != 2 {
+		t.Fatalf("应写入两行完整的日志: %q (%v)", lines, err)
+	}
+	for i, want := range []string{"first line", "second line"} {
+		fields := strings.SplitN(lines[i], " ", 3)
+		if len(fields) != 3 || fields[1] != "stderr" || fields[2] != want {
+			t.Errorf("第 %d 行格式不正确: %q", i+1, lines[i])
+		}
+		if _, err := time.Parse("2006-01-02T15:04:05.000Z07:00", fields[0]); err != nil {
+			t.Errorf("第 %d 行的时间前缀无效: %v", i+1, err)
+		}
+	}
+	if mirror.String() != "[printer] first line\n[printer] second line\n" {
+		t.Errorf("同步输出不正确: %q", mirror.String())
+	}
+}
+
+// TestStreamWriterRedact 测试写入日志文件与同步输出前处理每一行
+func TestStreamWriterRedact(t *testing.T) {
+	f, err := Open(t.TempDir(), "printer", Options{Redact: func(line string) string {
+		return strings.ReplaceAll(line, "s3cret", "[REDACTED]")
+	}})
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	defer f.Close()
+
+	var mirror bytes.Buffer
+	fmt.Fprint(f.StreamWriter("stdout", &mirror), "token=s3cret\n")
+	lines, _ := f.Tail(1)
+	if len(lines) != 1 || !strings.HasSuffix(lines[0], " stdout token=[REDACTED]") {
+		t.Errorf("日志文件中的内容应被处理: %q", lines)
+	}
+	if mirror.String() != "[printer] token=[REDACTED]\n" {
+		t.Errorf("同步输出的内容应被处理: %q", mirror.String())
+	}
+}
+
+// TestRotationAndTail 测试轮转后 Tail 跨文件读取，并只保留指定数量的文件
+func TestRotationAndTail(t *testing.T) {
+	dir := t.TempDir()
+	f, err := Open(dir, "printer", Options{MaxSize: 100, MaxFiles: 3})
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	defer f.Close()
+	for i := 0; i < 30; i++ {
+		f.WriteLine(fmt.Sprintf("line %02d", i))
+	}
+
+	for _, name := range []string{"printer.log", "printer.1.log", "printer.2.log"} {
+		if fi, err := os.Stat(filepath.Join(dir, name)); err != nil || fi.Size() > 100 {
+			t.Errorf("日志文件 %s 不存在或超过大小上限: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "printer.3.log")); !os.IsNotExist(err) {
+		t.Error("超过保留数量的日志文件应被删除")
+	}
+
+	lines, err := f.Tail(15)
+	if err != nil || len(lines) != 15 || lines[0] != "line 15" || lines[14] != "line 29" {
+		t.Errorf("Tail 结果不正确: %q (%v)", lines, err)
+	}
+}
+
+// TestTailAcrossChunks 测试最后若干行跨越多个读取块时 Tail 返回完整的行
+func TestTailAcrossChunks(t *testing.T) {
+	f, err := Open(t.TempDir(), "printer", Options{})
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	defer f.Close()
+	for i := 0; i < 10000; i++ {
+		f.WriteLine(fmt.Sprintf("line %05d %s", i, strings.Repeat("x", 20)))
+	}
+
+	lines, err := f.Tail(5000)
+	if err != nil || len(lines) != 5000 || !strings.HasPrefix(lines[0], "line 05000 ") || !strings.HasPrefix(lines[4999], "line 09999 ") {
+		t.Fatalf("Tail 结果不正确: %d 行 (%v)", len(lines), err)
+	}
+	if lines, _ := f.Tail(20000); len(lines) != 10000 || !strings.HasPrefix(lines[0], "line 00000 ") {
+		t.Errorf("行数不足时应返回所有行: %d 行", len(lines))
+	}
+}
+
+// TestFollow 测试 Follow 返回的已有内容与订阅的新行不重复
+func TestFollow(t *testing.T) {
+	f, err := Open(t.TempDir(), "printer", Options{MaxSize: 100, MaxFiles: 3})
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	defer f.Close()
+	f.WriteLine("before")
+
+	lines, ch, cancel, err := f.Follow(10)
+	if err != nil {
+		t.Fatalf("订阅日志失败: %v", err)
+	}
+	defer cancel()
+	if len(lines) != 1 || lines[0] != "before" {
+		t.Errorf("已有内容不正确: %q", lines)
+	}
+	f.WriteLine("after")
+	select {
+	case line := <-ch:
+		if line != "after" {
+			t.Errorf("订阅收到 %q", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("订阅者未收到新行")
+	}
+
+	// 确定范围之后发生轮转时，仍只返回范围内的行
+	f.mu.Lock()
+	size, rotations := f.size, f.rotations
+	f.mu.Unlock()
+	for i := 0; i < 15; i++ {
+		f.WriteLine(fmt.Sprintf("line %02d", i))
+	}
+	if f.rotations == rotations {
+		t.Fatal("日志文件应已轮转")
+	}
+	if lines, err := f.tail(10, size, rotations); err != nil || strings.Join(lines, ",") != "before,after" {
+		t.Errorf("轮转后读取的已有内容不正确: %q (%v)", lines, err)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	f, err := Open(t.TempDir(), "printer", Options{})
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	defer f.Close()
+
+	ch, cancel := f.Subscribe()
+	f.WriteLine("hello")
+	select {
+	case line := <-ch:
+		if line != "hello" {
+			t.Errorf("订阅收到 %q", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("订阅者未收到新行")
+	}
+	cancel()
+	cancel()
+	f.WriteLine("after cancel")
+	select {
+	case line := <-ch:
+		t.Errorf("取消订阅后不应收到 %q", line)
+	default:
+	}
+}
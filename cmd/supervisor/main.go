@@ -2,27 +2,61 @@ package main
 
 import (
 	"context"
-	"log"
-	"net"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
+	"cse-go/cmd/supervisor/auth"
+	"cse-go/cmd/supervisor/certs"
+	"cse-go/cmd/supervisor/config"
 	"cse-go/cmd/supervisor/http"
+	"cse-go/cmd/supervisor/logs"
 	"cse-go/cmd/supervisor/manager" // [已更新] 导入新的 manager 包
+	"cse-go/cmd/supervisor/secrets"
+	"cse-go/internal/commandbus"
+	"cse-go/internal/mtls"
+	"cse-go/internal/tracing"
+	"cse-go/internal/transport"
 	pb "cse-go/pkg/api/v1"
 
 	utils "cse-go/cmd/utils"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
 )
 
 const (
 	discoveryServiceAddress = "localhost:50050"
 	httpServiceAddress      = "localhost:18848"
+
+	// componentCertValidity 为组件 mTLS 证书的有效期，过半后自动续签
+	componentCertValidity = 24 * time.Hour
+	certRenewInterval     = 10 * time.Minute
+)
+
+// 进程退出码
+const (
+	exitOK = 0
+	// exitError 表示启动失败或服务运行中出错
+	exitError = 1
+	// exitIncomplete 表示关闭未能在期限内完成: 有请求被中断或组件被强制终止
+	exitIncomplete = 2
 )
 
+// fatal 记录错误日志并退出
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(exitError)
+}
+
 // discoveryServer 实现了 ComponentDiscoveryService
 type discoveryServer struct {
 	pb.UnimplementedComponentDiscoveryServiceServer
@@ -31,67 +65,398 @@ type discoveryServer struct {
 
 // RegisterComponent 将注册逻辑委托给 ComponentManager
 func (s *discoveryServer) RegisterComponent(ctx context.Context, req *pb.RegisterComponentRequest) (*pb.RegisterComponentResponse, error) {
+	// 组件只能以其证书中的名称注册
+	if name := peerCertName(ctx); name != req.Name {
+		slog.Warn("拒绝注册: 证书名称与组件名称不一致", "component", req.Name, "certificate", name)
+		return &pb.RegisterComponentResponse{Success: false, Message: "证书与组件名称不一致"}, nil
+	}
 	err := s.manager.HandleRegistration(req)
 	if err != nil {
-		log.Printf("[Discovery Service] 错误: 处理组件 '%s' 注册失败: %v", req.Name, err)
+		slog.Error("处理组件注册失败", "component", req.Name, "error", err)
 		return &pb.RegisterComponentResponse{Success: false, Message: err.Error()}, nil
 	}
 	return &pb.RegisterComponentResponse{Success: true, Message: ""}, nil
 }
 
-// startDiscoveryService 启动监听组件注册的 gRPC 服务
-func startDiscoveryService(manager *manager.ComponentManager) *grpc.Server {
-	lis, err := net.Listen("tcp", discoveryServiceAddress)
+// peerCertName 返回调用方 mTLS 证书的名称
+func peerCertName(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ""
+	}
+	return mtls.PeerName(info.State)
+}
+
+// startDiscoveryService 启动监听组件注册的 gRPC 服务，要求组件使用内部 CA 签发的证书。
+// 服务同时提供标准的 gRPC 健康检查，返回的 health.Server 用于在关闭时报告 NOT_SERVING
+func startDiscoveryService(addr string, manager *manager.ComponentManager, pki *certs.Internal) (*grpc.Server, *health.Server) {
+	lis, err := transport.Listen(addr)
 	if err != nil {
-		log.Fatalf("无法监听发现服务端口 %s: %v", discoveryServiceAddress, err)
+		fatal("无法监听发现服务地址", "addr", addr, "error", err)
 	}
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(grpc.Creds(credentials.NewTLS(pki.ServerConfig())))
 	pb.RegisterComponentDiscoveryServiceServer(s, &discoveryServer{manager: manager})
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(s, healthServer)
 
 	go func() {
-		log.Printf("组件发现服务启动成功，正在监听 %s", discoveryServiceAddress)
+		slog.Info("组件发现服务启动成功", "addr", addr)
 		if err := s.Serve(lis); err != nil && err != grpc.ErrServerStopped {
-			log.Fatalf("发现服务意外停止: %v", err)
+			fatal("发现服务意外停止", "error", err)
 		}
 	}()
-	return s
+	return s, healthServer
+}
+
+// discoveryHealthCheck 返回 /healthz 使用的检查：以 Supervisor 自身的证书连接发现服务，
+// 通过 gRPC 健康检查确认其正在提供服务
+func discoveryHealthCheck(addr string, pki *certs.Internal) http.HealthCheck {
+	creds := credentials.NewTLS(pki.ClientConfig(mtls.SupervisorName))
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		fatal("无法创建发现服务的健康检查连接", "error", err)
+	}
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("发现服务状态为 %s", resp.Status)
+		}
+		return nil
+	}
+}
+
+// setupRuntimeDir 创建存放 Unix 域套接字的目录 (仅当前用户可访问)，返回目录与退出时的清理函数
+func setupRuntimeDir(cfg config.Components) (string, func()) {
+	if cfg.RuntimeDir != "" {
+		if err := os.MkdirAll(cfg.RuntimeDir, 0o700); err != nil {
+			fatal("无法创建运行时目录", "error", err)
+		}
+		if err := os.Chmod(cfg.RuntimeDir, 0o700); err != nil {
+			fatal("无法设置运行时目录权限", "error", err)
+		}
+		return cfg.RuntimeDir, func() {}
+	}
+	dir, err := os.MkdirTemp("", "cse-supervisor-")
+	if err != nil {
+		fatal("无法创建运行时目录", "error", err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// setupLogging 配置 Supervisor 的日志，并使组件输出写入 <日志目录>/<组件名>.log
+func setupLogging(compManager *manager.ComponentManager, cfg config.Logging, dataDir string) {
+	if err := commandbus.SetupLogging(os.Stderr, cfg.Level, cfg.Format); err != nil {
+		fatal("日志配置错误", "error", err)
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = filepath.Join(dataDir, "logs")
+	}
+	opts := manager.LogOptions{
+		Dir:      dir,
+		Rotation: logs.Options{MaxSize: int64(cfg.MaxSizeMB) << 20, MaxFiles: cfg.MaxFiles},
+		Levels:   cfg.Components,
+	}
+	if cfg.MirrorComponents {
+		opts.Mirror = os.Stderr
+	}
+	compManager.SetLogging(opts)
+	slog.Info("组件日志写入目录", "dir", dir)
+}
+
+// setupTracing 启用时将 Supervisor 与组件的 span 写入 span 目录，返回退出时关闭文件的函数
+func setupTracing(compManager *manager.ComponentManager, cfg config.Tracing, dataDir string) func() {
+	if !cfg.Enabled {
+		return func() {}
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = filepath.Join(dataDir, "traces")
+	}
+	exporter, err := tracing.NewFileExporter(filepath.Join(dir, "supervisor.jsonl"), mtls.SupervisorName)
+	if err != nil {
+		fatal("无法启用请求追踪", "error", err)
+	}
+	tracing.SetExporter(exporter)
+	compManager.SetTracing(dir)
+	slog.Info("请求追踪已启用，span 写入目录", "dir", dir)
+	return func() {
+		tracing.SetExporter(nil)
+		exporter.Close()
+	}
+}
+
+// setupSecrets 打开 secret 存储，Supervisor 的日志与组件输出中的 secret 值被替换为 "[REDACTED]"。
+// 未配置主密钥时返回 nil，此时引用了 secret 的组件 settings 无法推送
+func setupSecrets(compManager *manager.ComponentManager, cfg config.Secrets, dataDir string) *secrets.Store {
+	key, err := secrets.LoadKey(cfg.KeyFile)
+	if err != nil {
+		fatal("secret 主密钥配置错误", "error", err)
+	}
+	if key == nil {
+		slog.Info("未配置 secret 主密钥，组件 settings 不能引用 secret", "env", secrets.KeyEnv)
+		return nil
+	}
+	file := cfg.File
+	if file == "" {
+		file = filepath.Join(dataDir, "secrets.enc")
+	}
+	store, err := secrets.Open(file, key)
+	if err != nil {
+		fatal("无法打开 secret 存储", "error", err)
+	}
+	slog.SetDefault(slog.New(secrets.NewLogHandler(slog.Default().Handler(), store)))
+	compManager.SetSecrets(store)
+	slog.Info("已打开 secret 存储", "file", file, "secrets", len(store.List()))
+	return store
+}
+
+// setupKeys 创建访问密钥存储。启用认证但没有任何密钥时生成一个管理员密钥，
+// 写入配置文件所在目录的 admin.key 文件 (仅当前用户可读)
+func setupKeys(configPath string, cfg *config.Config) *auth.Store {
+	persist := func(keys []*auth.Key) error {
+		return config.SaveSection(configPath, "auth", config.Auth{Enabled: cfg.Auth.Enabled, Keys: keys})
+	}
+	keys, err := auth.NewStore(cfg.Auth.Enabled, cfg.Auth.Keys, persist)
+	if err != nil {
+		fatal("访问密钥配置错误", "error", err)
+	}
+	if !keys.Enabled() {
+		slog.Warn("HTTP API 未启用认证，任何本地进程都可以调用")
+		return keys
+	}
+	if len(keys.List()) > 0 {
+		slog.Info("HTTP API 已启用认证", "keys", len(keys.List()))
+		return keys
+	}
+
+	token, _, err := keys.Create(auth.Key{Name: "admin", Admin: true})
+	if err != nil {
+		fatal("无法生成管理员密钥", "error", err)
+	}
+	keyFile := filepath.Join(filepath.Dir(configPath), "admin.key")
+	if err := os.WriteFile(keyFile, []byte(token+"\n"), 0o600); err != nil {
+		fatal("无法写入管理员密钥文件", "error", err)
+	}
+	slog.Warn("已生成管理员密钥，请妥善保管后删除该文件", "file", keyFile)
+	return keys
+}
+
+// defaultDataDir 返回主程序所在目录下的 data/supervisor 目录
+func defaultDataDir() string {
+	exePath, err := os.Executable()
+	if err != nil {
+		return filepath.Join("data", "supervisor")
+	}
+	return filepath.Join(filepath.Dir(exePath), "data", "supervisor")
+}
+
+// setupTLS 为 HTTP 服务器配置 HTTPS。配置了证书时直接使用，否则由本地 CA 签发 localhost 证书
+func setupTLS(httpServer *http.Server, cfg *config.Config, dataDir string) {
+	tlsCfg := cfg.HTTP.TLS
+	if tlsCfg.CertFile != "" {
+		cert, err := certs.LoadKeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+		if err != nil {
+			fatal("加载 HTTPS 证书失败", "error", err)
+		}
+		httpServer.EnableTLS(cert, nil)
+		return
+	}
+
+	certDir := filepath.Join(dataDir, "certs")
+	hosts := certs.ServerHosts(cfg.HTTP.AllowedHosts)
+	ca, err := certs.LoadOrCreateCA(certDir, "CSE Local CA", hosts)
+	if err != nil {
+		fatal("加载本地 CA 失败", "error", err)
+	}
+	cert, err := certs.LoadOrIssueServerCert(certDir, ca, hosts)
+	if err != nil {
+		fatal("签发 HTTPS 证书失败", "error", err)
+	}
+	httpServer.EnableTLS(cert, ca.PEM)
+	slog.Info("HTTPS 使用本地 CA 签发的证书，请将 CA 证书安装到系统信任列表 (或通过 /api/v1/tls/ca.pem 下载)",
+		"ca", filepath.Join(certDir, "ca.pem"))
+}
+
+// exportCA 将本地 CA 证书写入 dest ("-" 表示标准输出)，CA 不存在时生成，
+// 生成的 CA 只能为回环地址与 allowedHosts 签发证书
+func exportCA(dataDir, dest string, allowedHosts []string) {
+	ca, err := certs.LoadOrCreateCA(filepath.Join(dataDir, "certs"), "CSE Local CA", certs.ServerHosts(allowedHosts))
+	if err != nil {
+		fatal("加载本地 CA 失败", "error", err)
+	}
+	if dest == "-" {
+		os.Stdout.Write(ca.PEM)
+		return
+	}
+	if err := os.WriteFile(dest, ca.PEM, 0o644); err != nil {
+		fatal("导出 CA 证书失败", "error", err)
+	}
+	slog.Info("已导出本地 CA 证书", "file", dest)
 }
 
 func main() {
-	log.Printf("CSE 主应用程序 (Supervisor) 启动, 当前操作系统 %s...", utils.GetOSType())
-	compManager := manager.NewComponentManager()
+	configPath := flag.String("config", "supervisor.json", "Supervisor config file")
+	dataDir := flag.String("data-dir", "", "Directory for persistent supervisor data, defaults to <exe-dir>/data/supervisor")
+	exportCAPath := flag.String("export-ca", "", "Write the local CA certificate to this file (- for stdout) and exit")
+	generateSecretsKey := flag.Bool("generate-secrets-key", false, "Print a new base64 master key for the secrets store and exit")
+	flag.Parse()
+	if *generateSecretsKey {
+		key, err := secrets.GenerateKey()
+		if err != nil {
+			fatal("无法生成主密钥", "error", err)
+		}
+		fmt.Println(key)
+		return
+	}
+	if *dataDir == "" {
+		*dataDir = defaultDataDir()
+	}
+	// 证书等文件的路径传递给组件，组件可能使用不同的工作目录
+	if abs, err := filepath.Abs(*dataDir); err == nil {
+		*dataDir = abs
+	}
 
-	// 1. 启动 gRPC 发现服务
-	discoveryGrpcServer := startDiscoveryService(compManager)
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fatal("加载配置失败", "error", err)
+	}
+	if *exportCAPath != "" {
+		exportCA(*dataDir, *exportCAPath, cfg.HTTP.AllowedHosts)
+		return
+	}
+	pki, err := certs.NewInternal(filepath.Join(*dataDir, "run", "components"), componentCertValidity)
+	if err != nil {
+		fatal("初始化内部 CA 失败", "error", err)
+	}
+	compManager := manager.NewComponentManager(pki)
+	compManager.SetReadyTimeout(time.Duration(cfg.Components.ReadyTimeoutSeconds) * time.Second)
+	compManager.SetCgroupDir(cfg.Components.CgroupDir)
+	setupLogging(compManager, cfg.Logging, *dataDir)
+	closeTracing := setupTracing(compManager, cfg.Tracing, *dataDir)
+	secretStore := setupSecrets(compManager, cfg.Secrets, *dataDir)
+	slog.Info("CSE 主应用程序 (Supervisor) 启动", "os", utils.GetOSType())
 
-	// 2. 启动 HTTP API 服务
-	httpServer := http.NewServer(httpServiceAddress, compManager)
-	go httpServer.Start()
+	keys := setupKeys(*configPath, cfg)
+	pki.Start(certRenewInterval)
 
-	// 等待服务启动
-	time.Sleep(time.Second)
+	discoveryAddr := discoveryServiceAddress
+	cleanupRuntimeDir := func() {}
+	if cfg.Components.Transport == config.TransportUnix {
+		var runtimeDir string
+		runtimeDir, cleanupRuntimeDir = setupRuntimeDir(cfg.Components)
+		discoveryAddr = transport.UnixAddress(filepath.Join(runtimeDir, "discovery.sock"))
+		compManager.UseUnixSockets(runtimeDir)
+		slog.Info("组件通过 Unix 域套接字通信", "runtime_dir", runtimeDir)
+	}
 
-	// 3. 启动所有组件
-	compManager.LaunchComponents("./configs", discoveryServiceAddress)
+	// 1. 启动 gRPC 发现服务
+	discoveryGrpcServer, discoveryHealth := startDiscoveryService(discoveryAddr, compManager, pki)
 
-	log.Println("所有服务已启动。按 Ctrl+C 关闭。")
+	// 2. 启动 HTTP API 服务
+	httpServer := http.NewServer(httpServiceAddress, compManager, keys, cfg.HTTP)
+	httpServer.AddHealthCheck("discovery", discoveryHealthCheck(discoveryAddr, pki))
+	httpServer.SetSecrets(secretStore)
+	if cfg.HTTP.TLS.Enabled {
+		setupTLS(httpServer, cfg, *dataDir)
+	}
+	if err := httpServer.Start(); err != nil {
+		fatal("启动 HTTP 服务失败", "error", err)
+	}
 
-	// 4. 等待关闭信号以实现优雅退出
+	// 3. 在后台按依赖关系分阶段启动所有组件，等待依赖期间仍可响应关闭信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	go func() {
+		compManager.LaunchComponents("./configs", discoveryAddr)
+		slog.Info("组件启动流程已完成。按 Ctrl+C 关闭。")
+	}()
+
+	// 4. 等待关闭信号以实现优雅退出
+	// SIGHUP 重新加载组件的 settings
+	exitCode := exitOK
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+wait:
+	for {
+		select {
+		case <-hup:
+			// 推送 settings 期间仍可响应关闭信号
+			go reloadSettings(compManager)
+		case sig := <-quit:
+			slog.Info("收到关闭信号，正在关闭所有服务", "signal", sig)
+			break wait
+		case err := <-httpServer.Err():
+			slog.Error("HTTP 服务意外停止，正在关闭所有服务", "error", err)
+			exitCode = exitError
+			break wait
+		}
+	}
+	signal.Stop(hup)
+	go func() {
+		<-quit
+		slog.Error("再次收到关闭信号，立即退出")
+		os.Exit(exitError)
+	}()
 
-	log.Println("收到关闭信号，正在关闭所有服务...")
+	if !shutdown(cfg.Shutdown, httpServer, compManager, discoveryGrpcServer, discoveryHealth) && exitCode == exitOK {
+		exitCode = exitIncomplete
+	}
+	pki.Stop()
+	cleanupRuntimeDir()
+	closeTracing()
 
-	// 优雅地关闭所有组件
-	compManager.ShutdownAllComponents()
+	if exitCode == exitOK {
+		slog.Info("所有服务已成功关闭")
+	}
+	os.Exit(exitCode)
+}
 
-	// 优雅地关闭 gRPC 服务
-	discoveryGrpcServer.GracefulStop()
+// reloadSettings 重新加载组件的 settings 并记录结果
+func reloadSettings(compManager *manager.ComponentManager) {
+	slog.Info("收到 SIGHUP，正在重新加载组件 settings")
+	results, err := compManager.ReloadSettings()
+	if err != nil {
+		slog.Error("重新加载组件 settings 失败", "error", err)
+		return
+	}
+	failed := 0
+	for _, err := range results {
+		if err != nil {
+			failed++
+		}
+	}
+	slog.Info("组件 settings 重新加载完成", "changed", len(results), "failed", failed)
+}
 
-	// 注意: HTTP 服务器的优雅关闭可以在 http/server.go 中实现，
-	// 此处为简化暂未添加，但实际生产中应添加。
+// shutdown 按顺序关闭所有服务：停止接受新的 HTTP 请求并等待进行中的命令完成，
+// 按启动顺序的逆序关闭组件，最后停止发现服务。有请求被中断或组件被强制终止时返回 false
+func shutdown(cfg config.Shutdown, httpServer *http.Server, compManager *manager.ComponentManager,
+	discoveryGrpcServer *grpc.Server, discoveryHealth *health.Server) bool {
+	ok := true
+	// 健康检查立即报告正在关闭
+	discoveryHealth.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.DrainTimeoutSeconds)*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		slog.Warn("HTTP 服务未能正常关闭", "error", err)
+		ok = false
+	}
 
-	log.Println("所有服务已成功关闭。")
+	if err := compManager.ShutdownAllComponents(time.Duration(cfg.ComponentTimeoutSeconds) * time.Second); err != nil {
+		ok = false
+	}
+
+	discoveryGrpcServer.GracefulStop()
+	return ok
 }
@@ -0,0 +1,130 @@
+package manager
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// 组件的启动状态
+const (
+	// LaunchPending 表示组件等待所在的启动阶段
+	LaunchPending = "pending"
+	// LaunchStarting 表示组件进程已启动，等待其注册
+	LaunchStarting = "starting"
+	// LaunchRunning 表示组件已注册
+	LaunchRunning = "running"
+	// LaunchFailed 表示组件进程启动失败
+	LaunchFailed = "failed"
+	// LaunchBlocked 表示组件的依赖不存在、存在循环依赖或依赖未能就绪，组件不会启动
+	LaunchBlocked = "blocked"
+	// LaunchError 表示组件已注册但拒绝了配置中的 settings
+	LaunchError = "error"
+)
+
+// LaunchStatus 是组件的启动状态
+type LaunchStatus struct {
+	State string `json:"state"`
+	// Phase 为组件所在的启动阶段，从 1 开始，无法确定阶段时为 0
+	Phase int `json:"phase,omitempty"`
+	// BlockedBy 为导致组件无法启动的依赖
+	BlockedBy []string `json:"blocked_by,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// dependencyPhases 按 depends_on 将组件划分为启动阶段: 第一阶段的组件没有依赖，
+// 之后每个阶段的组件只依赖之前阶段的组件，阶段内保持配置顺序。
+// 依赖不存在、处于循环依赖中或依赖这类组件的组件不属于任何阶段，其原因在 blocked 中返回
+func dependencyPhases(configs []*ComponentConfig) (phases [][]*ComponentConfig, blocked map[string]LaunchStatus) {
+	known := make(map[string]bool, len(configs))
+	for _, c := range configs {
+		known[c.Name] = true
+	}
+	blocked = map[string]LaunchStatus{}
+	for _, c := range configs {
+		var missing []string
+		for _, dep := range c.DependsOn {
+			if !known[dep] {
+				missing = append(missing, dep)
+			}
+		}
+		if len(missing) > 0 {
+			blocked[c.Name] = LaunchStatus{State: LaunchBlocked, BlockedBy: missing,
+				Error: fmt.Sprintf("依赖的组件不存在: %s", strings.Join(missing, ", "))}
+		}
+	}
+
+	phase := map[string]int{}
+	remaining := slices.DeleteFunc(slices.Clone(configs), func(c *ComponentConfig) bool {
+		_, ok := blocked[c.Name]
+		return ok
+	})
+	for len(remaining) > 0 {
+		var current []*ComponentConfig
+		for _, c := range remaining {
+			if !slices.ContainsFunc(c.DependsOn, func(dep string) bool {
+				_, ok := phase[dep]
+				return !ok
+			}) {
+				current = append(current, c)
+			}
+		}
+		if len(current) == 0 {
+			break
+		}
+		for _, c := range current {
+			phase[c.Name] = len(phases) + 1
+		}
+		phases = append(phases, current)
+		remaining = slices.DeleteFunc(remaining, func(c *ComponentConfig) bool {
+			_, ok := phase[c.Name]
+			return ok
+		})
+	}
+
+	// 剩余的组件处于循环依赖中，或 (间接) 依赖无法启动的组件
+	byName := map[string]*ComponentConfig{}
+	for _, c := range remaining {
+		byName[c.Name] = c
+	}
+	for _, c := range remaining {
+		var waiting []string
+		for _, dep := range c.DependsOn {
+			if _, ok := phase[dep]; !ok {
+				waiting = append(waiting, dep)
+			}
+		}
+		status := LaunchStatus{State: LaunchBlocked, BlockedBy: waiting, Error: "依赖的组件无法启动"}
+		if cycle := findCycle(c.Name, byName); cycle != nil {
+			status.Error = "存在循环依赖: " + strings.Join(cycle, " -> ")
+		}
+		blocked[c.Name] = status
+	}
+	return phases, blocked
+}
+
+// findCycle 返回从 start 出发并回到 start 的依赖路径，start 不在循环中时返回 nil
+func findCycle(start string, byName map[string]*ComponentConfig) []string {
+	visited := map[string]bool{}
+	var path []string
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		c, ok := byName[name]
+		if !ok || visited[name] {
+			return false
+		}
+		visited[name] = true
+		path = append(path, name)
+		for _, dep := range c.DependsOn {
+			if dep == start || visit(dep) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if visit(start) {
+		return append(path, start)
+	}
+	return nil
+}
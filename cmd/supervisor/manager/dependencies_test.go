@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDependencyPhases(t *testing.T) {
+	configs := []*ComponentConfig{
+		{Name: "printer", DependsOn: []string{"spooler", "license"}},
+		{Name: "license"},
+		{Name: "spooler", DependsOn: []string{"license"}},
+		{Name: "scanner"},
+		{Name: "kiosk", DependsOn: []string{"printer", "scanner"}},
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+		{Name: "c", DependsOn: []string{"a"}},
+		{Name: "self", DependsOn: []string{"self"}},
+		{Name: "orphan", DependsOn: []string{"missing"}},
+		{Name: "d", DependsOn: []string{"orphan"}},
+	}
+	phases, blocked := dependencyPhases(configs)
+
+	var got [][]string
+	for _, phase := range phases {
+		got = append(got, componentNames(phase))
+	}
+	want := [][]string{{"license", "scanner"}, {"spooler"}, {"printer"}, {"kiosk"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("启动阶段 = %v, 期望 %v", got, want)
+	}
+
+	if len(blocked) != 6 {
+		t.Fatalf("应有 6 个组件无法启动: %v", blocked)
+	}
+	for name, cycle := range map[string]string{"a": "a -> b -> a", "b": "b -> a -> b", "self": "self -> self"} {
+		if !strings.Contains(blocked[name].Error, cycle) {
+			t.Errorf("组件 %s 应报告循环依赖 %s: %+v", name, cycle, blocked[name])
+		}
+	}
+	if s := blocked["c"]; !reflect.DeepEqual(s.BlockedBy, []string{"a"}) || strings.Contains(s.Error, "循环") {
+		t.Errorf("依赖循环中组件的组件应被阻塞: %+v", s)
+	}
+	if s := blocked["orphan"]; !reflect.DeepEqual(s.BlockedBy, []string{"missing"}) || !strings.Contains(s.Error, "不存在") {
+		t.Errorf("依赖不存在的组件应被阻塞: %+v", s)
+	}
+	if s := blocked["d"]; s.State != LaunchBlocked || !reflect.DeepEqual(s.BlockedBy, []string{"orphan"}) {
+		t.Errorf("间接依赖不存在的组件应被阻塞: %+v", s)
+	}
+}
@@ -0,0 +1,119 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RLimits 是组件进程的资源上限 (setrlimit)，0 表示不限制。仅在 Linux 上支持，在进程启动后立即设置
+type RLimits struct {
+	// MemoryMB 为虚拟内存上限 (RLIMIT_AS，MB)
+	MemoryMB int64 `json:"memory_mb"`
+	// OpenFiles 为打开的文件数上限 (RLIMIT_NOFILE)
+	OpenFiles int64 `json:"open_files"`
+	// CPUSeconds 为 CPU 时间上限 (RLIMIT_CPU，秒)，超过后进程被终止
+	CPUSeconds int64 `json:"cpu_seconds"`
+}
+
+// Cgroup 是组件所在 cgroup (v2) 的限制，0 表示不限制。组件在 Supervisor 配置的 components.cgroup_dir 中
+// 以组件命名的子 cgroup 中启动，限制同样作用于组件创建的所有子进程。仅在 Linux 上支持
+type Cgroup struct {
+	// MemoryMaxMB 为内存上限 (memory.max，MB)
+	MemoryMaxMB int64 `json:"memory_max_mb"`
+	// CPUMaxPercent 为 CPU 使用上限 (cpu.max)，100 表示一个 CPU
+	CPUMaxPercent int64 `json:"cpu_max_percent"`
+	// PidsMax 为进程与线程数上限 (pids.max)
+	PidsMax int64 `json:"pids_max"`
+}
+
+// ResourceUsage 是组件的资源使用情况。组件在 cgroup 中运行时为整个 cgroup (包括组件创建的子进程) 的用量，
+// 否则为组件进程自身的用量
+type ResourceUsage struct {
+	// RSSBytes 为常驻内存 (字节)，在 cgroup 中运行时为 memory.current
+	RSSBytes int64 `json:"rss_bytes"`
+	// CPUSeconds 为累计使用的 CPU 时间 (用户态与内核态，秒)
+	CPUSeconds float64 `json:"cpu_seconds"`
+}
+
+// cpuMaxPeriod 为 cpu.max 的周期 (微秒)
+const cpuMaxPeriod = 100000
+
+// validateLimits 检查资源限制的取值
+func (c *ComponentConfig) validateLimits() error {
+	if c.RLimits == nil && c.Cgroup == nil {
+		return nil
+	}
+	if !limitsSupported {
+		return errors.New("rlimits 与 cgroup 仅在 Linux 上支持")
+	}
+	if r := c.RLimits; r != nil && (r.MemoryMB < 0 || r.OpenFiles < 0 || r.CPUSeconds < 0) {
+		return errors.New("rlimits 的取值不能为负数")
+	}
+	if g := c.Cgroup; g != nil && (g.MemoryMaxMB < 0 || g.CPUMaxPercent < 0 || g.PidsMax < 0) {
+		return errors.New("cgroup 的取值不能为负数")
+	}
+	return nil
+}
+
+// settings 返回父 cgroup 需要启用的控制器与写入子 cgroup 的限制
+func (g *Cgroup) settings() (controllers []string, files [][2]string) {
+	if g.MemoryMaxMB > 0 {
+		controllers = append(controllers, "+memory")
+		files = append(files, [2]string{"memory.max", strconv.FormatInt(g.MemoryMaxMB<<20, 10)})
+	}
+	if g.CPUMaxPercent > 0 {
+		controllers = append(controllers, "+cpu")
+		files = append(files, [2]string{"cpu.max", fmt.Sprintf("%d %d", g.CPUMaxPercent*cpuMaxPeriod/100, cpuMaxPeriod)})
+	}
+	if g.PidsMax > 0 {
+		controllers = append(controllers, "+pids")
+		files = append(files, [2]string{"pids.max", strconv.FormatInt(g.PidsMax, 10)})
+	}
+	return controllers, files
+}
+
+// setupCgroup 在 parent 中创建组件的子 cgroup 并写入限制，返回子 cgroup 的目录。
+// 上次运行遗留的子 cgroup 会被继续使用
+func setupCgroup(parent, name string, g *Cgroup) (string, error) {
+	controllers, files := g.settings()
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return "", fmt.Errorf("无法创建 cgroup 目录: %w", err)
+	}
+	if len(controllers) > 0 {
+		if err := os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte(strings.Join(controllers, " ")), 0o644); err != nil {
+			return "", fmt.Errorf("无法启用 cgroup 控制器 %v: %w", controllers, err)
+		}
+	}
+	dir := filepath.Join(parent, name)
+	if err := os.Mkdir(dir, 0o755); err != nil && !errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("无法创建组件的 cgroup: %w", err)
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f[0]), []byte(f[1]), 0o644); err != nil {
+			return "", fmt.Errorf("无法设置 %s: %w", f[0], err)
+		}
+	}
+	return dir, nil
+}
+
+// Usage 返回组件当前的资源使用情况，进程未启动、已退出或当前平台不支持时返回 nil。
+// cgroup 未启用 memory 控制器时使用组件进程自身的用量
+func (c *ComponentInfo) Usage() *ResourceUsage {
+	if c.Cmd == nil || c.Cmd.Process == nil || c.processExited() {
+		return nil
+	}
+	if c.Cgroup != "" {
+		if usage, err := cgroupUsage(c.Cgroup); err == nil {
+			return usage
+		}
+	}
+	usage, err := processUsage(c.Cmd.Process.Pid)
+	if err != nil {
+		return nil
+	}
+	return usage
+}
@@ -0,0 +1,102 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const limitsSupported = true
+
+// userHZ 为 /proc 中 CPU 时间的单位 (每秒的时钟周期数)，Linux 对用户空间固定为 100
+const userHZ = 100
+
+// placeInCgroup 使组件进程直接在 dir 对应的 cgroup 中启动，返回进程启动后关闭目录的函数
+func placeInCgroup(cmd *exec.Cmd, dir string) (func(), error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开组件的 cgroup: %w", err)
+	}
+	attr := sysProcAttr(cmd)
+	attr.UseCgroupFD = true
+	attr.CgroupFD = int(f.Fd())
+	return func() { f.Close() }, nil
+}
+
+// applyRLimits 设置已启动的组件进程的资源上限
+func applyRLimits(pid int, r *RLimits) error {
+	for _, l := range []struct {
+		name     string
+		resource int
+		value    int64
+	}{
+		{"memory_mb", unix.RLIMIT_AS, r.MemoryMB << 20},
+		{"open_files", unix.RLIMIT_NOFILE, r.OpenFiles},
+		{"cpu_seconds", unix.RLIMIT_CPU, r.CPUSeconds},
+	} {
+		if l.value <= 0 {
+			continue
+		}
+		limit := unix.Rlimit{Cur: uint64(l.value), Max: uint64(l.value)}
+		if err := unix.Prlimit(pid, l.resource, &limit, nil); err != nil {
+			return fmt.Errorf("无法设置 rlimits.%s: %w", l.name, err)
+		}
+	}
+	return nil
+}
+
+// processUsage 从 /proc/<pid>/stat 读取进程的常驻内存与 CPU 时间
+func processUsage(pid int) (*ResourceUsage, error) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return nil, err
+	}
+	// 进程名可能包含空格与括号，从最后一个 ")" 之后按空格分割，第一个字段为 state (第 3 项)
+	i := strings.LastIndexByte(string(data), ')')
+	if i < 0 {
+		return nil, errors.New("无法解析 /proc/<pid>/stat")
+	}
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 22 {
+		return nil, errors.New("无法解析 /proc/<pid>/stat")
+	}
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+	rss, _ := strconv.ParseInt(fields[21], 10, 64)
+	return &ResourceUsage{
+		RSSBytes:   rss * int64(os.Getpagesize()),
+		CPUSeconds: float64(utime+stime) / userHZ,
+	}, nil
+}
+
+// cgroupUsage 从 cgroup 的 memory.current 与 cpu.stat 读取组件及其所有子进程的内存与 CPU 用量
+func cgroupUsage(dir string) (*ResourceUsage, error) {
+	memory, err := os.ReadFile(filepath.Join(dir, "memory.current"))
+	if err != nil {
+		return nil, err
+	}
+	current, err := strconv.ParseInt(strings.TrimSpace(string(memory)), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 memory.current: %w", err)
+	}
+	stat, err := os.ReadFile(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(stat), "\n") {
+		if value, ok := strings.CutPrefix(line, "usage_usec "); ok {
+			usec, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("无法解析 cpu.stat: %w", err)
+			}
+			return &ResourceUsage{RSSBytes: current, CPUSeconds: float64(usec) / 1e6}, nil
+		}
+	}
+	return nil, errors.New("cpu.stat 中没有 usage_usec")
+}
@@ -0,0 +1,127 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"cse-go/cmd/supervisor/certs"
+)
+
+// processAlive 判断进程是否仍在运行 (僵尸进程视为已退出)
+func processAlive(pid int) bool {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+	i := strings.LastIndexByte(string(data), ')')
+	return i >= 0 && !strings.HasPrefix(string(data[i+1:]), " Z")
+}
+
+func TestStartComponentLimits(t *testing.T) {
+	pki, err := certs.NewInternal(filepath.Join(t.TempDir(), "run"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewComponentManager(pki)
+	spawn := filepath.Join(t.TempDir(), "child.pid")
+	t.Setenv("CSE_TEST_HELPER_PROCESS", "1")
+	t.Setenv("CSE_TEST_SPAWN", spawn)
+	t.Setenv("CSE_TEST_HANG", "1")
+
+	exe, _ := os.Executable()
+	config := &ComponentConfig{
+		Name:       "helper",
+		Cmd:        exe,
+		CmdArgs:    []string{"-test.run=TestHelperProcess", "--"},
+		InheritEnv: true,
+		RLimits:    &RLimits{OpenFiles: 64, CPUSeconds: 600},
+	}
+	if err := config.validate(filepath.Dir(exe)); err != nil {
+		t.Fatal(err)
+	}
+	m.launch["helper"] = &LaunchStatus{State: LaunchPending, Phase: 1}
+	if !m.startComponent(config, "localhost:0") {
+		t.Fatal("组件应启动成功")
+	}
+	comp := m.Components["helper"]
+	pid := comp.Cmd.Process.Pid
+
+	if pgid, _ := syscall.Getpgid(pid); pgid != pid {
+		t.Errorf("组件应在自己的进程组中运行: pgid %d, pid %d", pgid, pid)
+	}
+	limits, _ := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/limits")
+	for _, want := range []string{"Max open files            64                   64", "Max cpu time              600                  600"} {
+		if !strings.Contains(string(limits), want) {
+			t.Errorf("资源上限未生效，缺少 %q:\n%s", want, limits)
+		}
+	}
+	var child int
+	for deadline := time.Now().Add(5 * time.Second); child == 0 && time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		data, _ := os.ReadFile(spawn)
+		child, _ = strconv.Atoi(string(data))
+	}
+	if child == 0 {
+		t.Fatal("组件未启动子进程")
+	}
+	// 组件已写入子进程的 PID，说明其已完成 exec 并在运行
+	if usage := comp.Usage(); usage == nil || usage.RSSBytes <= 0 {
+		t.Errorf("应返回组件进程的资源使用情况: %+v", usage)
+	}
+
+	// 未注册的组件被强制终止时，其创建的子进程一起被终止
+	if err := shutdownComponent("helper", *comp, time.Second); err == nil {
+		t.Error("未注册的组件应被强制终止")
+	}
+	for deadline := time.Now().Add(5 * time.Second); processAlive(child) && time.Now().Before(deadline); {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if processAlive(child) {
+		syscall.Kill(child, syscall.SIGKILL)
+		t.Error("组件的子进程应与组件一起被终止")
+	}
+	if comp.Usage() != nil {
+		t.Error("已退出的组件不应返回资源使用情况")
+	}
+}
+
+func TestSetupCgroup(t *testing.T) {
+	parent := filepath.Join(t.TempDir(), "cse")
+	dir, err := setupCgroup(parent, "printer", &Cgroup{MemoryMaxMB: 256, CPUMaxPercent: 50, PidsMax: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != filepath.Join(parent, "printer") {
+		t.Errorf("组件的 cgroup 目录为 %s", dir)
+	}
+	for file, want := range map[string]string{
+		filepath.Join(parent, "cgroup.subtree_control"): "+memory +cpu +pids",
+		filepath.Join(dir, "memory.max"):                "268435456",
+		filepath.Join(dir, "cpu.max"):                   "50000 100000",
+		filepath.Join(dir, "pids.max"):                  "64",
+	} {
+		if data, _ := os.ReadFile(file); string(data) != want {
+			t.Errorf("%s 的内容为 %q，期望 %q", file, data, want)
+		}
+	}
+
+	// 组件的资源使用情况从 cgroup 读取，包括其所有子进程
+	os.WriteFile(filepath.Join(dir, "memory.current"), []byte("1048576\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte("usage_usec 2500000\nuser_usec 2000000\n"), 0o644)
+	if usage, err := cgroupUsage(dir); err != nil || usage.RSSBytes != 1<<20 || usage.CPUSeconds != 2.5 {
+		t.Errorf("cgroup 的资源使用情况不正确: %+v (%v)", usage, err)
+	}
+
+	// 只放置进程、不限制资源时不启用控制器
+	parent = filepath.Join(t.TempDir(), "cse")
+	if _, err := setupCgroup(parent, "printer", &Cgroup{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(parent, "cgroup.subtree_control")); err == nil {
+		t.Error("没有限制时不应写入 cgroup.subtree_control")
+	}
+}
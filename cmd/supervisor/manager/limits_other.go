@@ -0,0 +1,26 @@
+//go:build !linux
+
+package manager
+
+import (
+	"errors"
+	"os/exec"
+)
+
+const limitsSupported = false
+
+func placeInCgroup(cmd *exec.Cmd, dir string) (func(), error) {
+	return nil, errors.ErrUnsupported
+}
+
+func applyRLimits(pid int, r *RLimits) error {
+	return errors.ErrUnsupported
+}
+
+func processUsage(pid int) (*ResourceUsage, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func cgroupUsage(dir string) (*ResourceUsage, error) {
+	return nil, errors.ErrUnsupported
+}
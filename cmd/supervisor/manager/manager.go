@@ -3,19 +3,28 @@ package manager
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"cse-go/cmd/supervisor/certs"
+	"cse-go/cmd/supervisor/logs"
+	"cse-go/cmd/supervisor/secrets"
+	"cse-go/internal/commandbus"
+	"cse-go/internal/tracing"
+	"cse-go/internal/transport"
 	pb "cse-go/pkg/api/v1"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials"
 )
 
 // ComponentConfig 定义了组件配置文件的结构。
@@ -25,6 +34,37 @@ type ComponentConfig struct {
 	Description string   `json:"description"`
 	Cmd         string   `json:"cmd"`
 	CmdArgs     []string `json:"cmd_args"`
+	// Required 为 false 时组件未就绪不影响 Supervisor 的就绪状态 (/readyz)，默认为 true
+	Required bool `json:"required"`
+	// DependsOn 为必须先注册并处于运行状态的组件，关闭时按相反的顺序进行
+	DependsOn []string `json:"depends_on"`
+	// Env 为组件进程额外的环境变量，值中的 ${VAR} 引用 Supervisor 的环境变量；
+	// InheritEnv 为 false 时组件进程只有 Env 中的环境变量，默认为 true
+	Env        map[string]string `json:"env"`
+	InheritEnv bool              `json:"inherit_env"`
+	// WorkingDir 为组件进程的工作目录，相对路径相对于 Supervisor 可执行文件所在目录，默认继承 Supervisor 的工作目录
+	WorkingDir string `json:"working_dir"`
+	// User 与 Group 为组件进程的用户与组 (名称或数字 ID)，仅在 Unix 上以 root 运行时支持切换
+	User  string `json:"user"`
+	Group string `json:"group"`
+	// StopSignal 为关闭请求无效或组件未注册时向组件的进程组发送的信号 (如 SIGTERM)，之后仍未退出则强制终止；
+	// 未配置时直接强制终止。StopTimeoutSeconds 为每一步等待退出的时间 (秒)，默认使用 Supervisor 的配置
+	StopSignal         string `json:"stop_signal"`
+	StopTimeoutSeconds int    `json:"stop_timeout_seconds"`
+	// RLimits 与 Cgroup 为组件进程的资源限制，仅在 Linux 上支持
+	RLimits *RLimits `json:"rlimits"`
+	Cgroup  *Cgroup  `json:"cgroup"`
+	// Settings 为组件自定义的设置 (JSON 对象)，组件注册后以及重新加载配置后通过 Configure 推送给组件
+	Settings json.RawMessage `json:"settings,omitempty"`
+
+	// file 为配置文件的路径
+	file string
+	// 以下为 validate 解析的进程设置
+	path       string
+	dir        string
+	env        []string
+	cred       *credential
+	stopSignal os.Signal
 }
 
 // ComponentInfo 存储了一个已注册组件的完整信息。
@@ -34,28 +74,251 @@ type ComponentInfo struct {
 	Client   pb.ComponentServiceClient
 	Cmd      *exec.Cmd
 	Conn     *grpc.ClientConn // gRPC connection to the component
+	// Restarts 为组件重新注册的次数 (首次注册不计入)
+	Restarts int
+	// Cgroup 为组件所在的 cgroup 目录，未配置 cgroup 时为空
+	Cgroup string
+	// exited 在组件进程退出并被回收后关闭，见 watchExit
+	exited chan struct{}
 }
 
 // ComponentManager 负责管理所有组件的生命周期。
 type ComponentManager struct {
 	Components map[string]*ComponentInfo
 	lock       sync.RWMutex
+	// pki 为每个组件签发 mTLS 证书
+	pki *certs.Internal
+	// socketDir 不为空时组件在该目录中的 Unix 域套接字上提供服务
+	socketDir string
+	// logging 为组件输出的捕获配置，logs 为已打开的组件日志文件
+	logging *LogOptions
+	logs    map[string]*logs.File
+	// traceDir 不为空时组件将 span 写入该目录中以组件命名的文件
+	traceDir string
+	// cgroupDir 为配置了 cgroup 的组件的父 cgroup 目录
+	cgroupDir string
+	// secrets 用于解析 settings 中的 secret 引用并隐藏组件输出中的 secret，未配置主密钥时为 nil
+	secrets *secrets.Store
+	// configured 为配置目录中的所有组件，包括启动失败的组件；configDir 为配置目录，重新加载 settings 时再次读取
+	configured []*ComponentConfig
+	configDir  string
+	// launch 为各组件的启动状态，started 为已启动的组件 (按启动顺序)
+	launch  map[string]*LaunchStatus
+	started []string
+	// readyTimeout 为等待被依赖的组件就绪的时间
+	readyTimeout time.Duration
+	// stopped 在开始关闭组件后为 true，此后不再启动新的组件；cancelLaunch 中止对依赖的等待
+	stopped      bool
+	launchCtx    context.Context
+	cancelLaunch context.CancelFunc
+}
+
+// defaultReadyTimeout 为默认等待被依赖的组件就绪的时间
+const defaultReadyTimeout = 30 * time.Second
+
+// readyPollInterval 为检查被依赖的组件是否就绪的间隔
+const readyPollInterval = 200 * time.Millisecond
+
+// LogOptions 定义了组件输出的捕获方式
+type LogOptions struct {
+	// Dir 为组件日志文件所在目录
+	Dir      string
+	Rotation logs.Options
+	// Mirror 不为 nil 时组件输出同时带前缀写入 Mirror
+	Mirror io.Writer
+	// Levels 为各组件的日志级别，通过 --log-level 参数传递
+	Levels map[string]string
 }
 
 // NewComponentManager 创建一个新的组件管理器。
-func NewComponentManager() *ComponentManager {
+func NewComponentManager(pki *certs.Internal) *ComponentManager {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &ComponentManager{
-		Components: make(map[string]*ComponentInfo),
+		Components:   make(map[string]*ComponentInfo),
+		pki:          pki,
+		logs:         make(map[string]*logs.File),
+		launch:       make(map[string]*LaunchStatus),
+		readyTimeout: defaultReadyTimeout,
+		launchCtx:    ctx,
+		cancelLaunch: cancel,
 	}
 }
 
-// LaunchComponents 扫描配置目录，并启动所有组件进程。
+// SetReadyTimeout 设置启动时等待被依赖的组件注册并处于运行状态的时间，超时后依赖它的组件不会启动
+func (m *ComponentManager) SetReadyTimeout(d time.Duration) {
+	m.readyTimeout = d
+}
+
+// SetLogging 使之后启动的组件的标准输出与标准错误写入各自的日志文件。
+// 未设置时组件输出直接写入 Supervisor 的标准输出与标准错误
+func (m *ComponentManager) SetLogging(opts LogOptions) {
+	m.logging = &opts
+}
+
+// ComponentLog 返回组件的日志文件
+func (m *ComponentManager) ComponentLog(name string) (*logs.File, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	f, ok := m.logs[name]
+	return f, ok
+}
+
+// setupOutput 设置组件进程的输出，组件重新启动时继续写入同一个日志文件
+func (m *ComponentManager) setupOutput(cmd *exec.Cmd, name string) error {
+	if m.logging == nil {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return nil
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	f, ok := m.logs[name]
+	if !ok {
+		opts := m.logging.Rotation
+		if m.secrets != nil {
+			opts.Redact = m.secrets.Redact
+		}
+		var err error
+		if f, err = logs.Open(m.logging.Dir, name, opts); err != nil {
+			return err
+		}
+		m.logs[name] = f
+	}
+	cmd.Stdout = f.StreamWriter("stdout", m.logging.Mirror)
+	cmd.Stderr = f.StreamWriter("stderr", m.logging.Mirror)
+	return nil
+}
+
+// SetSecrets 设置解析 settings 中 secret 引用的存储，组件输出中出现的 secret 值在写入日志前被替换
+func (m *ComponentManager) SetSecrets(store *secrets.Store) {
+	m.secrets = store
+}
+
+// SetTracing 使之后启动的组件将 span 写入 dir/<组件名>.jsonl
+func (m *ComponentManager) SetTracing(dir string) {
+	m.traceDir = absPath(dir)
+}
+
+// SetCgroupDir 设置配置了 cgroup 的组件的父 cgroup 目录 (cgroup v2)，组件在其中以组件命名的子 cgroup 中运行
+func (m *ComponentManager) SetCgroupDir(dir string) {
+	m.cgroupDir = dir
+}
+
+// UseUnixSockets 使之后启动的组件监听 dir 中以组件命名的 Unix 域套接字，而不是 TCP 端口
+func (m *ComponentManager) UseUnixSockets(dir string) {
+	m.socketDir = absPath(dir)
+}
+
+// absPath 返回绝对路径，使配置了 working_dir 的组件也能找到 Supervisor 传递的文件
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// LaunchComponents 扫描配置目录，按依赖关系分阶段启动所有组件进程。
+// 每个阶段中被其他组件依赖的组件注册并处于运行状态后才开始下一阶段；
+// 依赖无法满足的组件标记为 blocked，不会启动
 func (m *ComponentManager) LaunchComponents(configDir, discoveryAddr string) {
-	files, err := os.ReadDir(configDir)
+	configs, err := readConfigs(configDir)
 	if err != nil {
-		log.Fatalf("无法读取配置目录 '%s': %v", configDir, err)
+		slog.Error("无法读取组件配置目录", "dir", configDir, "error", err)
+		os.Exit(1)
 	}
+	// 组件可执行文件与工作目录的相对路径相对于主程序所在目录
+	exePath, err := os.Executable()
+	if err != nil {
+		slog.Error("无法获取主程序路径", "error", err)
+		os.Exit(1)
+	}
+	exeDir := filepath.Dir(exePath)
 
+	invalid := map[string]error{}
+	for _, config := range configs {
+		// 配置无效的组件仍然列出并标记为启动失败，依赖它的组件不会启动
+		if err := m.validateConfig(config, exeDir); err != nil {
+			slog.Error("组件配置无效，组件不会启动", "file", config.file, "component", config.Name, "error", err)
+			invalid[config.Name] = err
+		}
+	}
+
+	phases, blocked := dependencyPhases(configs)
+	dependedOn := map[string]bool{}
+	m.lock.Lock()
+	m.configDir = configDir
+	m.configured = append(m.configured, configs...)
+	for i, phase := range phases {
+		for _, config := range phase {
+			m.launch[config.Name] = &LaunchStatus{State: LaunchPending, Phase: i + 1}
+			if err := invalid[config.Name]; err != nil {
+				m.launch[config.Name] = &LaunchStatus{State: LaunchFailed, Phase: i + 1, Error: err.Error()}
+			}
+			for _, dep := range config.DependsOn {
+				dependedOn[dep] = true
+			}
+		}
+	}
+	for name, status := range blocked {
+		m.launch[name] = &status
+		slog.Error("组件无法启动", "component", name, "blocked_by", status.BlockedBy, "reason", status.Error)
+	}
+	m.lock.Unlock()
+
+	ready := map[string]bool{}
+	for i, phase := range phases {
+		slog.Info("开始启动阶段", "phase", i+1, "components", componentNames(phase))
+		var started []string
+		for _, config := range phase {
+			if invalid[config.Name] != nil {
+				continue
+			}
+			var waiting []string
+			for _, dep := range config.DependsOn {
+				if !ready[dep] {
+					waiting = append(waiting, dep)
+				}
+			}
+			if len(waiting) > 0 {
+				slog.Error("依赖的组件未就绪，组件不会启动", "component", config.Name, "blocked_by", waiting)
+				m.setLaunchStatus(config.Name, LaunchStatus{State: LaunchBlocked, Phase: i + 1, BlockedBy: waiting, Error: "依赖的组件未就绪"})
+				continue
+			}
+			if m.startComponent(config, discoveryAddr) {
+				started = append(started, config.Name)
+			}
+		}
+
+		// 等待本阶段中被之后阶段依赖的组件就绪
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		for _, name := range started {
+			if !dependedOn[name] {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := m.waitReady(name); err != nil {
+					slog.Warn("被依赖的组件未就绪", "component", name, "error", err)
+					return
+				}
+				mu.Lock()
+				ready[name] = true
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// readConfigs 读取配置目录中的所有组件配置文件，无法读取或解析的文件被跳过
+func readConfigs(configDir string) ([]*ComponentConfig, error) {
+	files, err := os.ReadDir(configDir)
+	if err != nil {
+		return nil, err
+	}
+	var configs []*ComponentConfig
 	for _, file := range files {
 		if filepath.Ext(file.Name()) != ".json" {
 			continue
@@ -64,139 +327,384 @@ func (m *ComponentManager) LaunchComponents(configDir, discoveryAddr string) {
 		configPath := filepath.Join(configDir, file.Name())
 		configData, err := os.ReadFile(configPath)
 		if err != nil {
-			log.Printf("错误: 无法读取配置文件 %s: %v", configPath, err)
+			slog.Error("无法读取组件配置文件", "file", configPath, "error", err)
 			continue
 		}
 
-		var config ComponentConfig
+		config := ComponentConfig{Required: true, InheritEnv: true, file: configPath}
 		if unmarshalErr := json.Unmarshal(configData, &config); unmarshalErr != nil {
-			log.Printf("错误: 解析配置文件 %s 失败: %v", configPath, err)
+			slog.Error("解析组件配置文件失败", "file", configPath, "error", unmarshalErr)
+			continue
+		}
+		if config.Name == "" || config.Name != filepath.Base(config.Name) {
+			slog.Error("组件名称无效", "file", configPath, "name", config.Name)
 			continue
 		}
+		if string(config.Settings) == "null" {
+			config.Settings = nil
+		}
+		configs = append(configs, &config)
+	}
+	return configs, nil
+}
+
+// validateConfig 检查组件配置以及其使用的 Supervisor 配置
+func (m *ComponentManager) validateConfig(config *ComponentConfig, exeDir string) error {
+	if err := config.validate(exeDir); err != nil {
+		return err
+	}
+	if config.Cgroup != nil && m.cgroupDir == "" {
+		return errors.New("使用 cgroup 需要在 Supervisor 配置中设置 components.cgroup_dir")
+	}
+	return nil
+}
+
+// startComponent 为组件签发证书并启动进程，返回是否启动成功
+func (m *ComponentManager) startComponent(config *ComponentConfig, discoveryAddr string) bool {
+	fail := func(msg string, err error) bool {
+		slog.Error(msg, "component", config.Name, "error", err)
+		m.setLaunchStatus(config.Name, LaunchStatus{State: LaunchFailed, Phase: m.launchPhase(config.Name), Error: err.Error()})
+		return false
+	}
+
+	// 以其他用户运行的组件需要能够读取证书并创建套接字
+	if config.cred != nil {
+		if err := m.prepareForUser(config, discoveryAddr); err != nil {
+			return fail("无法为组件的运行用户设置文件权限", err)
+		}
+	}
 
-		// 将发现服务的地址作为命令行参数传递给组件
-		args := append(config.CmdArgs, "--discovery-addr="+discoveryAddr, "--component-name="+config.Name)
+	// 为组件签发 mTLS 证书，组件只能以证书中的名称注册
+	tlsFiles, err := m.pki.IssueComponent(config.Name)
+	if err != nil {
+		return fail("无法为组件签发证书", err)
+	}
 
-		// 获取主程序所在目录，以正确地定位组件可执行文件
-		exePath, err := os.Executable()
+	// 将发现服务的地址与证书文件作为命令行参数传递给组件
+	args := append(config.CmdArgs, "--discovery-addr="+discoveryAddr, "--component-name="+config.Name)
+	args = append(args, tlsFiles.Args()...)
+	if m.socketDir != "" {
+		args = append(args, "--listen="+transport.UnixAddress(m.socketPath(config)))
+	}
+	if m.logging != nil && m.logging.Levels[config.Name] != "" {
+		args = append(args, "--"+commandbus.FlagLogLevel+"="+m.logging.Levels[config.Name])
+	}
+	if m.traceDir != "" {
+		args = append(args, "--"+tracing.FlagTraceFile+"="+filepath.Join(m.traceDir, config.Name+".jsonl"))
+	}
+
+	cmd := exec.Command(config.path, args...)
+	cmd.Dir = config.dir
+	cmd.Env = config.env
+	setCredential(cmd, config.cred)
+	setProcessGroup(cmd)
+	if err := m.setupOutput(cmd, config.Name); err != nil {
+		return fail("无法打开组件日志文件", err)
+	}
+	var cgroup string
+	if config.Cgroup != nil {
+		if cgroup, err = setupCgroup(m.cgroupDir, config.Name, config.Cgroup); err != nil {
+			return fail("无法为组件创建 cgroup", err)
+		}
+		closeCgroup, err := placeInCgroup(cmd, cgroup)
 		if err != nil {
-			log.Printf("错误: 无法获取主程序路径: %v", err)
-			continue
+			return fail("无法为组件创建 cgroup", err)
 		}
-		exeDir := filepath.Dir(exePath)
+		defer closeCgroup()
+	}
 
-		cmd := exec.Command(filepath.Join(exeDir, config.Cmd), args...)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	// 在持有锁的情况下创建占位符并启动进程，开始关闭后不再启动新的组件
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.stopped {
+		return false
+	}
+	if err := cmd.Start(); err != nil {
+		slog.Error("启动组件失败", "component", config.Name, "error", err)
+		m.launch[config.Name] = &LaunchStatus{State: LaunchFailed, Phase: m.launch[config.Name].Phase, Error: err.Error()}
+		return false
+	}
+	// 进程启动后立即设置资源上限，无法设置时不让组件继续运行
+	if config.RLimits != nil {
+		if err := applyRLimits(cmd.Process.Pid, config.RLimits); err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			slog.Error("设置组件资源上限失败", "component", config.Name, "error", err)
+			m.launch[config.Name] = &LaunchStatus{State: LaunchFailed, Phase: m.launch[config.Name].Phase, Error: err.Error()}
+			return false
+		}
+	}
+	info := &ComponentInfo{
+		Config: config,
+		Cmd:    cmd,
+		Cgroup: cgroup,
+	}
+	info.watchExit()
+	m.Components[config.Name] = info
+	m.started = append(m.started, config.Name)
+	m.launch[config.Name].State = LaunchStarting
+	slog.Info("组件进程已启动，等待其主动注册", "component", config.Name, "pid", cmd.Process.Pid)
+	return true
+}
 
-		// 在组件启动前，先在 map 中创建一个占位符
-		m.lock.Lock()
-		m.Components[config.Name] = &ComponentInfo{
-			Config: &config,
-			Cmd:    cmd,
+// waitReady 等待组件注册并报告运行状态
+func (m *ComponentManager) waitReady(name string) error {
+	ctx, cancel := context.WithTimeout(m.launchCtx, m.readyTimeout)
+	defer cancel()
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+	for {
+		m.lock.RLock()
+		var client pb.ComponentServiceClient
+		if comp, ok := m.Components[name]; ok {
+			client = comp.Client
 		}
-		m.lock.Unlock()
+		var rejected string
+		if status, ok := m.launch[name]; ok && status.State == LaunchError {
+			rejected = status.Error
+		}
+		m.lock.RUnlock()
 
-		if err := cmd.Start(); err != nil {
-			log.Printf("错误: 启动组件 '%s' 失败: %v", config.Name, err)
-			m.lock.Lock()
-			delete(m.Components, config.Name) // 启动失败则删除占位符
-			m.lock.Unlock()
-			continue
+		if rejected != "" {
+			return fmt.Errorf("组件拒绝了 settings: %s", rejected)
+		}
+
+		if client != nil {
+			resp, err := client.GetStatus(ctx, &pb.GetStatusRequest{})
+			if err == nil && resp.CurrentState == pb.ComponentState_RUNNING {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			if client == nil {
+				return errors.New("等待组件注册超时")
+			}
+			return errors.New("等待组件进入运行状态超时")
+		case <-ticker.C:
 		}
-		log.Printf("组件 '%s' 进程已启动 (PID: %d)，等待其主动注册...", config.Name, cmd.Process.Pid)
 	}
 }
 
-// HandleRegistration 处理来自组件的注册请求。
+// setLaunchStatus 更新组件的启动状态
+func (m *ComponentManager) setLaunchStatus(name string, status LaunchStatus) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.launch[name] = &status
+}
+
+// launchPhase 返回组件所在的启动阶段
+func (m *ComponentManager) launchPhase(name string) int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if status, ok := m.launch[name]; ok {
+		return status.Phase
+	}
+	return 0
+}
+
+// LaunchStatus 返回组件的启动状态
+func (m *ComponentManager) LaunchStatus(name string) (LaunchStatus, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	status, ok := m.launch[name]
+	if !ok {
+		return LaunchStatus{}, false
+	}
+	return *status, true
+}
+
+// LaunchStatuses 返回所有组件的启动状态
+func (m *ComponentManager) LaunchStatuses() map[string]LaunchStatus {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	statuses := make(map[string]LaunchStatus, len(m.launch))
+	for name, status := range m.launch {
+		statuses[name] = *status
+	}
+	return statuses
+}
+
+func componentNames(configs []*ComponentConfig) []string {
+	names := make([]string, len(configs))
+	for i, c := range configs {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Configured 返回配置目录中的所有组件配置，包括启动失败或尚未注册的组件
+func (m *ComponentManager) Configured() []*ComponentConfig {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return slices.Clone(m.configured)
+}
+
+// HandleRegistration 处理来自组件的注册请求，注册成功后将组件配置中的 settings 推送给组件。
+// 组件拒绝 settings 时注册仍然成功，组件被标记为 error
 func (m *ComponentManager) HandleRegistration(req *pb.RegisterComponentRequest) error {
+	client, settings, err := m.register(req)
+	if err != nil {
+		return err
+	}
+	// 在锁外推送，组件应用设置期间不阻塞其他请求
+	m.applySettings(req.Name, client, settings)
+	return nil
+}
+
+// register 连接到组件并获取元数据，返回组件的客户端与配置中的 settings
+func (m *ComponentManager) register(req *pb.RegisterComponentRequest) (pb.ComponentServiceClient, json.RawMessage, error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
 	compInfo, ok := m.Components[req.Name]
 	if !ok {
-		return fmt.Errorf("收到未知的组件注册请求: %s", req.Name)
+		return nil, nil, fmt.Errorf("收到未知的组件注册请求: %s", req.Name)
+	}
+	// 开始关闭后组件信息已被复制用于关闭，不再修改
+	if m.stopped {
+		return nil, nil, fmt.Errorf("Supervisor 正在关闭，拒绝组件 '%s' 的注册", req.Name)
+	}
+	if err := transport.Validate(req.GrpcAddress); err != nil {
+		return nil, nil, err
 	}
 
-	// 连接到组件报告的地址，以获取元数据
-	conn, err := grpc.Dial(req.GrpcAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// 通过 mTLS 连接到组件报告的地址，以获取元数据
+	creds := credentials.NewTLS(m.pki.ClientConfig(req.Name))
+	conn, err := grpc.Dial(req.GrpcAddress, grpc.WithTransportCredentials(creds))
 	if err != nil {
-		return fmt.Errorf("无法连接回组件 '%s': %w", req.Name, err)
+		return nil, nil, fmt.Errorf("无法连接回组件 '%s': %w", req.Name, err)
 	}
 
 	client := pb.NewComponentServiceClient(conn)
 	metadata, err := client.GetMetadata(context.Background(), &pb.GetMetadataRequest{})
 	if err != nil {
 		conn.Close()
-		return fmt.Errorf("无法从组件 '%s' 获取元数据: %w", req.Name, err)
+		return nil, nil, fmt.Errorf("无法从组件 '%s' 获取元数据: %w", req.Name, err)
+	}
+
+	// 组件重新注册说明进程已重启，关闭旧连接
+	if compInfo.Conn != nil {
+		compInfo.Conn.Close()
+		compInfo.Restarts++
 	}
 
 	// 更新组件信息
+	if status, ok := m.launch[req.Name]; ok {
+		status.State = LaunchRunning
+	}
 	compInfo.Metadata = metadata
 	compInfo.Client = client
 	compInfo.Conn = conn
 
-	log.Printf("[Discovery Service] 组件 '%s' v%s 注册成功！", metadata.Name, metadata.Version)
-	return nil
+	slog.Info("组件注册成功", "component", metadata.Name, "version", metadata.Version, "address", req.GrpcAddress)
+	var settings json.RawMessage
+	if compInfo.Config != nil {
+		settings = compInfo.Config.Settings
+	}
+	return client, settings, nil
 }
 
-// ShutdownAllComponents 优雅地关闭所有已注册的组件。
-func (m *ComponentManager) ShutdownAllComponents() {
-	m.lock.RLock()
-	defer m.lock.RUnlock()
-
-	log.Println("正在向所有组件发送关闭信号...")
-	var wg sync.WaitGroup
+// ShutdownAllComponents 按启动顺序的逆序 (依赖其他组件的组件先关闭) 逐个关闭组件：请求已注册的组件优雅关闭并等待进程退出，
+// 超过 timeout 仍未退出或尚未注册的组件进程被强制终止。有组件被强制终止时返回错误
+func (m *ComponentManager) ShutdownAllComponents(timeout time.Duration) error {
+	// 中止尚未完成的启动，之后不再启动新的组件
+	m.cancelLaunch()
+	m.lock.Lock()
+	m.stopped = true
+	order := slices.Clone(m.started)
+	slices.Reverse(order)
+	// 在持有锁时复制组件信息，之后注册被拒绝，关闭过程中不再读取共享的组件信息
+	components := make(map[string]ComponentInfo, len(m.Components))
 	for name, comp := range m.Components {
-		if comp.Client == nil {
-			continue // 组件未成功注册
-		}
-		wg.Add(1)
-		go func(name string, comp *ComponentInfo) {
-			defer wg.Done()
-			log.Printf("正在关闭组件: %s...", name)
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			_, err := comp.Client.Shutdown(ctx, &pb.ShutdownRequest{})
-			if err != nil {
-				// 检查是否是连接关闭错误（正常的关闭流程）
-				errorMsg := err.Error()
-				if strings.Contains(errorMsg, "connection was forcibly closed") ||
-					strings.Contains(errorMsg, "transport is closing") ||
-					strings.Contains(errorMsg, "connection refused") ||
-					strings.Contains(errorMsg, "EOF") {
-					log.Printf("组件 '%s' 已正常关闭", name)
-				} else {
-					log.Printf("关闭组件 '%s' 时出错: %v，尝试强制终止", name, err)
-					if comp.Cmd.Process != nil {
-						comp.Cmd.Process.Kill()
-					}
-				}
-			} else {
-				log.Printf("组件 '%s' 收到关闭信号，等待其自行退出...", name)
-				// 等待组件自行退出，如果超时则强制终止
-				done := make(chan error, 1)
-				go func() {
-					done <- comp.Cmd.Wait()
-				}()
+		components[name] = *comp
+	}
+	m.lock.Unlock()
+
+	slog.Info("正在关闭所有组件", "order", order)
+	var errs error
+	for _, name := range order {
+		if err := shutdownComponent(name, components[name], timeout); err != nil {
+			slog.Warn("组件未能正常关闭", "component", name, "error", err)
+			errs = errors.Join(errs, fmt.Errorf("组件 '%s': %w", name, err))
+		}
+	}
+
+	m.lock.Lock()
+	for _, f := range m.logs {
+		f.Close()
+	}
+	m.lock.Unlock()
+	slog.Info("所有组件已关闭")
+	return errs
+}
+
+// shutdownComponent 关闭一个组件的进程与 gRPC 连接。组件配置了 stop_timeout_seconds 时代替 timeout
+func shutdownComponent(name string, comp ComponentInfo, timeout time.Duration) error {
+	if comp.Conn != nil {
+		defer comp.Conn.Close()
+	}
+	if comp.Cmd == nil || comp.Cmd.Process == nil {
+		return nil
+	}
+	if comp.Cgroup != "" {
+		// cgroup 中仍有进程时删除失败，下次启动时继续使用
+		defer os.Remove(comp.Cgroup)
+	}
+	var stopSignal os.Signal
+	if comp.Config != nil {
+		if comp.Config.StopTimeoutSeconds > 0 {
+			timeout = time.Duration(comp.Config.StopTimeoutSeconds) * time.Second
+		}
+		stopSignal = comp.Config.stopSignal
+	}
+	exited := comp.exited
+	// stop 发送组件配置的停止信号并等待退出，未配置信号或超时后强制终止
+	stop := func(reason string) error {
+		if stopSignal != nil {
+			slog.Info("正在向组件发送停止信号", "component", name, "signal", stopSignal)
+			if err := comp.signal(stopSignal); err == nil {
 				select {
-				case <-done:
-					log.Printf("组件 '%s' 已正常退出", name)
-				case <-time.After(3 * time.Second):
-					log.Printf("组件 '%s' 退出超时，强制终止", name)
-					if comp.Cmd.Process != nil {
-						comp.Cmd.Process.Kill()
-					}
+				case <-exited:
+					slog.Info("组件已在收到停止信号后退出", "component", name)
+					return nil
+				case <-time.After(timeout):
 				}
 			}
-			// 关闭 gRPC 连接
-			if comp.Conn != nil {
-				comp.Conn.Close()
-			}
-		}(name, comp)
+		}
+		comp.kill()
+		<-exited
+		return errors.New(reason)
 	}
-	wg.Wait()
-	log.Println("所有组件已关闭。")
+
+	if comp.Client == nil {
+		return stop("组件尚未注册，已强制终止")
+	}
+
+	slog.Info("正在关闭组件", "component", name)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if _, err := comp.Client.Shutdown(ctx, &pb.ShutdownRequest{}); err != nil && !connectionClosed(err) {
+		return stop(fmt.Sprintf("关闭请求失败，已强制终止: %v", err))
+	}
+
+	// 组件收到关闭信号后等待进行中的命令完成再退出
+	select {
+	case <-exited:
+		slog.Info("组件已正常退出", "component", name)
+		return nil
+	case <-ctx.Done():
+		return stop("组件退出超时，已强制终止")
+	}
+}
+
+// connectionClosed 判断错误是否由组件在应答前关闭连接引起，属于正常的关闭流程
+func connectionClosed(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "connection was forcibly closed") ||
+		strings.Contains(msg, "transport is closing") ||
+		strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "EOF")
 }
 
 // Lock 提供对互斥锁的写锁定
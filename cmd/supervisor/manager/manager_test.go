@@ -0,0 +1,211 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cse-go/cmd/supervisor/certs"
+	pb "cse-go/pkg/api/v1"
+
+	"google.golang.org/grpc"
+)
+
+// TestHelperProcess 是测试中启动的组件进程，标准输入关闭时退出。
+// 设置了 CSE_TEST_DUMP 时先将工作目录与环境变量写入该文件；设置了 CSE_TEST_SPAWN 时
+// 启动一个不退出的子进程并将其 PID 写入该文件；CSE_TEST_HANG 为 1 时不退出
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("CSE_TEST_HELPER_PROCESS") != "1" {
+		return
+	}
+	if path := os.Getenv("CSE_TEST_DUMP"); path != "" {
+		wd, _ := os.Getwd()
+		os.WriteFile(path, []byte(strings.Join(append([]string{wd}, os.Environ()...), "\n")), 0o644)
+	}
+	if path := os.Getenv("CSE_TEST_SPAWN"); path != "" {
+		child := exec.Command(os.Args[0], "-test.run=TestHelperProcess")
+		child.Env = append(os.Environ(), "CSE_TEST_SPAWN=", "CSE_TEST_HANG=1")
+		child.Start()
+		os.WriteFile(path, []byte(strconv.Itoa(child.Process.Pid)), 0o644)
+	}
+	if os.Getenv("CSE_TEST_HANG") == "1" {
+		time.Sleep(time.Hour)
+	}
+	io.Copy(io.Discard, os.Stdin)
+	os.Exit(0)
+}
+
+// fakeComponent 在收到关闭请求时记录顺序，并关闭进程的标准输入使其退出 (hang 为 true 时不退出)
+type fakeComponent struct {
+	pb.ComponentServiceClient
+	name  string
+	stdin io.Closer
+	hang  bool
+	order *[]string
+	mu    *sync.Mutex
+}
+
+func (f *fakeComponent) Shutdown(ctx context.Context, req *pb.ShutdownRequest, opts ...grpc.CallOption) (*pb.ShutdownResponse, error) {
+	f.mu.Lock()
+	*f.order = append(*f.order, f.name)
+	f.mu.Unlock()
+	if !f.hang && f.stdin != nil {
+		f.stdin.Close()
+	}
+	return &pb.ShutdownResponse{Acknowledged: true}, nil
+}
+
+func (f *fakeComponent) GetStatus(ctx context.Context, req *pb.GetStatusRequest, opts ...grpc.CallOption) (*pb.GetStatusResponse, error) {
+	return &pb.GetStatusResponse{CurrentState: pb.ComponentState_RUNNING}, nil
+}
+
+// startComponent 启动一个组件进程并加入管理器，registered 为 false 时模拟尚未注册的组件
+func startComponent(t *testing.T, m *ComponentManager, name string, registered, hang bool, order *[]string, mu *sync.Mutex) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess")
+	cmd.Env = append(os.Environ(), "CSE_TEST_HELPER_PROCESS=1")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	info := &ComponentInfo{Config: &ComponentConfig{Name: name}, Cmd: cmd}
+	info.watchExit()
+	if registered {
+		info.Client = &fakeComponent{name: name, stdin: stdin, hang: hang, order: order, mu: mu}
+	}
+	m.started = append(m.started, name)
+	m.Components[name] = info
+}
+
+func TestShutdownAllComponentsOrder(t *testing.T) {
+	m := NewComponentManager(nil)
+	var order []string
+	var mu sync.Mutex
+	for _, name := range []string{"a", "b", "c"} {
+		startComponent(t, m, name, true, false, &order, &mu)
+	}
+
+	if err := m.ShutdownAllComponents(5 * time.Second); err != nil {
+		t.Fatalf("组件应正常关闭: %v", err)
+	}
+	if strings.Join(order, ",") != "c,b,a" {
+		t.Errorf("组件应按启动顺序的逆序关闭，实际 %v", order)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if state := m.Components[name].Cmd.ProcessState; state == nil || !state.Success() {
+			t.Errorf("组件 %s 应正常退出: %v", name, state)
+		}
+	}
+	if err := m.HandleRegistration(&pb.RegisterComponentRequest{Name: "a"}); err == nil {
+		t.Error("开始关闭后应拒绝组件注册")
+	}
+}
+
+func TestShutdownAllComponentsForced(t *testing.T) {
+	m := NewComponentManager(nil)
+	var order []string
+	var mu sync.Mutex
+	startComponent(t, m, "hung", true, true, &order, &mu)
+	startComponent(t, m, "unregistered", false, false, &order, &mu)
+	startComponent(t, m, "ok", true, false, &order, &mu)
+	// 只有 hung 与 unregistered 使用较短的超时，正常退出的组件留出充足的时间
+	m.Components["ok"].Config.StopTimeoutSeconds = 30
+
+	start := time.Now()
+	err := m.ShutdownAllComponents(200 * time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "'hung'") || !strings.Contains(err.Error(), "'unregistered'") || strings.Contains(err.Error(), "'ok'") {
+		t.Fatalf("应报告被强制终止的组件: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("超时的组件应被及时终止，耗时 %v", elapsed)
+	}
+	for _, name := range []string{"hung", "unregistered"} {
+		if state := m.Components[name].Cmd.ProcessState; state == nil || state.Success() {
+			t.Errorf("组件 %s 应被强制终止: %v", name, state)
+		}
+	}
+}
+
+func TestLaunchComponentsInDependencyOrder(t *testing.T) {
+	pki, err := certs.NewInternal(filepath.Join(t.TempDir(), "run"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewComponentManager(pki)
+	m.SetReadyTimeout(2 * time.Second)
+	t.Setenv("CSE_TEST_HELPER_PROCESS", "1")
+
+	// 组件程序为测试程序自身，"--" 之后的参数不作为测试参数解析
+	exe, _ := os.Executable()
+	helper := func(name string, deps ...string) string {
+		config, _ := json.Marshal(ComponentConfig{Name: name, Cmd: filepath.Base(exe),
+			CmdArgs: []string{"-test.run=TestHelperProcess", "--"}, DependsOn: deps, InheritEnv: true})
+		return string(config)
+	}
+	configDir := t.TempDir()
+	for name, config := range map[string]string{
+		"1.json": helper("app", "db"),
+		"2.json": helper("db"),
+		"3.json": `{"name": "broken", "cmd": "missing-component"}`,
+		"4.json": helper("reports", "broken"),
+	} {
+		os.WriteFile(filepath.Join(configDir, name), []byte(config), 0o644)
+	}
+
+	// 模拟组件注册，记录注册顺序
+	var registered []string
+	var mu sync.Mutex
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+			m.Lock()
+			for name, comp := range m.Components {
+				if comp.Client == nil {
+					comp.Client = &fakeComponent{name: name, order: &[]string{}, mu: &mu}
+					registered = append(registered, name)
+				}
+			}
+			m.Unlock()
+		}
+	}()
+	m.LaunchComponents(configDir, "localhost:0")
+	// 等待模拟注册的 goroutine 退出后再检查结果与关闭组件
+	close(done)
+	wg.Wait()
+	defer m.ShutdownAllComponents(time.Second)
+
+	m.RLock()
+	started := strings.Join(m.started, ",")
+	m.RUnlock()
+	if started != "db,app" || len(registered) == 0 || registered[0] != "db" {
+		t.Errorf("应先启动被依赖的组件: started %v registered %v", started, registered)
+	}
+	if s, _ := m.LaunchStatus("app"); s.Phase != 2 || s.State != LaunchStarting {
+		t.Errorf("app 的启动状态不正确: %+v", s)
+	}
+	if s, _ := m.LaunchStatus("broken"); s.State != LaunchFailed {
+		t.Errorf("broken 应启动失败: %+v", s)
+	}
+	if s, _ := m.LaunchStatus("reports"); s.State != LaunchBlocked || strings.Join(s.BlockedBy, ",") != "broken" {
+		t.Errorf("依赖启动失败的组件应被阻塞: %+v", s)
+	}
+}
@@ -0,0 +1,130 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// watchExit 在后台等待组件进程退出并回收，使自行退出的组件不会成为僵尸进程，
+// 进程退出后关闭 exited。进程启动后必须调用一次，之后不能再调用 Cmd.Wait
+func (c *ComponentInfo) watchExit() {
+	cmd, name, exited := c.Cmd, c.Config.Name, make(chan struct{})
+	c.exited = exited
+	go func() {
+		cmd.Wait()
+		slog.Info("组件进程已退出", "component", name, "pid", cmd.Process.Pid, "status", cmd.ProcessState.String())
+		close(exited)
+	}()
+}
+
+// processExited 判断组件进程是否已退出并被回收
+func (c *ComponentInfo) processExited() bool {
+	select {
+	case <-c.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// validate 检查组件配置并解析启动进程所需的设置，exeDir 为 Supervisor 可执行文件所在目录。
+// cmd 与 working_dir 为绝对路径时直接使用，为相对路径时相对于 exeDir
+func (c *ComponentConfig) validate(exeDir string) error {
+	if c.Cmd == "" {
+		return errors.New("cmd 不能为空")
+	}
+	c.path = resolvePath(exeDir, c.Cmd)
+
+	if c.WorkingDir != "" {
+		c.dir = resolvePath(exeDir, c.WorkingDir)
+		info, err := os.Stat(c.dir)
+		if err != nil {
+			return fmt.Errorf("working_dir 无效: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("working_dir '%s' 不是目录", c.dir)
+		}
+	}
+
+	env, err := c.environ()
+	if err != nil {
+		return err
+	}
+	c.env = env
+
+	if c.User != "" || c.Group != "" {
+		cred, err := lookupCredential(c.User, c.Group)
+		if err != nil {
+			return err
+		}
+		c.cred = cred
+	}
+
+	if c.StopSignal != "" {
+		sig, err := parseSignal(c.StopSignal)
+		if err != nil {
+			return err
+		}
+		c.stopSignal = sig
+	}
+	if c.StopTimeoutSeconds < 0 {
+		return errors.New("stop_timeout_seconds 不能为负数")
+	}
+	if err := validSettings(c.Settings); err != nil {
+		return err
+	}
+	return c.validateLimits()
+}
+
+// resolvePath 将相对路径解析为相对于 base 的路径
+func resolvePath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+// environ 返回组件进程的环境变量：inherit_env 为 true 时在 Supervisor 的环境变量之上覆盖 env 中的变量，
+// 否则只包含 env 中的变量。env 的值中 ${VAR} 或 $VAR 引用 Supervisor 的环境变量，$$ 表示 "$"
+func (c *ComponentConfig) environ() ([]string, error) {
+	env := []string{}
+	if c.InheritEnv {
+		env = os.Environ()
+	}
+	for _, key := range slices.Sorted(maps.Keys(c.Env)) {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return nil, fmt.Errorf("env 中的变量名 '%s' 无效", key)
+		}
+		var undefined []string
+		value := os.Expand(c.Env[key], func(name string) string {
+			if name == "$" {
+				return "$"
+			}
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				undefined = append(undefined, name)
+			}
+			return v
+		})
+		if len(undefined) > 0 {
+			return nil, fmt.Errorf("env.%s 引用了未定义的环境变量: %s", key, strings.Join(undefined, ", "))
+		}
+		env = append(env, key+"="+value)
+	}
+	return env, nil
+}
+
+// socketPath 返回组件监听的 Unix 域套接字路径。以其他用户运行的组件无法在运行时目录中创建文件，
+// 其套接字位于属于该用户的子目录中
+func (m *ComponentManager) socketPath(config *ComponentConfig) string {
+	if config.cred != nil {
+		return filepath.Join(m.socketDir, config.Name, config.Name+".sock")
+	}
+	return filepath.Join(m.socketDir, config.Name+".sock")
+}
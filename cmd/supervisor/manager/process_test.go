@@ -0,0 +1,151 @@
+package manager
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"cse-go/cmd/supervisor/certs"
+)
+
+func TestComponentConfigValidate(t *testing.T) {
+	exeDir := t.TempDir()
+	file := filepath.Join(exeDir, "file")
+	os.WriteFile(file, nil, 0o644)
+	t.Setenv("CSE_TEST_BASE", "/opt/cse")
+
+	config := ComponentConfig{
+		Name:       "printer",
+		Cmd:        "printer",
+		WorkingDir: ".",
+		Env:        map[string]string{"DATA": "${CSE_TEST_BASE}/data", "PRICE": "$$5"},
+		StopSignal: "SIGKILL",
+	}
+	if err := config.validate(exeDir); err != nil {
+		t.Fatalf("配置应有效: %v", err)
+	}
+	if config.path != filepath.Join(exeDir, "printer") || config.dir != exeDir {
+		t.Errorf("相对路径应相对于主程序目录: %s %s", config.path, config.dir)
+	}
+	if !slices.Equal(config.env, []string{"DATA=/opt/cse/data", "PRICE=$5"}) {
+		t.Errorf("不继承环境变量时只应包含 env 中的变量: %v", config.env)
+	}
+
+	config = ComponentConfig{Name: "printer", Cmd: file, InheritEnv: true, Env: map[string]string{"A": "1"}}
+	if err := config.validate(exeDir); err != nil {
+		t.Fatalf("配置应有效: %v", err)
+	}
+	if config.path != file || !slices.Contains(config.env, "CSE_TEST_BASE=/opt/cse") || config.env[len(config.env)-1] != "A=1" {
+		t.Errorf("绝对路径应直接使用，环境变量应继承并覆盖: %s %v", config.path, config.env)
+	}
+
+	for name, c := range map[string]ComponentConfig{
+		"缺少 cmd":     {},
+		"工作目录不存在":    {Cmd: "printer", WorkingDir: "missing"},
+		"工作目录不是目录":   {Cmd: "printer", WorkingDir: file},
+		"变量名无效":      {Cmd: "printer", Env: map[string]string{"A=B": "1"}},
+		"引用未定义的变量":   {Cmd: "printer", Env: map[string]string{"A": "${CSE_TEST_UNDEFINED}"}},
+		"信号无效":       {Cmd: "printer", StopSignal: "SIGFOO"},
+		"停止超时为负数":    {Cmd: "printer", StopTimeoutSeconds: -1},
+		"设置不是对象":     {Cmd: "printer", Settings: json.RawMessage(`[1]`)},
+		"用户不存在":      {Cmd: "printer", User: "cse-test-no-such-user"},
+		"组不存在":       {Cmd: "printer", Group: "cse-test-no-such-group"},
+		"资源上限为负数":    {Cmd: "printer", RLimits: &RLimits{OpenFiles: -1}},
+		"cgroup 为负数": {Cmd: "printer", Cgroup: &Cgroup{PidsMax: -1}},
+	} {
+		if err := c.validate(exeDir); err == nil {
+			t.Errorf("%s: 应校验失败", name)
+		}
+	}
+}
+
+func TestStartComponentProcessConfig(t *testing.T) {
+	pki, err := certs.NewInternal(filepath.Join(t.TempDir(), "run"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewComponentManager(pki)
+	dump := filepath.Join(t.TempDir(), "dump.txt")
+	t.Setenv("CSE_TEST_DUMP_PATH", dump)
+	workDir, _ := filepath.EvalSymlinks(t.TempDir())
+
+	exe, _ := os.Executable()
+	config := &ComponentConfig{
+		Name:       "helper",
+		Cmd:        exe,
+		CmdArgs:    []string{"-test.run=TestHelperProcess", "--"},
+		WorkingDir: workDir,
+		Env:        map[string]string{"CSE_TEST_HELPER_PROCESS": "1", "CSE_TEST_DUMP": "${CSE_TEST_DUMP_PATH}"},
+	}
+	if err := config.validate(filepath.Dir(exe)); err != nil {
+		t.Fatal(err)
+	}
+	m.launch["helper"] = &LaunchStatus{State: LaunchPending, Phase: 1}
+	if !m.startComponent(config, "localhost:0") {
+		t.Fatal("组件应启动成功")
+	}
+	comp := m.Components["helper"]
+	<-comp.exited
+	if !comp.Cmd.ProcessState.Success() {
+		t.Fatalf("组件进程应正常退出: %v", comp.Cmd.ProcessState)
+	}
+	if comp.Usage() != nil {
+		t.Error("自行退出的组件不应返回资源使用情况")
+	}
+
+	data, err := os.ReadFile(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	if lines[0] != workDir {
+		t.Errorf("工作目录为 %s，期望 %s", lines[0], workDir)
+	}
+	if !slices.Contains(lines, "CSE_TEST_DUMP="+dump) || slices.Contains(lines, "CSE_TEST_DUMP_PATH="+dump) {
+		t.Errorf("组件只应有配置的环境变量: %v", lines[1:])
+	}
+}
+
+func TestShutdownStopSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 上只能强制终止进程")
+	}
+	var order []string
+	var mu sync.Mutex
+	start := func(name string, registered bool, config ComponentConfig) *ComponentInfo {
+		m := NewComponentManager(nil)
+		startComponent(t, m, name, registered, true, &order, &mu)
+		if err := config.validate(t.TempDir()); err != nil {
+			t.Fatal(err)
+		}
+		comp := m.Components[name]
+		comp.Config = &config
+		return comp
+	}
+
+	// 未注册的组件收到停止信号后退出，不属于强制终止
+	comp := start("unregistered", false, ComponentConfig{Cmd: "helper", StopSignal: "TERM"})
+	if err := shutdownComponent("unregistered", *comp, 5*time.Second); err != nil {
+		t.Errorf("组件应在收到停止信号后退出: %v", err)
+	}
+	if status, ok := comp.Cmd.ProcessState.Sys().(interface{ Signal() syscall.Signal }); !ok || status.Signal() != syscall.SIGTERM {
+		t.Errorf("组件应被 SIGTERM 终止: %v", comp.Cmd.ProcessState)
+	}
+
+	// 组件的 stop_timeout_seconds 代替全局的关闭超时
+	comp = start("hung", true, ComponentConfig{Cmd: "helper", StopTimeoutSeconds: 1})
+	begin := time.Now()
+	if err := shutdownComponent("hung", *comp, time.Minute); err == nil {
+		t.Error("未退出的组件应被强制终止")
+	}
+	if elapsed := time.Since(begin); elapsed > 10*time.Second {
+		t.Errorf("应使用组件的停止超时，耗时 %v", elapsed)
+	}
+}
@@ -0,0 +1,172 @@
+//go:build unix
+
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"cse-go/internal/transport"
+)
+
+// credential 是组件进程的用户、组与附加组
+type credential = syscall.Credential
+
+// signals 为 stop_signal 支持的信号，名称可以省略 SIG 前缀
+var signals = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGINT":  syscall.SIGINT,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+	"SIGKILL": syscall.SIGKILL,
+}
+
+// parseSignal 解析 stop_signal 中的信号名称
+func parseSignal(name string) (os.Signal, error) {
+	upper := strings.ToUpper(name)
+	if !strings.HasPrefix(upper, "SIG") {
+		upper = "SIG" + upper
+	}
+	sig, ok := signals[upper]
+	if !ok {
+		return nil, fmt.Errorf("不支持的 stop_signal '%s'", name)
+	}
+	return sig, nil
+}
+
+// lookupCredential 解析 user 与 group (名称或数字 ID)。只配置 user 时使用该用户的主组与附加组，
+// 只配置 group 时保持 Supervisor 的用户。非 root 运行时只能指定 Supervisor 自身的用户与组，此时返回 nil
+func lookupCredential(userName, groupName string) (*credential, error) {
+	cred := &credential{Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid()), Groups: []uint32{}}
+	if userName != "" {
+		u, err := user.Lookup(userName)
+		if err != nil {
+			if u, err = user.LookupId(userName); err != nil {
+				return nil, fmt.Errorf("用户 '%s' 不存在", userName)
+			}
+		}
+		uid, _ := strconv.ParseUint(u.Uid, 10, 32)
+		gid, _ := strconv.ParseUint(u.Gid, 10, 32)
+		cred.Uid, cred.Gid = uint32(uid), uint32(gid)
+		groups, err := u.GroupIds()
+		if err != nil {
+			return nil, fmt.Errorf("无法获取用户 '%s' 的附加组: %w", userName, err)
+		}
+		for _, g := range groups {
+			if id, err := strconv.ParseUint(g, 10, 32); err == nil {
+				cred.Groups = append(cred.Groups, uint32(id))
+			}
+		}
+	}
+	if groupName != "" {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			if g, err = user.LookupGroupId(groupName); err != nil {
+				return nil, fmt.Errorf("组 '%s' 不存在", groupName)
+			}
+		}
+		gid, _ := strconv.ParseUint(g.Gid, 10, 32)
+		cred.Gid = uint32(gid)
+	}
+
+	if os.Geteuid() != 0 {
+		if cred.Uid != uint32(os.Geteuid()) || cred.Gid != uint32(os.Getegid()) {
+			return nil, errors.New("以其他用户或组运行组件需要以 root 运行 Supervisor")
+		}
+		return nil, nil
+	}
+	return cred, nil
+}
+
+// sysProcAttr 返回进程的系统属性，未设置时创建
+func sysProcAttr(cmd *exec.Cmd) *syscall.SysProcAttr {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	return cmd.SysProcAttr
+}
+
+// setCredential 使组件进程以 cred 中的用户与组运行
+func setCredential(cmd *exec.Cmd, cred *credential) {
+	if cred == nil {
+		return
+	}
+	sysProcAttr(cmd).Credential = cred
+}
+
+// setProcessGroup 使组件进程成为新进程组的组长，关闭时其创建的子进程一起被终止，
+// 终端的 Ctrl+C 也只发送给 Supervisor
+func setProcessGroup(cmd *exec.Cmd) {
+	sysProcAttr(cmd).Setpgid = true
+}
+
+// kill 强制终止组件的进程组以及 cgroup 中的所有进程 (包括脱离了进程组的进程)。
+// 进程不是进程组组长时只终止该进程
+func (c *ComponentInfo) kill() error {
+	if c.Cgroup != "" {
+		os.WriteFile(filepath.Join(c.Cgroup, "cgroup.kill"), []byte("1"), 0o644)
+	}
+	if err := syscall.Kill(-c.Cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		return c.Cmd.Process.Kill()
+	}
+	return nil
+}
+
+// signal 向组件的进程组发送信号，进程不是进程组组长时只发送给该进程
+func (c *ComponentInfo) signal(sig os.Signal) error {
+	if s, ok := sig.(syscall.Signal); ok {
+		if err := syscall.Kill(-c.Cmd.Process.Pid, s); err == nil {
+			return nil
+		}
+	}
+	return c.Cmd.Process.Signal(sig)
+}
+
+// prepareForUser 使以其他用户运行的组件能够访问 Supervisor 为其创建的文件：
+// 证书文件、发现服务的套接字、组件套接字所在的子目录与 span 文件
+func (m *ComponentManager) prepareForUser(config *ComponentConfig, discoveryAddr string) error {
+	uid, gid := int(config.cred.Uid), int(config.cred.Gid)
+	if err := m.pki.SetOwner(config.Name, uid, gid); err != nil {
+		return err
+	}
+	if m.socketDir != "" {
+		// 运行时目录只允许其他用户进入，不允许列出或创建文件
+		if err := os.Chmod(m.socketDir, 0o711); err != nil {
+			return fmt.Errorf("无法设置运行时目录权限: %w", err)
+		}
+		// 连接发现服务仍需要内部 CA 签发的证书
+		if path := transport.SocketPath(discoveryAddr); path != "" {
+			if err := os.Chmod(path, 0o666); err != nil {
+				return fmt.Errorf("无法设置发现服务套接字权限: %w", err)
+			}
+		}
+		dir := filepath.Dir(m.socketPath(config))
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("无法创建组件的套接字目录: %w", err)
+		}
+		if err := os.Chown(dir, uid, gid); err != nil {
+			return fmt.Errorf("无法设置组件套接字目录的所有者: %w", err)
+		}
+	}
+	if m.traceDir != "" {
+		path := filepath.Join(m.traceDir, config.Name+".jsonl")
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("无法创建组件的 span 文件: %w", err)
+		}
+		f.Close()
+		if err := os.Chown(path, uid, gid); err != nil {
+			return fmt.Errorf("无法设置组件 span 文件的所有者: %w", err)
+		}
+	}
+	return nil
+}
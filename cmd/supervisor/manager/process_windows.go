@@ -0,0 +1,41 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// credential 在 Windows 上没有对应的设置，组件总是以 Supervisor 的用户运行
+type credential struct{}
+
+// parseSignal 解析 stop_signal 中的信号名称。Windows 上只能强制终止进程
+func parseSignal(name string) (os.Signal, error) {
+	switch strings.ToUpper(name) {
+	case "SIGKILL", "KILL":
+		return os.Kill, nil
+	}
+	return nil, fmt.Errorf("Windows 上 stop_signal 只支持 SIGKILL，不支持 '%s'", name)
+}
+
+func lookupCredential(userName, groupName string) (*credential, error) {
+	return nil, errors.New("Windows 上不支持 user 与 group")
+}
+
+func setCredential(cmd *exec.Cmd, cred *credential) {}
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func (c *ComponentInfo) kill() error {
+	return c.Cmd.Process.Kill()
+}
+
+func (c *ComponentInfo) signal(sig os.Signal) error {
+	return c.Cmd.Process.Signal(sig)
+}
+
+func (m *ComponentManager) prepareForUser(config *ComponentConfig, discoveryAddr string) error {
+	return nil
+}
@@ -0,0 +1,186 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"cse-go/cmd/supervisor/secrets"
+	pb "cse-go/pkg/api/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// configureTimeout 为等待组件应用 settings 的时间
+const configureTimeout = 10 * time.Second
+
+// validSettings 检查 settings 是否为 JSON 对象，以及其中的 secret 引用格式
+func validSettings(settings json.RawMessage) error {
+	if len(settings) == 0 {
+		return nil
+	}
+	var v map[string]json.RawMessage
+	if err := json.Unmarshal(settings, &v); err != nil || v == nil {
+		return errors.New("settings 必须是 JSON 对象")
+	}
+	_, err := secrets.References(settings)
+	return err
+}
+
+// configure 将 settings 推送给组件。未实现 Configure 的组件在没有 settings 时视为成功
+func configure(ctx context.Context, client pb.ComponentServiceClient, settings json.RawMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, configureTimeout)
+	defer cancel()
+	resp, err := client.Configure(ctx, &pb.ConfigureRequest{SettingsJson: string(settings)})
+	if status.Code(err) == codes.Unimplemented {
+		if len(settings) == 0 {
+			return nil
+		}
+		return errors.New("组件不支持 settings")
+	}
+	if err != nil {
+		return fmt.Errorf("无法推送 settings: %w", err)
+	}
+	if !resp.Accepted {
+		return errors.New(resp.ErrorMessage)
+	}
+	return nil
+}
+
+// applySettings 解析 settings 中的 secret 引用后推送给组件并更新其启动状态：
+// secret 无法解析或组件拒绝时标记为 error，接受时恢复为 running。返回的错误不包含 secret 的值
+func (m *ComponentManager) applySettings(name string, client pb.ComponentServiceClient, settings json.RawMessage) error {
+	resolved, err := m.secrets.Resolve(settings)
+	if err == nil {
+		err = configure(context.Background(), client, resolved)
+	}
+	if err != nil {
+		err = errors.New(m.secrets.Redact(err.Error()))
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	// 推送期间组件可能已重新注册，结果只对当前的连接有效
+	if comp, ok := m.Components[name]; !ok || comp.Client != client {
+		return err
+	}
+	st, ok := m.launch[name]
+	if !ok {
+		return err
+	}
+	if err != nil {
+		slog.Error("组件拒绝了 settings", "component", name, "error", err)
+		st.State = LaunchError
+		st.Error = err.Error()
+		return err
+	}
+	if st.State == LaunchError {
+		st.State = LaunchRunning
+		st.Error = ""
+	}
+	return nil
+}
+
+// ReloadSettings 重新读取配置目录，将 settings 有变化或处于 error 状态的组件的 settings 推送给已注册的组件，
+// 返回这些组件的推送结果 (尚未注册的组件在注册时使用新的 settings，不在结果中)。
+// 只有 settings 会重新加载，其他配置项的修改需要重启 Supervisor 才能生效，新增的组件同样不会启动
+func (m *ComponentManager) ReloadSettings() (map[string]error, error) {
+	m.lock.RLock()
+	configDir := m.configDir
+	m.lock.RUnlock()
+	if configDir == "" {
+		return nil, errors.New("组件尚未启动")
+	}
+	configs, err := readConfigs(configDir)
+	if err != nil {
+		return nil, err
+	}
+
+	type target struct {
+		client   pb.ComponentServiceClient
+		settings json.RawMessage
+	}
+	results := map[string]error{}
+	targets := map[string]target{}
+	m.lock.Lock()
+	for _, config := range configs {
+		comp, ok := m.Components[config.Name]
+		if !ok || comp.Config == nil {
+			continue
+		}
+		if err := validSettings(config.Settings); err != nil {
+			slog.Error("组件的 settings 无效，保持之前的 settings", "file", config.file, "component", config.Name, "error", err)
+			results[config.Name] = err
+			continue
+		}
+		// 拒绝了之前的 settings 的组件即使 settings 没有变化也重新推送
+		st, ok := m.launch[config.Name]
+		if bytes.Equal(comp.Config.Settings, config.Settings) && (!ok || st.State != LaunchError) {
+			continue
+		}
+		comp.Config.Settings = config.Settings
+		if comp.Client != nil {
+			targets[config.Name] = target{comp.Client, config.Settings}
+		}
+	}
+	m.lock.Unlock()
+
+	for name, t := range targets {
+		if err := m.applySettings(name, t.client, t.settings); err != nil {
+			results[name] = err
+			continue
+		}
+		results[name] = nil
+		slog.Info("组件已应用新的 settings", "component", name)
+	}
+	return results, nil
+}
+
+// ApplySecret 将 settings 引用了 secret 的已注册组件的 settings 重新推送给组件，在 secret 被修改后调用，
+// 返回这些组件的推送结果
+func (m *ComponentManager) ApplySecret(name string) map[string]error {
+	type target struct {
+		client   pb.ComponentServiceClient
+		settings json.RawMessage
+	}
+	targets := map[string]target{}
+	m.lock.RLock()
+	for compName, comp := range m.Components {
+		if comp.Config == nil || comp.Client == nil {
+			continue
+		}
+		if refs, _ := secrets.References(comp.Config.Settings); slices.Contains(refs, name) {
+			targets[compName] = target{comp.Client, comp.Config.Settings}
+		}
+	}
+	m.lock.RUnlock()
+
+	results := make(map[string]error, len(targets))
+	for compName, t := range targets {
+		results[compName] = m.applySettings(compName, t.client, t.settings)
+		if results[compName] == nil {
+			slog.Info("组件已应用更新后的 secret", "component", compName, "secret", name)
+		}
+	}
+	return results
+}
+
+// SecretReferences 返回 settings 引用了 secret 的组件名称
+func (m *ComponentManager) SecretReferences(name string) []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	var names []string
+	for _, config := range m.configured {
+		if refs, _ := secrets.References(config.Settings); slices.Contains(refs, name) {
+			names = append(names, config.Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,182 @@
+package manager
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"cse-go/cmd/supervisor/secrets"
+	pb "cse-go/pkg/api/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// configurableComponent 记录收到的 settings，settings 中包含 "invalid" 时拒绝，包含 "echo" 时在拒绝原因中返回 settings
+type configurableComponent struct {
+	pb.ComponentServiceClient
+	unimplemented bool
+
+	mu       sync.Mutex
+	received []string
+}
+
+func (c *configurableComponent) Configure(ctx context.Context, req *pb.ConfigureRequest, opts ...grpc.CallOption) (*pb.ConfigureResponse, error) {
+	if c.unimplemented {
+		return nil, status.Error(codes.Unimplemented, "method Configure not implemented")
+	}
+	c.mu.Lock()
+	c.received = append(c.received, req.SettingsJson)
+	c.mu.Unlock()
+	if strings.Contains(req.SettingsJson, "echo") {
+		return &pb.ConfigureResponse{Accepted: false, ErrorMessage: "无效的 settings: " + req.SettingsJson}, nil
+	}
+	if strings.Contains(req.SettingsJson, "invalid") {
+		return &pb.ConfigureResponse{Accepted: false, ErrorMessage: "backend 无效"}, nil
+	}
+	return &pb.ConfigureResponse{Accepted: true}, nil
+}
+
+func TestApplySettings(t *testing.T) {
+	m := NewComponentManager(nil)
+	component := &configurableComponent{}
+	m.Components["printer"] = &ComponentInfo{Client: component}
+	m.launch["printer"] = &LaunchStatus{State: LaunchRunning, Phase: 1}
+
+	if err := m.applySettings("printer", component, json.RawMessage(`{"backend":"invalid"}`)); err == nil || err.Error() != "backend 无效" {
+		t.Errorf("应返回组件拒绝的原因: %v", err)
+	}
+	if s, _ := m.LaunchStatus("printer"); s.State != LaunchError || s.Error != "backend 无效" {
+		t.Errorf("拒绝 settings 的组件应标记为 error: %+v", s)
+	}
+	if err := m.applySettings("printer", component, json.RawMessage(`{"backend":"fake"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := m.LaunchStatus("printer"); s.State != LaunchRunning || s.Error != "" {
+		t.Errorf("接受 settings 后应恢复为 running: %+v", s)
+	}
+
+	// 未实现 Configure 的组件只在配置了 settings 时视为错误
+	legacy := &configurableComponent{unimplemented: true}
+	m.Components["printer"].Client = legacy
+	if err := m.applySettings("printer", legacy, nil); err != nil {
+		t.Errorf("未配置 settings 时不应要求组件实现 Configure: %v", err)
+	}
+	if err := m.applySettings("printer", legacy, json.RawMessage(`{"backend":"fake"}`)); err == nil {
+		t.Error("配置了 settings 但组件未实现 Configure 时应返回错误")
+	}
+
+	// 推送期间组件重新注册时不更新状态
+	m.launch["printer"] = &LaunchStatus{State: LaunchRunning, Phase: 1}
+	m.applySettings("printer", component, json.RawMessage(`{"backend":"invalid"}`))
+	if s, _ := m.LaunchStatus("printer"); s.State != LaunchRunning {
+		t.Errorf("旧连接的推送结果不应影响组件状态: %+v", s)
+	}
+}
+
+func TestReloadSettings(t *testing.T) {
+	m := NewComponentManager(nil)
+	if _, err := m.ReloadSettings(); err == nil {
+		t.Error("组件启动前重新加载应返回错误")
+	}
+
+	configDir := t.TempDir()
+	write := func(name, config string) {
+		os.WriteFile(filepath.Join(configDir, name+".json"), []byte(config), 0o644)
+	}
+	write("printer", `{"name": "printer", "cmd": "printer", "settings": {"backend": "fake"}}`)
+	write("scanner", `{"name": "scanner", "cmd": "scanner"}`)
+	write("reports", `{"name": "reports", "cmd": "reports"}`)
+	configs, err := readConfigs(configDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.configDir = configDir
+	printer, scanner := &configurableComponent{}, &configurableComponent{}
+	for _, config := range configs {
+		m.Components[config.Name] = &ComponentInfo{Config: config}
+		m.launch[config.Name] = &LaunchStatus{State: LaunchRunning, Phase: 1}
+	}
+	m.Components["printer"].Client = printer
+	m.Components["scanner"].Client = scanner
+
+	write("printer", `{"name": "printer", "cmd": "printer", "settings": {"backend": "invalid"}}`)
+	write("scanner", `{"name": "scanner", "cmd": "scanner", "settings": {"port": 9100}}`)
+	write("reports", `{"name": "reports", "cmd": "reports", "settings": [1]}`)
+	results, err := m.ReloadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results["printer"] == nil || results["scanner"] != nil || results["reports"] == nil {
+		t.Errorf("重新加载的结果不正确: %v", results)
+	}
+	if len(scanner.received) != 1 || scanner.received[0] != `{"port": 9100}` {
+		t.Errorf("应推送新的 settings: %v", scanner.received)
+	}
+	if s, _ := m.LaunchStatus("printer"); s.State != LaunchError {
+		t.Errorf("拒绝新 settings 的组件应标记为 error: %+v", s)
+	}
+	if string(m.Components["reports"].Config.Settings) != "" {
+		t.Error("无效的 settings 不应替换之前的 settings")
+	}
+
+	// settings 没有变化的组件不再推送，处于 error 状态的组件重新推送
+	results, _ = m.ReloadSettings()
+	if len(results) != 2 || len(scanner.received) != 1 {
+		t.Errorf("settings 没有变化时不应推送: %v %v", results, scanner.received)
+	}
+}
+
+func TestApplySettingsWithSecrets(t *testing.T) {
+	m := NewComponentManager(nil)
+	component := &configurableComponent{}
+	settings := json.RawMessage(`{"token": {"$secret": "cloud-token"}}`)
+	m.Components["printer"] = &ComponentInfo{Client: component, Config: &ComponentConfig{Name: "printer", Settings: settings}}
+	m.Components["scanner"] = &ComponentInfo{Client: &configurableComponent{}, Config: &ComponentConfig{Name: "scanner"}}
+	m.configured = []*ComponentConfig{m.Components["printer"].Config, m.Components["scanner"].Config}
+	m.launch["printer"] = &LaunchStatus{State: LaunchRunning, Phase: 1}
+
+	// 未配置主密钥时引用了 secret 的 settings 无法推送
+	if err := m.applySettings("printer", component, settings); err == nil {
+		t.Error("未配置主密钥时应返回错误")
+	}
+	encoded, _ := secrets.GenerateKey()
+	key, _ := base64.StdEncoding.DecodeString(encoded)
+	store, err := secrets.Open(filepath.Join(t.TempDir(), "secrets.enc"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetSecrets(store)
+	if err := m.applySettings("printer", component, settings); err == nil {
+		t.Error("引用的 secret 不存在时应返回错误")
+	}
+
+	store.Set("cloud-token", "tok-echo-1")
+	results := m.ApplySecret("cloud-token")
+	if len(results) != 1 || results["printer"] == nil {
+		t.Fatalf("应只向引用了 secret 的组件推送: %v", results)
+	}
+	if results["printer"].Error() != `无效的 settings: {"token":"[REDACTED]"}` {
+		t.Errorf("错误中不应包含 secret 的值: %v", results["printer"])
+	}
+	if got := component.received[len(component.received)-1]; got != `{"token":"tok-echo-1"}` {
+		t.Errorf("推送给组件的 settings 应包含 secret 的值: %s", got)
+	}
+
+	store.Set("cloud-token", "tok-1234")
+	if results := m.ApplySecret("cloud-token"); results["printer"] != nil {
+		t.Errorf("更新 secret 后应推送成功: %v", results)
+	}
+	if s, _ := m.LaunchStatus("printer"); s.State != LaunchRunning {
+		t.Errorf("应用 settings 后应恢复为 running: %+v", s)
+	}
+	if refs := m.SecretReferences("cloud-token"); strings.Join(refs, ",") != "printer" {
+		t.Errorf("引用 secret 的组件不正确: %v", refs)
+	}
+}
@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// logHandler 将日志消息与属性中出现的 secret 值替换为 "[REDACTED]"
+type logHandler struct {
+	slog.Handler
+	store *Store
+}
+
+// NewLogHandler 包装 h，使写入的日志不包含 store 中 secret 的值
+func NewLogHandler(h slog.Handler, store *Store) slog.Handler {
+	return logHandler{h, store}
+}
+
+func (h logHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, h.store.Redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactAttr(a)
+	}
+	return logHandler{h.Handler.WithAttrs(redacted), h.store}
+}
+
+func (h logHandler) WithGroup(name string) slog.Handler {
+	return logHandler{h.Handler.WithGroup(name), h.store}
+}
+
+// redactAttr 脱敏字符串、错误与分组属性，其他类型的值在格式化后包含 secret 时替换为字符串
+func (h logHandler) redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.store.Redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, child := range group {
+			redacted[i] = h.redactAttr(child)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, h.store.Redact(err.Error()))
+		}
+		text := fmt.Sprint(v.Any())
+		if redacted := h.store.Redact(text); redacted != text {
+			return slog.String(a.Key, redacted)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
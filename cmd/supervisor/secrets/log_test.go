@@ -0,0 +1,36 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogHandler(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "secrets.enc"), testKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Set("token", "tok-123456")
+
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewTextHandler(&buf, nil), s)).With("auth", "Bearer tok-123456")
+	logger.Info("连接 tok-123456 失败",
+		"error", errors.New("invalid token tok-123456"),
+		"settings", map[string]string{"token": "tok-123456"},
+		slog.Group("request", "header", "tok-123456"),
+		"port", 9100,
+	)
+	out := buf.String()
+	if strings.Contains(out, "tok-123456") {
+		t.Errorf("日志中不应出现 secret 的值: %s", out)
+	}
+	for _, want := range []string{`msg="连接 [REDACTED] 失败"`, `auth="Bearer [REDACTED]"`, `error="invalid token [REDACTED]"`, `request.header=[REDACTED]`, "port=9100"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("日志应包含 %s: %s", want, out)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+// Package secrets 保存组件 settings 中引用的凭据。secret 以 AES-256-GCM 加密后写入本地文件，
+// 主密钥来自环境变量或密钥文件。secret 的值只在推送给组件时解析，不能通过 API 读取，
+// 并在日志与 API 输出中被替换为 "[REDACTED]"
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// KeyEnv 为保存主密钥的环境变量，优先于密钥文件
+	KeyEnv = "CSE_SECRETS_KEY"
+	// KeySize 为主密钥的字节数
+	KeySize = 32
+	// RefKey 为 settings 中引用 secret 的字段，例如 {"$secret": "cloud-print-token"}
+	RefKey = "$secret"
+	// Redacted 为脱敏后的替换文本
+	Redacted = "[REDACTED]"
+	// minRedactLen 为参与脱敏的最短值，更短的值无法可靠地与其他内容区分
+	minRedactLen = 4
+	// fileVersion 为加密文件的格式版本
+	fileVersion = 1
+)
+
+var (
+	// ErrNotFound 表示指定名称的 secret 不存在
+	ErrNotFound = errors.New("secret 不存在")
+	// ErrNotConfigured 表示未配置主密钥，不能使用 secret
+	ErrNotConfigured = errors.New("未配置 secret 主密钥")
+
+	validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+	// additionalData 绑定到密文，防止其他用途的密文被当作 secret 文件解密
+	additionalData = []byte("cse-secrets-v1")
+)
+
+// Info 是 secret 的摘要信息，不包含值
+type Info struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// entry 是加密前的一个 secret
+type entry struct {
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// envelope 是加密文件的内容
+type envelope struct {
+	Version    int    `json:"version"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Store 保存所有 secret，修改后立即加密写回文件，可并发使用。
+// nil 的 *Store 表示未配置主密钥：Resolve 对引用了 secret 的 settings 返回 ErrNotConfigured，Redact 原样返回
+type Store struct {
+	path string
+	aead cipher.AEAD
+
+	mu       sync.RWMutex
+	entries  map[string]entry
+	replacer *strings.Replacer
+}
+
+// LoadKey 读取主密钥 (base64 编码的 32 字节)：环境变量 CSE_SECRETS_KEY 优先，其次为 keyFile。
+// 都未配置时返回 nil 与 nil 错误
+func LoadKey(keyFile string) ([]byte, error) {
+	encoded, source := os.Getenv(KeyEnv), KeyEnv
+	if encoded == "" {
+		if keyFile == "" {
+			return nil, nil
+		}
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("无法读取主密钥文件: %w", err)
+		}
+		encoded, source = string(data), keyFile
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != KeySize {
+		return nil, fmt.Errorf("%s 中的主密钥必须是 base64 编码的 %d 字节", source, KeySize)
+	}
+	return key, nil
+}
+
+// GenerateKey 生成新的主密钥，返回其 base64 编码
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Open 使用主密钥打开 path 中的 secret 文件，文件不存在时在首次修改时创建
+func Open(path string, key []byte) (*Store, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{path: path, aead: aead, entries: map[string]entry{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.rebuild()
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取 secret 文件: %w", err)
+	}
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Version != fileVersion {
+		return nil, fmt.Errorf("secret 文件 '%s' 格式无效", path)
+	}
+	plain, err := aead.Open(nil, env.Nonce, env.Ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("无法解密 secret 文件 '%s'，主密钥不正确或文件已损坏", path)
+	}
+	if err := json.Unmarshal(plain, &s.entries); err != nil {
+		return nil, fmt.Errorf("secret 文件 '%s' 内容无效: %w", path, err)
+	}
+	s.rebuild()
+	return s, nil
+}
+
+// Set 创建或替换 secret
+func (s *Store) Set(name, value string) (*Info, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("secret 名称 '%s' 无效，只能包含字母、数字、'_'、'.' 与 '-'", name)
+	}
+	if value == "" {
+		return nil, errors.New("secret 的值不能为空")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, existed := s.entries[name]
+	e := entry{Value: value, UpdatedAt: time.Now().UTC()}
+	s.entries[name] = e
+	if err := s.save(); err != nil {
+		if existed {
+			s.entries[name] = old
+		} else {
+			delete(s.entries, name)
+		}
+		return nil, err
+	}
+	s.rebuild()
+	return &Info{Name: name, UpdatedAt: e.UpdatedAt}, nil
+}
+
+// Delete 删除 secret
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.entries[name]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.entries, name)
+	if err := s.save(); err != nil {
+		s.entries[name] = old
+		return err
+	}
+	s.rebuild()
+	return nil
+}
+
+// List 按名称顺序返回所有 secret 的摘要信息
+func (s *Store) List() []*Info {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	infos := make([]*Info, 0, len(s.entries))
+	for name, e := range s.entries {
+		infos = append(infos, &Info{Name: name, UpdatedAt: e.UpdatedAt})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// References 按名称顺序返回 settings 中引用的 secret 名称 (不重复)。
+// 引用必须是只包含 "$secret" 字段且值为字符串的对象
+func References(settings json.RawMessage) ([]string, error) {
+	if len(settings) == 0 {
+		return nil, nil
+	}
+	var v any
+	if err := json.Unmarshal(settings, &v); err != nil {
+		return nil, err
+	}
+	var names []string
+	_, err := walk(v, func(name string) (string, error) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+		return "", nil
+	})
+	sort.Strings(names)
+	return names, err
+}
+
+// Resolve 将 settings 中的 secret 引用替换为 secret 的值。没有引用时原样返回 settings
+func (s *Store) Resolve(settings json.RawMessage) (json.RawMessage, error) {
+	names, err := References(settings)
+	if err != nil || len(names) == 0 {
+		return settings, err
+	}
+	if s == nil {
+		return nil, ErrNotConfigured
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(settings))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	resolved, err := walk(v, func(name string) (string, error) {
+		e, ok := s.entries[name]
+		if !ok {
+			return "", fmt.Errorf("settings 引用的 secret '%s' 不存在", name)
+		}
+		return e.Value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(resolved)
+}
+
+// walk 遍历 JSON 值，将每个 secret 引用替换为 lookup 的返回值
+func walk(v any, lookup func(name string) (string, error)) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		if ref, ok := v[RefKey]; ok {
+			name, isString := ref.(string)
+			if !isString || len(v) != 1 {
+				return nil, fmt.Errorf(`secret 引用必须是 {"%s": "<名称>"}`, RefKey)
+			}
+			return lookup(name)
+		}
+		for key, child := range v {
+			resolved, err := walk(child, lookup)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+	case []any:
+		for i, child := range v {
+			resolved, err := walk(child, lookup)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+	}
+	return v, nil
+}
+
+// Redact 将文本中出现的 secret 值 (包括其 JSON 转义形式) 替换为 "[REDACTED]"。
+// 短于 4 个字节的值不参与替换
+func (s *Store) Redact(text string) string {
+	if s == nil {
+		return text
+	}
+	s.mu.RLock()
+	replacer := s.replacer
+	s.mu.RUnlock()
+	if replacer == nil {
+		return text
+	}
+	return replacer.Replace(text)
+}
+
+// rebuild 根据当前的 secret 重建脱敏替换器，调用方需持有写锁
+func (s *Store) rebuild() {
+	var values []string
+	for _, e := range s.entries {
+		if len(e.Value) < minRedactLen {
+			continue
+		}
+		values = append(values, e.Value)
+		if quoted, _ := json.Marshal(e.Value); string(quoted[1:len(quoted)-1]) != e.Value {
+			values = append(values, string(quoted[1:len(quoted)-1]))
+		}
+	}
+	if len(values) == 0 {
+		s.replacer = nil
+		return
+	}
+	// 较长的值优先匹配，避免其中包含的较短的值先被替换
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	pairs := make([]string, 0, 2*len(values))
+	for _, v := range values {
+		pairs = append(pairs, v, Redacted)
+	}
+	s.replacer = strings.NewReplacer(pairs...)
+}
+
+// save 加密并原子地写回文件 (仅当前用户可读)，调用方需持有写锁
+func (s *Store) save() error {
+	plain, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data, err := json.Marshal(envelope{
+		Version:    fileVersion,
+		Nonce:      nonce,
+		Ciphertext: s.aead.Seal(nil, nonce, plain, additionalData),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("无法创建 secret 目录: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("无法写入 secret 文件: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("无法写入 secret 文件: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("无法写入 secret 文件: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		return fmt.Errorf("无法设置 secret 文件权限: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,160 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+	encoded, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := base64.StdEncoding.DecodeString(encoded)
+	return key
+}
+
+func TestStorePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "secrets.enc")
+	key := testKey(t)
+	s, err := Open(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Set("cloud-print.token", "tok-1234567890"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Set("terminal_pin", "4821"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("terminal_pin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("terminal_pin"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("删除不存在的 secret 应返回 ErrNotFound: %v", err)
+	}
+	for _, name := range []string{"", "-token", "a/b", "a b", strings.Repeat("a", 129)} {
+		if _, err := s.Set(name, "value"); err == nil {
+			t.Errorf("名称 %q 应无效", name)
+		}
+	}
+	if _, err := s.Set("empty", ""); err == nil {
+		t.Error("空的值应无效")
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "tok-1234567890") || strings.Contains(string(data), "cloud-print") {
+		t.Errorf("文件中不应出现明文: %s", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("文件权限应为 0600，实际 %v", info.Mode().Perm())
+	}
+
+	s, err = Open(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := s.List(); len(list) != 1 || list[0].Name != "cloud-print.token" || list[0].UpdatedAt.IsZero() {
+		t.Errorf("重新打开后的 secret 不正确: %+v", list)
+	}
+	if _, err := Open(path, testKey(t)); err == nil {
+		t.Error("使用错误的主密钥应无法打开")
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	encoded, _ := GenerateKey()
+	keyFile := filepath.Join(t.TempDir(), "secrets.key")
+	os.WriteFile(keyFile, []byte(encoded+"\n"), 0o600)
+
+	t.Setenv(KeyEnv, "")
+	if key, err := LoadKey(""); key != nil || err != nil {
+		t.Errorf("未配置主密钥时应返回 nil: %v", err)
+	}
+	if key, err := LoadKey(keyFile); err != nil || base64.StdEncoding.EncodeToString(key) != encoded {
+		t.Errorf("应从文件读取主密钥: %v", err)
+	}
+	if _, err := LoadKey(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("主密钥文件不存在时应返回错误")
+	}
+
+	t.Setenv(KeyEnv, base64.StdEncoding.EncodeToString([]byte("short")))
+	if _, err := LoadKey(keyFile); err == nil {
+		t.Error("环境变量优先，长度错误的主密钥应返回错误")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "secrets.enc"), testKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Set("token", `tok"en`)
+	s.Set("pin", "4821")
+
+	settings := json.RawMessage(`{"port": 9100, "auth": {"token": {"$secret": "token"}}, "pins": [{"$secret": "pin"}, {"$secret": "token"}]}`)
+	if names, err := References(settings); err != nil || strings.Join(names, ",") != "pin,token" {
+		t.Errorf("引用的 secret 不正确: %v %v", names, err)
+	}
+	resolved, err := s.Resolve(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resolved) != `{"auth":{"token":"tok\"en"},"pins":["4821","tok\"en"],"port":9100}` {
+		t.Errorf("解析结果不正确: %s", resolved)
+	}
+
+	plain := json.RawMessage(`{"port": 9100}`)
+	if resolved, err := (*Store)(nil).Resolve(plain); err != nil || string(resolved) != string(plain) {
+		t.Errorf("没有引用时应原样返回: %s %v", resolved, err)
+	}
+	if _, err := (*Store)(nil).Resolve(settings); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("未配置主密钥时应返回 ErrNotConfigured: %v", err)
+	}
+	if _, err := s.Resolve(json.RawMessage(`{"a": {"$secret": "missing"}}`)); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("引用不存在的 secret 应返回错误: %v", err)
+	}
+	for _, invalid := range []string{`{"a": {"$secret": 1}}`, `{"a": {"$secret": "token", "b": 1}}`} {
+		if _, err := References(json.RawMessage(invalid)); err == nil {
+			t.Errorf("引用 %s 应无效", invalid)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "secrets.enc"), testKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Redact("token tok-123"); got != "token tok-123" {
+		t.Errorf("没有 secret 时应原样返回: %q", got)
+	}
+	s.Set("token", "tok-123")
+	s.Set("long", "tok-123-long")
+	s.Set("quoted", `a"b\c`)
+	s.Set("short", "abc")
+
+	for text, want := range map[string]string{
+		"token=tok-123 long=tok-123-long": "token=[REDACTED] long=[REDACTED]",
+		`{"error":"a\"b\\c"} a"b\c`:       `{"error":"[REDACTED]"} [REDACTED]`,
+		"abc":                             "abc",
+	} {
+		if got := s.Redact(text); got != want {
+			t.Errorf("Redact(%q) = %q，期望 %q", text, got, want)
+		}
+	}
+
+	s.Delete("token")
+	if got := s.Redact("tok-123"); got != "tok-123" {
+		t.Errorf("删除后不应再替换: %q", got)
+	}
+	if got := (*Store)(nil).Redact("tok-123"); got != "tok-123" {
+		t.Errorf("nil 的 Store 应原样返回: %q", got)
+	}
+}
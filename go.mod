@@ -5,13 +5,11 @@ go 1.23.1
 require (
 	github.com/godoes/printers v0.1.4
 	github.com/magefile/mage v1.15.0
+	golang.org/x/net v0.38.0
+	golang.org/x/sys v0.31.0
+	golang.org/x/text v0.23.0
 	google.golang.org/grpc v1.73.0
 	google.golang.org/protobuf v1.36.6
 )
 
-require (
-	golang.org/x/net v0.38.0 // indirect
-	golang.org/x/sys v0.31.0 // indirect
-	golang.org/x/text v0.23.0 // indirect
-	google.golang.org/genproto/googleapis/rpc v0.0.0-20250324211829-b45e905df463 // indirect
-)
+require google.golang.org/genproto/googleapis/rpc v0.0.0-20250324211829-b45e905df463 // indirect
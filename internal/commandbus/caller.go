@@ -0,0 +1,62 @@
+package commandbus
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// 调用方身份在 gRPC 元数据中使用的键
+const (
+	metadataUser       = "x-cse-caller-user"
+	metadataRemoteAddr = "x-cse-caller-addr"
+	metadataUserAgent  = "x-cse-caller-agent"
+	metadataOrigin     = "x-cse-caller-origin"
+)
+
+// Caller 是发起命令的调用方身份，由 Supervisor 根据 HTTP 请求填写并经 gRPC 元数据传递给组件
+type Caller struct {
+	User       string `json:"user,omitempty"`
+	RemoteAddr string `json:"remoteAddr,omitempty"`
+	UserAgent  string `json:"userAgent,omitempty"`
+	Origin     string `json:"origin,omitempty"`
+}
+
+// NewOutgoingContext 将调用方身份附加到发往组件的 gRPC 请求中
+func NewOutgoingContext(ctx context.Context, caller Caller) context.Context {
+	var kv []string
+	for _, pair := range [][2]string{
+		{metadataUser, caller.User},
+		{metadataRemoteAddr, caller.RemoteAddr},
+		{metadataUserAgent, caller.UserAgent},
+		{metadataOrigin, caller.Origin},
+	} {
+		if pair[1] != "" {
+			kv = append(kv, pair[0], pair[1])
+		}
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// CallerFromContext 从组件收到的 gRPC 请求中读取调用方身份，没有时返回零值
+func CallerFromContext(ctx context.Context) Caller {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return Caller{}
+	}
+	get := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	return Caller{
+		User:       get(metadataUser),
+		RemoteAddr: get(metadataRemoteAddr),
+		UserAgent:  get(metadataUserAgent),
+		Origin:     get(metadataOrigin),
+	}
+}
@@ -1,6 +1,8 @@
 package commandbus
 
 import (
+	"context"
+
 	pb "cse-go/pkg/api/v1" // 导入 gRPC API
 )
 
@@ -16,3 +18,12 @@ type Command interface {
 	// GetInfo 返回命令的元数据，用于服务发现
 	GetInfo() *pb.CommandInfo
 }
+
+// ContextCommand 是需要调用上下文 (例如调用方身份) 的命令。
+// 组件发现命令实现了该接口时调用 ExecuteContext 而不是 Execute
+type ContextCommand interface {
+	Command
+
+	// ExecuteContext 使用 gRPC 请求的上下文执行命令逻辑
+	ExecuteContext(ctx context.Context, params *pb.CommandParams) (*pb.CommandResult, error)
+}
@@ -0,0 +1,35 @@
+package commandbus
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"cse-go/internal/tracing"
+)
+
+// FlagLogLevel 是 Supervisor 向组件传递日志级别的命令行参数
+const FlagLogLevel = "log-level"
+
+// SetupLogging 将默认日志设置为写入 w 的 slog 日志。level 为 debug、info、warn 或 error，
+// format 为 text 或 json。标准库 log 包的输出也会以 INFO 级别经由该日志输出。
+// 通过 slog.InfoContext 等方法写入的日志包含上下文中的请求 ID 与 span 标识。
+// 组件应写入标准错误，Supervisor 会捕获组件的输出并写入组件日志文件
+func SetupLogging(w io.Writer, level, format string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("无效的日志级别 '%s'", level)
+	}
+	opts := &slog.HandlerOptions{Level: l}
+	var handler slog.Handler
+	switch format {
+	case "", "text":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("无效的日志格式 '%s'", format)
+	}
+	slog.SetDefault(slog.New(tracing.NewLogHandler(handler)))
+	return nil
+}
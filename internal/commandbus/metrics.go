@@ -0,0 +1,67 @@
+package commandbus
+
+import (
+	"sort"
+
+	"cse-go/internal/metrics"
+	pb "cse-go/pkg/api/v1"
+)
+
+// Metrics 是组件自定义指标的注册表。组件在其中注册计数器等指标，
+// 并在 GetMetrics 中返回 MetricsResponse(Metrics)；Supervisor 汇总后以
+// cse_component_<名称>{component="<组件名>"} 的形式输出到 /metrics
+var Metrics = metrics.NewRegistry()
+
+// MetricsResponse 将注册表中的指标转换为 GetMetrics 的响应
+func MetricsResponse(r *metrics.Registry) *pb.GetMetricsResponse {
+	resp := &pb.GetMetricsResponse{}
+	for _, f := range r.Gather() {
+		family := &pb.MetricFamily{Name: f.Name, Help: f.Help, Type: f.Type}
+		for _, s := range f.Samples {
+			sample := &pb.MetricSample{Suffix: s.Suffix, Value: s.Value}
+			if len(s.Labels) > 0 {
+				sample.Labels = make(map[string]string, len(s.Labels))
+				for _, l := range s.Labels {
+					sample.Labels[l.Name] = l.Value
+				}
+			}
+			family.Samples = append(family.Samples, sample)
+		}
+		resp.Families = append(resp.Families, family)
+	}
+	return resp
+}
+
+// MetricsFromResponse 将组件返回的指标转换回 metrics.Family，标签按名称排序，
+// 名称无效的指标与名称无效的标签被丢弃
+func MetricsFromResponse(resp *pb.GetMetricsResponse) []*metrics.Family {
+	var families []*metrics.Family
+	for _, f := range resp.GetFamilies() {
+		if !metrics.ValidName(f.Name) {
+			continue
+		}
+		switch f.Type {
+		case metrics.TypeCounter, metrics.TypeGauge, metrics.TypeHistogram:
+		default:
+			continue
+		}
+		family := &metrics.Family{Name: f.Name, Help: f.Help, Type: f.Type}
+		for _, s := range f.Samples {
+			switch s.Suffix {
+			case "", "_bucket", "_sum", "_count":
+			default:
+				continue
+			}
+			sample := metrics.Sample{Suffix: s.Suffix, Value: s.Value}
+			for name, value := range s.Labels {
+				if metrics.ValidLabel(name) {
+					sample.Labels = append(sample.Labels, metrics.Label{Name: name, Value: value})
+				}
+			}
+			sort.Slice(sample.Labels, func(i, j int) bool { return sample.Labels[i].Name < sample.Labels[j].Name })
+			family.Samples = append(family.Samples, sample)
+		}
+		families = append(families, family)
+	}
+	return families
+}
@@ -0,0 +1,95 @@
+package commandbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	pb "cse-go/pkg/api/v1"
+)
+
+// Validator 是可以校验自身取值的组件设置
+type Validator interface {
+	Validate() error
+}
+
+// Settings 保存 Supervisor 通过 ComponentService.Configure 推送的组件设置。
+// T 为组件定义的设置结构，按 JSON 解析且不允许未知字段；T 实现了 Validator 时在生效前校验
+type Settings[T any] struct {
+	apply func(*T) error
+
+	// applyMu 使设置按推送顺序逐个生效
+	applyMu sync.Mutex
+
+	mu      sync.Mutex
+	current *T
+	err     error
+}
+
+// NewSettings 创建组件设置。apply 在每次收到有效的设置后调用，返回错误时设置不生效。
+// 首次调用时组件通常据此完成初始化，之后的调用来自 Supervisor 重新加载配置
+func NewSettings[T any](apply func(*T) error) *Settings[T] {
+	return &Settings[T]{apply: apply}
+}
+
+// Configure 实现 ComponentService.Configure。设置无效时返回 accepted 为 false 的响应，
+// 组件保持之前的设置并通过 Status 报告 ERROR，直到收到有效的设置
+func (s *Settings[T]) Configure(ctx context.Context, req *pb.ConfigureRequest) (*pb.ConfigureResponse, error) {
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	v, err := s.parse(req.GetSettingsJson())
+	if err == nil {
+		err = s.apply(v)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+	if err != nil {
+		return &pb.ConfigureResponse{Accepted: false, ErrorMessage: err.Error()}, nil
+	}
+	s.current = v
+	return &pb.ConfigureResponse{Accepted: true}, nil
+}
+
+// parse 解析并校验设置，未配置 settings 时使用 T 的零值
+func (s *Settings[T]) parse(data string) (*T, error) {
+	v := new(T)
+	if data != "" {
+		dec := json.NewDecoder(strings.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(v); err != nil {
+			return nil, fmt.Errorf("无法解析 settings: %w", err)
+		}
+	}
+	if validator, ok := any(v).(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, fmt.Errorf("settings 无效: %w", err)
+		}
+	}
+	return v, nil
+}
+
+// Get 返回当前生效的设置，尚未收到有效的设置时返回 nil。返回值不应被修改
+func (s *Settings[T]) Get() *T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+// Status 返回设置对应的组件状态：最近一次推送的设置无效时为 ERROR，
+// 尚未收到有效的设置时为 INITIALIZED，否则为 RUNNING
+func (s *Settings[T]) Status() (pb.ComponentState, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.err != nil:
+		return pb.ComponentState_ERROR, s.err.Error()
+	case s.current == nil:
+		return pb.ComponentState_INITIALIZED, "等待 Supervisor 推送设置"
+	}
+	return pb.ComponentState_RUNNING, ""
+}
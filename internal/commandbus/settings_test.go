@@ -0,0 +1,76 @@
+package commandbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "cse-go/pkg/api/v1"
+)
+
+type testSettings struct {
+	Port int    `json:"port"`
+	Mode string `json:"mode"`
+}
+
+func (s *testSettings) Validate() error {
+	if s.Port < 0 {
+		return errors.New("port 不能为负数")
+	}
+	return nil
+}
+
+func TestSettings(t *testing.T) {
+	var applied []*testSettings
+	settings := NewSettings(func(s *testSettings) error {
+		if s.Mode == "broken" {
+			return errors.New("无法应用")
+		}
+		applied = append(applied, s)
+		return nil
+	})
+	if state, _ := settings.Status(); state != pb.ComponentState_INITIALIZED || settings.Get() != nil {
+		t.Errorf("收到设置前应为 INITIALIZED: %v", state)
+	}
+
+	configure := func(data string) *pb.ConfigureResponse {
+		t.Helper()
+		resp, err := settings.Configure(context.Background(), &pb.ConfigureRequest{SettingsJson: data})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	if resp := configure(`{"port": 9100}`); !resp.Accepted || settings.Get().Port != 9100 {
+		t.Errorf("有效的设置应被接受: %+v", resp)
+	}
+	if state, _ := settings.Status(); state != pb.ComponentState_RUNNING {
+		t.Errorf("应用设置后应为 RUNNING: %v", state)
+	}
+
+	for data, want := range map[string]string{
+		`{"port": -1}`:        "settings 无效: port 不能为负数",
+		`{"unknown": 1}`:      `无法解析 settings: json: unknown field "unknown"`,
+		`{"mode": "broken"}`:  "无法应用",
+		`{"port": "invalid"}`: "",
+	} {
+		resp := configure(data)
+		if resp.Accepted || (want != "" && resp.ErrorMessage != want) {
+			t.Errorf("设置 %s 应被拒绝: %+v", data, resp)
+		}
+		if state, message := settings.Status(); state != pb.ComponentState_ERROR || message != resp.ErrorMessage {
+			t.Errorf("拒绝设置后应报告 ERROR: %v %s", state, message)
+		}
+		if settings.Get().Port != 9100 {
+			t.Error("拒绝设置后应保留之前的设置")
+		}
+	}
+
+	// 未配置 settings 时使用零值
+	if resp := configure(""); !resp.Accepted || settings.Get().Port != 0 || len(applied) != 2 {
+		t.Errorf("空的设置应使用零值: %+v %d", resp, len(applied))
+	}
+	if state, _ := settings.Status(); state != pb.ComponentState_RUNNING {
+		t.Errorf("再次应用有效的设置后应恢复为 RUNNING: %v", state)
+	}
+}
@@ -0,0 +1,298 @@
+// Package metrics 提供计数器、仪表与直方图，并以 Prometheus 文本格式输出。
+// Supervisor 用它实现 /metrics，组件通过 commandbus.Metrics 上报自定义指标
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 指标类型
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+var (
+	nameRe  = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// ValidName 判断指标名称是否符合 Prometheus 的命名规则
+func ValidName(name string) bool {
+	return nameRe.MatchString(name)
+}
+
+// ValidLabel 判断标签名称是否符合 Prometheus 的命名规则
+func ValidLabel(name string) bool {
+	return labelRe.MatchString(name) && !strings.HasPrefix(name, "__")
+}
+
+// Label 是一个标签
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample 是一个采样值。直方图的采样通过 Suffix 区分 _bucket、_sum 与 _count
+type Sample struct {
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+// Family 是同名指标的所有采样
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Collector 在输出时生成指标，用于组件状态等需要实时查询的数据
+type Collector func() []*Family
+
+// Registry 保存已注册的指标
+type Registry struct {
+	mu         sync.Mutex
+	vecs       []*vec
+	names      map[string]bool
+	collectors []Collector
+}
+
+// NewRegistry 创建空的注册表
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// DefBuckets 是延迟直方图默认的桶上限 (秒)
+var DefBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// vec 是一个指标及其按标签取值区分的序列
+type vec struct {
+	name, help, typ string
+	labelNames      []string
+	buckets         []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	// 直方图使用: counts[i] 为落入第 i 个桶 (不累计) 的次数
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (r *Registry) register(v *vec) *vec {
+	if !ValidName(v.name) {
+		panic(fmt.Sprintf("metrics: 无效的指标名称 '%s'", v.name))
+	}
+	for _, l := range v.labelNames {
+		if !ValidLabel(l) {
+			panic(fmt.Sprintf("metrics: 指标 '%s' 的标签名称 '%s' 无效", v.name, l))
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[v.name] {
+		panic(fmt.Sprintf("metrics: 指标 '%s' 重复注册", v.name))
+	}
+	r.names[v.name] = true
+	v.series = make(map[string]*series)
+	r.vecs = append(r.vecs, v)
+	return v
+}
+
+// with 返回标签取值对应的序列，不存在时创建
+func (v *vec) with(labelValues []string) *series {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metrics: 指标 '%s' 需要 %d 个标签取值，实际为 %d 个", v.name, len(v.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labelValues: slices.Clone(labelValues)}
+		if v.typ == TypeHistogram {
+			s.counts = make([]uint64, len(v.buckets))
+		}
+		v.series[key] = s
+	}
+	return s
+}
+
+func (v *vec) labels(s *series, extra ...Label) []Label {
+	labels := make([]Label, 0, len(v.labelNames)+len(extra))
+	for i, name := range v.labelNames {
+		labels = append(labels, Label{name, s.labelValues[i]})
+	}
+	return append(labels, extra...)
+}
+
+// snapshot 复制当前的所有序列，按标签取值排序
+func (v *vec) snapshot() *Family {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	f := &Family{Name: v.name, Help: v.help, Type: v.typ}
+	for _, k := range keys {
+		s := v.series[k]
+		if v.typ != TypeHistogram {
+			f.Samples = append(f.Samples, Sample{Labels: v.labels(s), Value: s.value})
+			continue
+		}
+		var cumulative uint64
+		for i, upper := range v.buckets {
+			cumulative += s.counts[i]
+			f.Samples = append(f.Samples, Sample{Suffix: "_bucket", Labels: v.labels(s, Label{"le", formatFloat(upper)}), Value: float64(cumulative)})
+		}
+		f.Samples = append(f.Samples,
+			Sample{Suffix: "_bucket", Labels: v.labels(s, Label{"le", "+Inf"}), Value: float64(s.count)},
+			Sample{Suffix: "_sum", Labels: v.labels(s), Value: s.sum},
+			Sample{Suffix: "_count", Labels: v.labels(s), Value: float64(s.count)},
+		)
+	}
+	return f
+}
+
+// Counter 是只增不减的计数器
+type Counter struct{ v *vec }
+
+// NewCounter 注册计数器，labelNames 为标签名称
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	return &Counter{r.register(&vec{name: name, help: help, typ: TypeCounter, labelNames: labelNames})}
+}
+
+// Inc 将标签取值对应的计数加一
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add 将标签取值对应的计数增加 delta，delta 不能为负数
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: 计数器 '%s' 不能减少", c.v.name))
+	}
+	c.v.mu.Lock()
+	c.v.with(labelValues).value += delta
+	c.v.mu.Unlock()
+}
+
+// Gauge 是可以任意设置的仪表
+type Gauge struct{ v *vec }
+
+// NewGauge 注册仪表，labelNames 为标签名称
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{r.register(&vec{name: name, help: help, typ: TypeGauge, labelNames: labelNames})}
+}
+
+// Set 设置标签取值对应的值
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.v.mu.Lock()
+	g.v.with(labelValues).value = value
+	g.v.mu.Unlock()
+}
+
+// Add 将标签取值对应的值增加 delta，delta 可以为负数
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.v.mu.Lock()
+	g.v.with(labelValues).value += delta
+	g.v.mu.Unlock()
+}
+
+// Histogram 统计观测值的分布
+type Histogram struct{ v *vec }
+
+// NewHistogram 注册直方图，buckets 为升序的桶上限，为空时使用 DefBuckets
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	if !slices.IsSorted(buckets) {
+		panic(fmt.Sprintf("metrics: 直方图 '%s' 的桶上限必须升序排列", name))
+	}
+	return &Histogram{r.register(&vec{name: name, help: help, typ: TypeHistogram, labelNames: labelNames, buckets: buckets})}
+}
+
+// Observe 记录一个观测值
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.v.mu.Lock()
+	defer h.v.mu.Unlock()
+	s := h.v.with(labelValues)
+	if i, _ := slices.BinarySearch(h.v.buckets, value); i < len(s.counts) {
+		s.counts[i]++
+	}
+	s.sum += value
+	s.count++
+}
+
+// RegisterCollector 注册在输出时调用的收集函数
+func (r *Registry) RegisterCollector(c Collector) {
+	r.mu.Lock()
+	r.collectors = append(r.collectors, c)
+	r.mu.Unlock()
+}
+
+// Gather 返回所有指标，同名的指标合并，结果按名称排序。
+// 类型不一致的同名指标只保留先出现的一个
+func (r *Registry) Gather() []*Family {
+	r.mu.Lock()
+	vecs := slices.Clone(r.vecs)
+	collectors := slices.Clone(r.collectors)
+	r.mu.Unlock()
+
+	var families []*Family
+	for _, v := range vecs {
+		families = append(families, v.snapshot())
+	}
+	for _, c := range collectors {
+		families = append(families, c()...)
+	}
+	return Merge(families)
+}
+
+// Merge 合并同名的指标并按名称排序
+func Merge(families []*Family) []*Family {
+	byName := make(map[string]*Family)
+	var merged []*Family
+	for _, f := range families {
+		if existing, ok := byName[f.Name]; ok {
+			if existing.Type == f.Type {
+				existing.Samples = append(existing.Samples, f.Samples...)
+			}
+			continue
+		}
+		copied := *f
+		copied.Samples = slices.Clone(f.Samples)
+		byName[f.Name] = &copied
+		merged = append(merged, &copied)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name < merged[j].Name })
+	return merged
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return fmt.Sprint(v)
+}
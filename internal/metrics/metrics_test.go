@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("cse_requests_total", "Requests.\nSecond line", "component", "command")
+	requests.Inc("printer", "print.testPrint")
+	requests.Add(2, "printer", `a"b\c`)
+	r.NewGauge("cse_up", "Up.").Set(1)
+	latency := r.NewHistogram("cse_latency_seconds", "Latency.", []float64{0.1, 1}, "command")
+	for _, v := range []float64{0.05, 0.1, 0.5, 3} {
+		latency.Observe(v, "x")
+	}
+	r.RegisterCollector(func() []*Family {
+		return []*Family{{Name: "cse_up", Type: TypeGauge, Samples: []Sample{{Labels: []Label{{"component", "printer"}}, Value: 0}}}}
+	})
+
+	var sb strings.Builder
+	if err := WriteText(&sb, r.Gather()); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP cse_latency_seconds Latency.
+# TYPE cse_latency_seconds histogram
+cse_latency_seconds_bucket{command="x",le="0.1"} 2
+cse_latency_seconds_bucket{command="x",le="1"} 3
+cse_latency_seconds_bucket{command="x",le="+Inf"} 4
+cse_latency_seconds_sum{command="x"} 3.65
+cse_latency_seconds_count{command="x"} 4
+# HELP cse_requests_total Requests.\nSecond line
+# TYPE cse_requests_total counter
+cse_requests_total{component="printer",command="a\"b\\c"} 2
+cse_requests_total{component="printer",command="print.testPrint"} 1
+# HELP cse_up Up.
+# TYPE cse_up gauge
+cse_up 1
+cse_up{component="printer"} 0
+`
+	if sb.String() != want {
+		t.Errorf("输出不正确:\n%s\n期望:\n%s", sb.String(), want)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	for name, register := range map[string]func(r *Registry){
+		"无效名称": func(r *Registry) { r.NewCounter("bad-name", "") },
+		"无效标签": func(r *Registry) { r.NewCounter("ok", "", "__reserved") },
+		"重复注册": func(r *Registry) { r.NewCounter("dup", ""); r.NewGauge("dup", "") },
+		"标签数量": func(r *Registry) { r.NewCounter("c", "", "a").Inc() },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s 应引发 panic", name)
+				}
+			}()
+			register(NewRegistry())
+		}()
+	}
+}
@@ -0,0 +1,146 @@
+// Package mtls 提供 Supervisor 与组件之间双向 TLS 连接的配置。
+// Supervisor 作为 CA 为每个组件签发短期证书，证书的 CommonName 为组件名称，
+// Supervisor 自身证书的 CommonName 为 SupervisorName；双方都校验对端证书的名称
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// SupervisorName 是 Supervisor 证书的 CommonName
+const SupervisorName = "cse-supervisor"
+
+// 组件接收证书文件路径的命令行参数
+const (
+	FlagCA   = "tls-ca"
+	FlagCert = "tls-cert"
+	FlagKey  = "tls-key"
+)
+
+// Files 是组件的 CA 证书、组件证书与私钥的文件路径
+type Files struct {
+	CA   string
+	Cert string
+	Key  string
+}
+
+// Args 返回传递给组件进程的命令行参数
+func (f Files) Args() []string {
+	return []string{"--" + FlagCA + "=" + f.CA, "--" + FlagCert + "=" + f.Cert, "--" + FlagKey + "=" + f.Key}
+}
+
+// Complete 判断三个文件路径是否都已提供
+func (f Files) Complete() bool {
+	return f.CA != "" && f.Cert != "" && f.Key != ""
+}
+
+// VerifyPeerName 返回校验对端证书 CommonName 的 tls.Config.VerifyConnection 回调。
+// 证书链已由 TLS 握手按 CA 校验，这里只确认对端身份
+func VerifyPeerName(name string) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("对端未提供证书")
+		}
+		if cn := cs.PeerCertificates[0].Subject.CommonName; cn != name {
+			return fmt.Errorf("对端证书名称为 '%s'，期望 '%s'", cn, name)
+		}
+		return nil
+	}
+}
+
+// PeerName 返回已校验的对端证书的 CommonName
+func PeerName(cs tls.ConnectionState) string {
+	if len(cs.VerifiedChains) == 0 || len(cs.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return cs.VerifiedChains[0][0].Subject.CommonName
+}
+
+// ComponentServerConfig 返回组件 gRPC 服务使用的配置：要求客户端提供由 CA 签发的 Supervisor 证书
+func ComponentServerConfig(f Files) (*tls.Config, error) {
+	pool, reloader, err := load(f)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS13,
+		ClientAuth:       tls.RequireAndVerifyClientCert,
+		ClientCAs:        pool,
+		GetCertificate:   func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return reloader.get() },
+		VerifyConnection: VerifyPeerName(SupervisorName),
+	}, nil
+}
+
+// ComponentClientConfig 返回组件连接 Supervisor 发现服务时使用的配置
+func ComponentClientConfig(f Files) (*tls.Config, error) {
+	pool, reloader, err := load(f)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:           tls.VersionTLS13,
+		RootCAs:              pool,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) { return reloader.get() },
+		VerifyConnection:     VerifyPeerName(SupervisorName),
+	}, nil
+}
+
+func load(f Files) (*x509.CertPool, *keyPairReloader, error) {
+	if !f.Complete() {
+		return nil, nil, errors.New("缺少 TLS 证书文件参数")
+	}
+	caPEM, err := os.ReadFile(f.CA)
+	if err != nil {
+		return nil, nil, fmt.Errorf("无法读取 CA 证书: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, nil, fmt.Errorf("'%s' 中没有有效的 CA 证书", f.CA)
+	}
+	reloader := &keyPairReloader{certFile: f.Cert, keyFile: f.Key}
+	if _, err := reloader.get(); err != nil {
+		return nil, nil, err
+	}
+	return pool, reloader, nil
+}
+
+// keyPairReloader 在证书文件更新后重新加载，使 Supervisor 续签的证书无需重启组件即可生效
+type keyPairReloader struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func (r *keyPairReloader) get() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fi, err := os.Stat(r.certFile)
+	if err != nil {
+		if r.cert != nil {
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("无法读取证书: %w", err)
+	}
+	if r.cert != nil && fi.ModTime().Equal(r.modTime) {
+		return r.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		// 续签写入过程中可能读到不完整的文件，继续使用旧证书
+		if r.cert != nil {
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("无法加载证书: %w", err)
+	}
+	r.cert = &cert
+	r.modTime = fi.ModTime()
+	return r.cert, nil
+}
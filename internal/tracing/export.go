@@ -0,0 +1,162 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// exporter 为当前进程使用的导出器，为 nil 时不导出 span
+var exporter atomic.Pointer[FileExporter]
+
+// SetExporter 设置结束的 span 的导出器，传入 nil 时停止导出
+func SetExporter(e *FileExporter) {
+	exporter.Store(e)
+}
+
+// FileExporter 将结束的 span 追加写入文件。每行是一个 OTLP JSON 格式的
+// ExportTraceServiceRequest，与 OpenTelemetry Collector 的 file 导出格式相同
+type FileExporter struct {
+	mu       sync.Mutex
+	f        *os.File
+	resource resource
+}
+
+// NewFileExporter 打开 (或创建) path 用于追加写入 span，service 为记录在 span 中的服务名称
+func NewFileExporter(path, service string) (*FileExporter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("无法创建 span 文件目录: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开 span 文件: %w", err)
+	}
+	return &FileExporter{
+		f: f,
+		resource: resource{Attributes: []keyValue{
+			{Key: "service.name", Value: anyValue(service)},
+			{Key: "process.pid", Value: anyValue(os.Getpid())},
+		}},
+	}, nil
+}
+
+// Close 关闭 span 文件
+func (e *FileExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.f.Close()
+}
+
+func (e *FileExporter) export(s *Span) {
+	line, err := json.Marshal(exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   e.resource,
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: "cse-go"}, Spans: []span{s.otlp()}}},
+	}}})
+	if err != nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.f.Write(append(line, '\n'))
+}
+
+// otlp 将 span 转换为 OTLP JSON 结构
+func (s *Span) otlp() span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := span{
+		TraceID:           s.sc.TraceID.String(),
+		SpanID:            s.sc.SpanID.String(),
+		Name:              s.name,
+		Kind:              int(s.kind),
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Attributes:        s.attrs,
+	}
+	if s.parent.IsValid() {
+		out.ParentSpanID = s.parent.String()
+	}
+	if s.failed {
+		out.Status = status{Code: statusError, Message: s.message}
+	}
+	return out
+}
+
+// 以下为 OTLP JSON 编码 (https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding) 的结构，
+// 标识使用十六进制字符串，64 位整数使用十进制字符串
+
+const statusError = 2
+
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope  `json:"scope"`
+	Spans []span `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string `json:"key"`
+	Value value  `json:"value"`
+}
+
+type value struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+// anyValue 将属性值转换为 OTLP 的 AnyValue
+func anyValue(v any) value {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case int:
+		s = strconv.Itoa(v)
+		return value{IntValue: &s}
+	case int64:
+		s = strconv.FormatInt(v, 10)
+		return value{IntValue: &s}
+	case bool:
+		return value{BoolValue: &v}
+	default:
+		s = fmt.Sprint(v)
+	}
+	return value{StringValue: &s}
+}
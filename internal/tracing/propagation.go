@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// HTTP 请求头与 gRPC 元数据中使用的键
+const (
+	HeaderRequestID   = "X-Request-ID"
+	HeaderTraceparent = "traceparent"
+
+	metadataRequestID   = "x-request-id"
+	metadataTraceparent = "traceparent"
+)
+
+// FlagTraceFile 是 Supervisor 向组件传递 span 文件路径的命令行参数
+const FlagTraceFile = "trace-file"
+
+// Inject 将上下文中的请求 ID 与当前 span 附加到发出的 gRPC 请求元数据中
+func Inject(ctx context.Context) context.Context {
+	var kv []string
+	if id := RequestID(ctx); id != "" {
+		kv = append(kv, metadataRequestID, id)
+	}
+	if sc := SpanContextFromContext(ctx); sc.IsValid() {
+		kv = append(kv, metadataTraceparent, sc.Traceparent())
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// Extract 从收到的 gRPC 请求元数据中读取请求 ID 与上游 span，放入返回的上下文
+func Extract(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if values := md.Get(metadataRequestID); len(values) > 0 && ValidRequestID(values[0]) {
+		ctx = ContextWithRequestID(ctx, values[0])
+	}
+	if values := md.Get(metadataTraceparent); len(values) > 0 {
+		if sc, ok := ParseTraceparent(values[0]); ok {
+			ctx = ContextWithRemoteSpanContext(ctx, sc)
+		}
+	}
+	return ctx
+}
@@ -0,0 +1,225 @@
+// Package tracing 为每个请求确定请求 ID 与 W3C Trace Context，记录请求经过 Supervisor
+// 与组件时的 span，并以 OTLP JSON 格式导出到文件，用于关联 HTTP 请求、gRPC 调用与各进程的日志
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID 与 SpanID 是 W3C Trace Context 中的调用链与 span 标识
+type (
+	TraceID [16]byte
+	SpanID  [8]byte
+)
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// IsValid 判断标识是否非零
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+func (s SpanID) IsValid() bool  { return s != SpanID{} }
+
+// FlagSampled 表示该调用链的 span 需要被记录
+const FlagSampled byte = 0x01
+
+// SpanContext 是在进程间传递的 span 标识
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+}
+
+// IsValid 判断 TraceID 与 SpanID 是否都非零
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Sampled 判断该 span 是否需要被记录
+func (sc SpanContext) Sampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent 返回 W3C traceparent 头的值
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent 解析 W3C traceparent 头，格式无效时返回 false
+func ParseTraceparent(value string) (SpanContext, bool) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return sc, false
+	}
+	// 版本 00 只有四个字段，更高版本可以在末尾追加字段
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, false
+	}
+	var version, flags [1]byte
+	if !decodeHex(version[:], parts[0]) || !decodeHex(sc.TraceID[:], parts[1]) ||
+		!decodeHex(sc.SpanID[:], parts[2]) || !decodeHex(flags[:], parts[3]) {
+		return sc, false
+	}
+	sc.Flags = flags[0]
+	return sc, sc.IsValid()
+}
+
+// decodeHex 将小写十六进制字符串解码到 dst，长度必须恰好匹配
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != 2*len(dst) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+// SpanKind 是 span 在调用中的角色，取值与 OTLP 一致
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// Span 记录一次操作的起止时间、属性与结果。所有方法都可以在 nil 上调用
+type Span struct {
+	name   string
+	kind   SpanKind
+	sc     SpanContext
+	parent SpanID
+	start  time.Time
+
+	mu      sync.Mutex
+	end     time.Time
+	attrs   []keyValue
+	failed  bool
+	message string
+}
+
+// Start 创建一个以上下文中的 span (或上游传入的 span) 为父 span 的新 span，
+// 没有父 span 时开始一个新的调用链。返回的上下文携带新的 span
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{Flags: FlagSampled}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Flags = parent.Flags
+	} else {
+		rand.Read(sc.TraceID[:])
+	}
+	rand.Read(sc.SpanID[:])
+
+	s := &Span{name: name, kind: kind, sc: sc, parent: parent.SpanID, start: time.Now()}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// SpanContext 返回 span 的标识
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetAttribute 设置 span 的属性，value 可以是字符串、整数或布尔值，其他类型按字符串记录
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, keyValue{Key: key, Value: anyValue(value)})
+}
+
+// SetError 将 span 标记为失败
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = true
+	s.message = message
+}
+
+// End 结束 span 并导出，重复调用无效
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.end.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if e := exporter.Load(); e != nil && s.sc.Sampled() {
+		e.export(s)
+	}
+}
+
+type (
+	spanKey       struct{}
+	remoteSpanKey struct{}
+	requestIDKey  struct{}
+)
+
+// SpanFromContext 返回上下文中由当前进程创建的 span，没有时返回 nil
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// SpanContextFromContext 返回上下文中当前 span 的标识，没有本地 span 时返回上游传入的标识
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if s := SpanFromContext(ctx); s != nil {
+		return s.sc
+	}
+	sc, _ := ctx.Value(remoteSpanKey{}).(SpanContext)
+	return sc
+}
+
+// ContextWithRemoteSpanContext 将上游传入的 span 标识放入上下文，之后创建的 span 以其为父 span
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteSpanKey{}, sc)
+}
+
+// ContextWithRequestID 将请求 ID 放入上下文
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID 返回上下文中的请求 ID，没有时返回空字符串
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID 生成一个随机的请求 ID
+func NewRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ValidRequestID 判断调用方提供的请求 ID 是否可用: 1 到 128 个可见 ASCII 字符
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := ParseTraceparent(valid)
+	if !ok || sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.Sampled() {
+		t.Fatalf("解析结果不正确: %+v %v", sc, ok)
+	}
+	if sc.Traceparent() != valid {
+		t.Errorf("Traceparent() = %s", sc.Traceparent())
+	}
+	if _, ok := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); !ok {
+		t.Error("更高版本允许追加字段")
+	}
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+	} {
+		if _, ok := ParseTraceparent(invalid); ok {
+			t.Errorf("%q 应无效", invalid)
+		}
+	}
+}
+
+func TestSpanExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	e, err := NewFileExporter(path, "test-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetExporter(e)
+	defer SetExporter(nil)
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithRemoteSpanContext(context.Background(), remote)
+	ctx, server := Start(ctx, "server", KindServer)
+	_, client := Start(ctx, "client", KindClient)
+	client.SetAttribute("count", 3)
+	client.SetError("failed")
+	client.End()
+	client.End()
+	server.End()
+
+	// 未采样的调用链不导出
+	unsampled := remote
+	unsampled.Flags = 0
+	_, s := Start(ContextWithRemoteSpanContext(context.Background(), unsampled), "unsampled", KindInternal)
+	s.End()
+	e.Close()
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("应导出 2 个 span，实际 %d", len(lines))
+	}
+	var spans []span
+	for _, line := range lines {
+		var req exportRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			t.Fatal(err)
+		}
+		if *req.ResourceSpans[0].Resource.Attributes[0].Value.StringValue != "test-service" {
+			t.Errorf("服务名称不正确: %s", line)
+		}
+		spans = append(spans, req.ResourceSpans[0].ScopeSpans[0].Spans[0])
+	}
+	c, srv := spans[0], spans[1]
+	if srv.TraceID != remote.TraceID.String() || srv.ParentSpanID != remote.SpanID.String() || srv.Kind != int(KindServer) {
+		t.Errorf("服务端 span 应继承上游调用链: %+v", srv)
+	}
+	if c.TraceID != srv.TraceID || c.ParentSpanID != srv.SpanID {
+		t.Errorf("客户端 span 的父 span 不正确: %+v", c)
+	}
+	if c.Status.Code != statusError || c.Status.Message != "failed" || *c.Attributes[0].Value.IntValue != "3" {
+		t.Errorf("客户端 span 的状态或属性不正确: %+v", c)
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	ctx, s := Start(ctx, "client", KindClient)
+	md, _ := metadata.FromOutgoingContext(Inject(ctx))
+
+	received := Extract(metadata.NewIncomingContext(context.Background(), md))
+	if RequestID(received) != "req-1" {
+		t.Errorf("请求 ID 未传递: %q", RequestID(received))
+	}
+	if SpanContextFromContext(received) != s.SpanContext() {
+		t.Errorf("span 未传递: %+v", SpanContextFromContext(received))
+	}
+
+	md = metadata.Pairs(metadataRequestID, "bad id", metadataTraceparent, "invalid")
+	received = Extract(metadata.NewIncomingContext(context.Background(), md))
+	if RequestID(received) != "" || SpanContextFromContext(received).IsValid() {
+		t.Error("无效的元数据应被忽略")
+	}
+}
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	ctx, s := Start(ContextWithRequestID(context.Background(), "req-1"), "op", KindInternal)
+	logger.InfoContext(ctx, "with context")
+	logger.Info("without context")
+
+	scanner := bufio.NewScanner(&buf)
+	var records []map[string]any
+	for scanner.Scan() {
+		var record map[string]any
+		json.Unmarshal(scanner.Bytes(), &record)
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("应输出 2 条日志，实际 %d", len(records))
+	}
+	if records[0]["request_id"] != "req-1" || records[0]["trace_id"] != s.SpanContext().TraceID.String() ||
+		records[0]["span_id"] != s.SpanContext().SpanID.String() || records[0]["component"] != "test" {
+		t.Errorf("日志缺少关联字段: %v", records[0])
+	}
+	if _, ok := records[1]["request_id"]; ok {
+		t.Errorf("没有上下文的日志不应包含请求 ID: %v", records[1])
+	}
+}
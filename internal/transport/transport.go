@@ -0,0 +1,95 @@
+// Package transport 处理 Supervisor 与组件之间 gRPC 服务的监听地址。
+// 地址可以是 TCP 的 host:port，也可以是 unix:// 开头的 Unix 域套接字路径
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+)
+
+const unixScheme = "unix:"
+
+// IsUnix 判断地址是否为 Unix 域套接字地址
+func IsUnix(addr string) bool {
+	return strings.HasPrefix(addr, unixScheme)
+}
+
+// UnixAddress 返回套接字文件对应的 gRPC 地址。绝对路径使用 unix:///path 形式，
+// Windows 路径 (如 C:\...) 使用 unix:C:\... 形式，避免盘符被当作主机名
+func UnixAddress(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return unixScheme + "//" + path
+	}
+	return unixScheme + path
+}
+
+// SocketPath 返回 Unix 域套接字地址中的文件路径，不是 Unix 域套接字地址时返回空字符串
+func SocketPath(addr string) string {
+	if !IsUnix(addr) {
+		return ""
+	}
+	return unixPath(addr)
+}
+
+// unixPath 返回 Unix 域套接字地址中的文件路径
+func unixPath(addr string) string {
+	path := strings.TrimPrefix(addr, unixScheme)
+	return strings.TrimPrefix(path, "//")
+}
+
+// Listen 在地址上监听。Unix 域套接字会替换残留的套接字文件，并设置为只有当前用户可以连接
+func Listen(addr string) (net.Listener, error) {
+	if !IsUnix(addr) {
+		return net.Listen("tcp", addr)
+	}
+	path := unixPath(addr)
+	if path == "" {
+		return nil, fmt.Errorf("地址 '%s' 缺少套接字路径", addr)
+	}
+	// 进程异常退出后套接字文件会残留，导致无法再次监听
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("'%s' 已存在且不是套接字", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("无法删除残留的套接字 '%s': %w", path, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		lis.Close()
+		return nil, fmt.Errorf("无法设置套接字权限: %w", err)
+	}
+	return lis, nil
+}
+
+// Address 返回监听器对应的 gRPC 地址，用于向 Supervisor 注册
+func Address(lis net.Listener) string {
+	if addr, ok := lis.Addr().(*net.UnixAddr); ok {
+		return UnixAddress(addr.Name)
+	}
+	return lis.Addr().String()
+}
+
+// Validate 检查地址格式是否为 host:port 或 Unix 域套接字地址
+func Validate(addr string) error {
+	if IsUnix(addr) {
+		if unixPath(addr) == "" {
+			return fmt.Errorf("地址 '%s' 缺少套接字路径", addr)
+		}
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return fmt.Errorf("地址 '%s' 无效: %w", addr, err)
+	}
+	return nil
+}
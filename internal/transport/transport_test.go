@@ -0,0 +1,65 @@
+package transport
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestUnixAddress(t *testing.T) {
+	tests := []struct{ path, addr string }{
+		{"/run/cse/printer.sock", "unix:///run/cse/printer.sock"},
+		{`C:\cse\printer.sock`, `unix:C:\cse\printer.sock`},
+	}
+	for _, tt := range tests {
+		addr := UnixAddress(tt.path)
+		if addr != tt.addr || !IsUnix(addr) || unixPath(addr) != tt.path {
+			t.Errorf("UnixAddress(%q) = %q，解析出的路径为 %q", tt.path, addr, unixPath(addr))
+		}
+	}
+	for _, addr := range []string{"127.0.0.1:0", "unix:///tmp/a.sock"} {
+		if err := Validate(addr); err != nil {
+			t.Errorf("地址 %s 应有效: %v", addr, err)
+		}
+	}
+	for _, addr := range []string{"unix://", "localhost"} {
+		if Validate(addr) == nil {
+			t.Errorf("地址 %s 应无效", addr)
+		}
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.sock")
+	addr := UnixAddress(path)
+	lis, err := Listen(addr)
+	if err != nil {
+		t.Fatalf("监听 Unix 域套接字失败: %v", err)
+	}
+	if Address(lis) != addr {
+		t.Errorf("监听地址为 %s，期望 %s", Address(lis), addr)
+	}
+	if runtime.GOOS != "windows" {
+		if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+			t.Errorf("套接字权限应为 0600: %v", err)
+		}
+	}
+
+	// 模拟进程异常退出后残留的套接字文件
+	if ul, ok := lis.(interface{ SetUnlinkOnClose(bool) }); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+	lis.Close()
+	lis, err = Listen(addr)
+	if err != nil {
+		t.Fatalf("应替换残留的套接字: %v", err)
+	}
+	lis.Close()
+
+	regular := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(regular, nil, 0o644)
+	if _, err := Listen(UnixAddress(regular)); err == nil {
+		t.Error("不应替换普通文件")
+	}
+}
@@ -665,6 +665,320 @@ func (x *ShutdownResponse) GetMessage() string {
 	return ""
 }
 
+// GetMetrics 方法的请求体 (空)
+type GetMetricsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMetricsRequest) Reset() {
+	*x = GetMetricsRequest{}
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMetricsRequest) ProtoMessage() {}
+
+func (x *GetMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMetricsRequest.ProtoReflect.Descriptor instead.
+func (*GetMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{11}
+}
+
+// 一个指标的所有采样
+type MetricFamily struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Help  string                 `protobuf:"bytes,2,opt,name=help,proto3" json:"help,omitempty"`
+	// counter、gauge 或 histogram
+	Type          string          `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Samples       []*MetricSample `protobuf:"bytes,4,rep,name=samples,proto3" json:"samples,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricFamily) Reset() {
+	*x = MetricFamily{}
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricFamily) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricFamily) ProtoMessage() {}
+
+func (x *MetricFamily) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricFamily.ProtoReflect.Descriptor instead.
+func (*MetricFamily) Descriptor() ([]byte, []int) {
+	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MetricFamily) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MetricFamily) GetHelp() string {
+	if x != nil {
+		return x.Help
+	}
+	return ""
+}
+
+func (x *MetricFamily) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *MetricFamily) GetSamples() []*MetricSample {
+	if x != nil {
+		return x.Samples
+	}
+	return nil
+}
+
+// 单个采样，直方图的采样通过 suffix 区分 _bucket、_sum 与 _count
+type MetricSample struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Suffix        string                 `protobuf:"bytes,1,opt,name=suffix,proto3" json:"suffix,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Value         float64                `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricSample) Reset() {
+	*x = MetricSample{}
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricSample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricSample) ProtoMessage() {}
+
+func (x *MetricSample) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricSample.ProtoReflect.Descriptor instead.
+func (*MetricSample) Descriptor() ([]byte, []int) {
+	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MetricSample) GetSuffix() string {
+	if x != nil {
+		return x.Suffix
+	}
+	return ""
+}
+
+func (x *MetricSample) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *MetricSample) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+// GetMetrics 方法的响应体
+type GetMetricsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Families      []*MetricFamily        `protobuf:"bytes,1,rep,name=families,proto3" json:"families,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMetricsResponse) Reset() {
+	*x = GetMetricsResponse{}
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMetricsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMetricsResponse) ProtoMessage() {}
+
+func (x *GetMetricsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMetricsResponse.ProtoReflect.Descriptor instead.
+func (*GetMetricsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetMetricsResponse) GetFamilies() []*MetricFamily {
+	if x != nil {
+		return x.Families
+	}
+	return nil
+}
+
+// Configure 方法的请求体
+type ConfigureRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 组件配置中 settings 对象的 JSON，未配置时为空
+	SettingsJson  string `protobuf:"bytes,1,opt,name=settings_json,json=settingsJson,proto3" json:"settings_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigureRequest) Reset() {
+	*x = ConfigureRequest{}
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigureRequest) ProtoMessage() {}
+
+func (x *ConfigureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigureRequest.ProtoReflect.Descriptor instead.
+func (*ConfigureRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ConfigureRequest) GetSettingsJson() string {
+	if x != nil {
+		return x.SettingsJson
+	}
+	return ""
+}
+
+// Configure 方法的响应体
+type ConfigureResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 设置是否有效并已生效，无效时组件保持之前的设置并报告 ERROR 状态
+	Accepted bool `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	// 设置无效的原因
+	ErrorMessage  string `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigureResponse) Reset() {
+	*x = ConfigureResponse{}
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigureResponse) ProtoMessage() {}
+
+func (x *ConfigureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigureResponse.ProtoReflect.Descriptor instead.
+func (*ConfigureResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ConfigureResponse) GetAccepted() bool {
+	if x != nil {
+		return x.Accepted
+	}
+	return false
+}
+
+func (x *ConfigureResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
 type UpdateNotificationRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	ComponentName  string                 `protobuf:"bytes,1,opt,name=component_name,json=componentName,proto3" json:"component_name,omitempty"`
@@ -676,7 +990,7 @@ type UpdateNotificationRequest struct {
 
 func (x *UpdateNotificationRequest) Reset() {
 	*x = UpdateNotificationRequest{}
-	mi := &file_pkg_api_v1_cse_proto_msgTypes[11]
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -688,7 +1002,7 @@ func (x *UpdateNotificationRequest) String() string {
 func (*UpdateNotificationRequest) ProtoMessage() {}
 
 func (x *UpdateNotificationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_cse_proto_msgTypes[11]
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -701,7 +1015,7 @@ func (x *UpdateNotificationRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateNotificationRequest.ProtoReflect.Descriptor instead.
 func (*UpdateNotificationRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{11}
+	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *UpdateNotificationRequest) GetComponentName() string {
@@ -735,7 +1049,7 @@ type UpdateNotificationResponse struct {
 
 func (x *UpdateNotificationResponse) Reset() {
 	*x = UpdateNotificationResponse{}
-	mi := &file_pkg_api_v1_cse_proto_msgTypes[12]
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -747,7 +1061,7 @@ func (x *UpdateNotificationResponse) String() string {
 func (*UpdateNotificationResponse) ProtoMessage() {}
 
 func (x *UpdateNotificationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_cse_proto_msgTypes[12]
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -760,7 +1074,7 @@ func (x *UpdateNotificationResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateNotificationResponse.ProtoReflect.Descriptor instead.
 func (*UpdateNotificationResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{12}
+	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *UpdateNotificationResponse) GetAcknowledged() bool {
@@ -786,7 +1100,7 @@ type ComponentVersionRequest struct {
 
 func (x *ComponentVersionRequest) Reset() {
 	*x = ComponentVersionRequest{}
-	mi := &file_pkg_api_v1_cse_proto_msgTypes[13]
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -798,7 +1112,7 @@ func (x *ComponentVersionRequest) String() string {
 func (*ComponentVersionRequest) ProtoMessage() {}
 
 func (x *ComponentVersionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_cse_proto_msgTypes[13]
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -811,7 +1125,7 @@ func (x *ComponentVersionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ComponentVersionRequest.ProtoReflect.Descriptor instead.
 func (*ComponentVersionRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{13}
+	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *ComponentVersionRequest) GetComponentName() string {
@@ -831,7 +1145,7 @@ type ComponentVersionResponse struct {
 
 func (x *ComponentVersionResponse) Reset() {
 	*x = ComponentVersionResponse{}
-	mi := &file_pkg_api_v1_cse_proto_msgTypes[14]
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -843,7 +1157,7 @@ func (x *ComponentVersionResponse) String() string {
 func (*ComponentVersionResponse) ProtoMessage() {}
 
 func (x *ComponentVersionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_cse_proto_msgTypes[14]
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -856,7 +1170,7 @@ func (x *ComponentVersionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ComponentVersionResponse.ProtoReflect.Descriptor instead.
 func (*ComponentVersionResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{14}
+	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *ComponentVersionResponse) GetVersion() string {
@@ -874,17 +1188,18 @@ func (x *ComponentVersionResponse) GetFound() bool {
 }
 
 type RegisterComponentRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	GrpcAddress   string                 `protobuf:"bytes,2,opt,name=grpc_address,json=grpcAddress,proto3" json:"grpc_address,omitempty"`
-	Pid           int32                  `protobuf:"varint,3,opt,name=pid,proto3" json:"pid,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// 组件 gRPC 服务的地址: host:port 或 unix:///path/to/socket
+	GrpcAddress   string `protobuf:"bytes,2,opt,name=grpc_address,json=grpcAddress,proto3" json:"grpc_address,omitempty"`
+	Pid           int32  `protobuf:"varint,3,opt,name=pid,proto3" json:"pid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RegisterComponentRequest) Reset() {
 	*x = RegisterComponentRequest{}
-	mi := &file_pkg_api_v1_cse_proto_msgTypes[15]
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -896,7 +1211,7 @@ func (x *RegisterComponentRequest) String() string {
 func (*RegisterComponentRequest) ProtoMessage() {}
 
 func (x *RegisterComponentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_cse_proto_msgTypes[15]
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -909,7 +1224,7 @@ func (x *RegisterComponentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RegisterComponentRequest.ProtoReflect.Descriptor instead.
 func (*RegisterComponentRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{15}
+	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *RegisterComponentRequest) GetName() string {
@@ -943,7 +1258,7 @@ type RegisterComponentResponse struct {
 
 func (x *RegisterComponentResponse) Reset() {
 	*x = RegisterComponentResponse{}
-	mi := &file_pkg_api_v1_cse_proto_msgTypes[16]
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -955,7 +1270,7 @@ func (x *RegisterComponentResponse) String() string {
 func (*RegisterComponentResponse) ProtoMessage() {}
 
 func (x *RegisterComponentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_cse_proto_msgTypes[16]
+	mi := &file_pkg_api_v1_cse_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -968,7 +1283,7 @@ func (x *RegisterComponentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RegisterComponentResponse.ProtoReflect.Descriptor instead.
 func (*RegisterComponentResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{16}
+	return file_pkg_api_v1_cse_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *RegisterComponentResponse) GetSuccess() bool {
@@ -1020,7 +1335,27 @@ const file_pkg_api_v1_cse_proto_rawDesc = "" +
 	"\x0fShutdownRequest\"P\n" +
 	"\x10ShutdownResponse\x12\"\n" +
 	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\x88\x01\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x13\n" +
+	"\x11GetMetricsRequest\"v\n" +
+	"\fMetricFamily\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04help\x18\x02 \x01(\tR\x04help\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12*\n" +
+	"\asamples\x18\x04 \x03(\v2\x10.v1.MetricSampleR\asamples\"\xad\x01\n" +
+	"\fMetricSample\x12\x16\n" +
+	"\x06suffix\x18\x01 \x01(\tR\x06suffix\x124\n" +
+	"\x06labels\x18\x02 \x03(\v2\x1c.v1.MetricSample.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\x01R\x05value\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"B\n" +
+	"\x12GetMetricsResponse\x12,\n" +
+	"\bfamilies\x18\x01 \x03(\v2\x10.v1.MetricFamilyR\bfamilies\"7\n" +
+	"\x10ConfigureRequest\x12#\n" +
+	"\rsettings_json\x18\x01 \x01(\tR\fsettingsJson\"T\n" +
+	"\x11ConfigureResponse\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\bR\baccepted\x12#\n" +
+	"\rerror_message\x18\x02 \x01(\tR\ferrorMessage\"\x88\x01\n" +
 	"\x19UpdateNotificationRequest\x12%\n" +
 	"\x0ecomponent_name\x18\x01 \x01(\tR\rcomponentName\x12(\n" +
 	"\x10new_version_path\x18\x02 \x01(\tR\x0enewVersionPath\x12\x1a\n" +
@@ -1051,12 +1386,15 @@ const file_pkg_api_v1_cse_proto_rawDesc = "" +
 	"\bUPDATING\x10\x05\x12\r\n" +
 	"\tUNLOADING\x10\x06\x12\f\n" +
 	"\bUNLOADED\x10\a\x12\t\n" +
-	"\x05ERROR\x10\b2\x92\x02\n" +
+	"\x05ERROR\x10\b2\x8d\x03\n" +
 	"\x10ComponentService\x12I\n" +
 	"\x0eExecuteCommand\x12\x19.v1.ExecuteCommandRequest\x1a\x1a.v1.ExecuteCommandResponse\"\x00\x12>\n" +
 	"\vGetMetadata\x12\x16.v1.GetMetadataRequest\x1a\x15.v1.ComponentMetadata\"\x00\x12:\n" +
 	"\tGetStatus\x12\x14.v1.GetStatusRequest\x1a\x15.v1.GetStatusResponse\"\x00\x127\n" +
-	"\bShutdown\x12\x13.v1.ShutdownRequest\x1a\x14.v1.ShutdownResponse\"\x002\xca\x01\n" +
+	"\bShutdown\x12\x13.v1.ShutdownRequest\x1a\x14.v1.ShutdownResponse\"\x00\x12=\n" +
+	"\n" +
+	"GetMetrics\x12\x15.v1.GetMetricsRequest\x1a\x16.v1.GetMetricsResponse\"\x00\x12:\n" +
+	"\tConfigure\x12\x14.v1.ConfigureRequest\x1a\x15.v1.ConfigureResponse\"\x002\xca\x01\n" +
 	"\x1aUpdaterNotificationService\x12X\n" +
 	"\x15NotifyUpdateAvailable\x12\x1d.v1.UpdateNotificationRequest\x1a\x1e.v1.UpdateNotificationResponse\"\x00\x12R\n" +
 	"\x13GetComponentVersion\x12\x1b.v1.ComponentVersionRequest\x1a\x1c.v1.ComponentVersionResponse\"\x002o\n" +
@@ -1076,7 +1414,7 @@ func file_pkg_api_v1_cse_proto_rawDescGZIP() []byte {
 }
 
 var file_pkg_api_v1_cse_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_pkg_api_v1_cse_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_pkg_api_v1_cse_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_pkg_api_v1_cse_proto_goTypes = []any{
 	(ComponentState)(0),                // 0: v1.ComponentState
 	(*CommandParams)(nil),              // 1: v1.CommandParams
@@ -1090,37 +1428,51 @@ var file_pkg_api_v1_cse_proto_goTypes = []any{
 	(*GetStatusResponse)(nil),          // 9: v1.GetStatusResponse
 	(*ShutdownRequest)(nil),            // 10: v1.ShutdownRequest
 	(*ShutdownResponse)(nil),           // 11: v1.ShutdownResponse
-	(*UpdateNotificationRequest)(nil),  // 12: v1.UpdateNotificationRequest
-	(*UpdateNotificationResponse)(nil), // 13: v1.UpdateNotificationResponse
-	(*ComponentVersionRequest)(nil),    // 14: v1.ComponentVersionRequest
-	(*ComponentVersionResponse)(nil),   // 15: v1.ComponentVersionResponse
-	(*RegisterComponentRequest)(nil),   // 16: v1.RegisterComponentRequest
-	(*RegisterComponentResponse)(nil),  // 17: v1.RegisterComponentResponse
+	(*GetMetricsRequest)(nil),          // 12: v1.GetMetricsRequest
+	(*MetricFamily)(nil),               // 13: v1.MetricFamily
+	(*MetricSample)(nil),               // 14: v1.MetricSample
+	(*GetMetricsResponse)(nil),         // 15: v1.GetMetricsResponse
+	(*ConfigureRequest)(nil),           // 16: v1.ConfigureRequest
+	(*ConfigureResponse)(nil),          // 17: v1.ConfigureResponse
+	(*UpdateNotificationRequest)(nil),  // 18: v1.UpdateNotificationRequest
+	(*UpdateNotificationResponse)(nil), // 19: v1.UpdateNotificationResponse
+	(*ComponentVersionRequest)(nil),    // 20: v1.ComponentVersionRequest
+	(*ComponentVersionResponse)(nil),   // 21: v1.ComponentVersionResponse
+	(*RegisterComponentRequest)(nil),   // 22: v1.RegisterComponentRequest
+	(*RegisterComponentResponse)(nil),  // 23: v1.RegisterComponentResponse
+	nil,                                // 24: v1.MetricSample.LabelsEntry
 }
 var file_pkg_api_v1_cse_proto_depIdxs = []int32{
 	1,  // 0: v1.ExecuteCommandRequest.params:type_name -> v1.CommandParams
 	2,  // 1: v1.ExecuteCommandResponse.result:type_name -> v1.CommandResult
 	7,  // 2: v1.ComponentMetadata.provided_commands:type_name -> v1.CommandInfo
 	0,  // 3: v1.GetStatusResponse.current_state:type_name -> v1.ComponentState
-	3,  // 4: v1.ComponentService.ExecuteCommand:input_type -> v1.ExecuteCommandRequest
-	5,  // 5: v1.ComponentService.GetMetadata:input_type -> v1.GetMetadataRequest
-	8,  // 6: v1.ComponentService.GetStatus:input_type -> v1.GetStatusRequest
-	10, // 7: v1.ComponentService.Shutdown:input_type -> v1.ShutdownRequest
-	12, // 8: v1.UpdaterNotificationService.NotifyUpdateAvailable:input_type -> v1.UpdateNotificationRequest
-	14, // 9: v1.UpdaterNotificationService.GetComponentVersion:input_type -> v1.ComponentVersionRequest
-	16, // 10: v1.ComponentDiscoveryService.RegisterComponent:input_type -> v1.RegisterComponentRequest
-	4,  // 11: v1.ComponentService.ExecuteCommand:output_type -> v1.ExecuteCommandResponse
-	6,  // 12: v1.ComponentService.GetMetadata:output_type -> v1.ComponentMetadata
-	9,  // 13: v1.ComponentService.GetStatus:output_type -> v1.GetStatusResponse
-	11, // 14: v1.ComponentService.Shutdown:output_type -> v1.ShutdownResponse
-	13, // 15: v1.UpdaterNotificationService.NotifyUpdateAvailable:output_type -> v1.UpdateNotificationResponse
-	15, // 16: v1.UpdaterNotificationService.GetComponentVersion:output_type -> v1.ComponentVersionResponse
-	17, // 17: v1.ComponentDiscoveryService.RegisterComponent:output_type -> v1.RegisterComponentResponse
-	11, // [11:18] is the sub-list for method output_type
-	4,  // [4:11] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	14, // 4: v1.MetricFamily.samples:type_name -> v1.MetricSample
+	24, // 5: v1.MetricSample.labels:type_name -> v1.MetricSample.LabelsEntry
+	13, // 6: v1.GetMetricsResponse.families:type_name -> v1.MetricFamily
+	3,  // 7: v1.ComponentService.ExecuteCommand:input_type -> v1.ExecuteCommandRequest
+	5,  // 8: v1.ComponentService.GetMetadata:input_type -> v1.GetMetadataRequest
+	8,  // 9: v1.ComponentService.GetStatus:input_type -> v1.GetStatusRequest
+	10, // 10: v1.ComponentService.Shutdown:input_type -> v1.ShutdownRequest
+	12, // 11: v1.ComponentService.GetMetrics:input_type -> v1.GetMetricsRequest
+	16, // 12: v1.ComponentService.Configure:input_type -> v1.ConfigureRequest
+	18, // 13: v1.UpdaterNotificationService.NotifyUpdateAvailable:input_type -> v1.UpdateNotificationRequest
+	20, // 14: v1.UpdaterNotificationService.GetComponentVersion:input_type -> v1.ComponentVersionRequest
+	22, // 15: v1.ComponentDiscoveryService.RegisterComponent:input_type -> v1.RegisterComponentRequest
+	4,  // 16: v1.ComponentService.ExecuteCommand:output_type -> v1.ExecuteCommandResponse
+	6,  // 17: v1.ComponentService.GetMetadata:output_type -> v1.ComponentMetadata
+	9,  // 18: v1.ComponentService.GetStatus:output_type -> v1.GetStatusResponse
+	11, // 19: v1.ComponentService.Shutdown:output_type -> v1.ShutdownResponse
+	15, // 20: v1.ComponentService.GetMetrics:output_type -> v1.GetMetricsResponse
+	17, // 21: v1.ComponentService.Configure:output_type -> v1.ConfigureResponse
+	19, // 22: v1.UpdaterNotificationService.NotifyUpdateAvailable:output_type -> v1.UpdateNotificationResponse
+	21, // 23: v1.UpdaterNotificationService.GetComponentVersion:output_type -> v1.ComponentVersionResponse
+	23, // 24: v1.ComponentDiscoveryService.RegisterComponent:output_type -> v1.RegisterComponentResponse
+	16, // [16:25] is the sub-list for method output_type
+	7,  // [7:16] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_pkg_api_v1_cse_proto_init() }
@@ -1134,7 +1486,7 @@ func file_pkg_api_v1_cse_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_api_v1_cse_proto_rawDesc), len(file_pkg_api_v1_cse_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   17,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   3,
 		},